@@ -0,0 +1,198 @@
+// casmock 本地开发用的 CAS 模拟统一身份认证服务
+//
+// 用法:
+//
+//	go run ./cmd/casmock -addr :9100 -major 计算机科学与技术 -grade 2023
+//	go run ./cmd/casmock -user 2023012345   # 跳过登录表单直接以该学号登录
+//
+// 提供 /login、/serviceValidate（CAS 2.0）与 /p3/serviceValidate（CAS 3.0，返回属性），
+// 输入任意学号即可登录，票据只能校验一次。配合 config.yaml:
+//
+//	cas:
+//	  enabled: true
+//	  server_url: "http://localhost:9100"
+//	  service_url: "http://localhost:8080/api/v1/auth/cas/callback"
+//
+// 仅用于开发与联调，切勿用于生产环境。
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"flag"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ticketTTL 服务票据有效期
+const ticketTTL = 30 * time.Second
+
+// ticket 已签发的服务票据
+type ticket struct {
+	Service   string
+	User      string
+	ExpiresAt time.Time
+}
+
+// server 模拟CAS服务
+type server struct {
+	autoUser   string
+	mailDomain string
+	major      string
+	grade      string
+
+	mu      sync.Mutex
+	tickets map[string]*ticket
+}
+
+// serviceResponse CAS 票据校验响应
+type serviceResponse struct {
+	XMLName xml.Name     `xml:"cas:serviceResponse"`
+	XMLNS   string       `xml:"xmlns:cas,attr"`
+	Success *authSuccess `xml:"cas:authenticationSuccess,omitempty"`
+	Failure *authFailure `xml:"cas:authenticationFailure,omitempty"`
+}
+
+// authSuccess 认证成功
+type authSuccess struct {
+	User       string      `xml:"cas:user"`
+	Attributes *attributes `xml:"cas:attributes,omitempty"`
+}
+
+// attributes 释放的属性
+type attributes struct {
+	Mail  string `xml:"cas:mail,omitempty"`
+	CN    string `xml:"cas:cn,omitempty"`
+	Major string `xml:"cas:major,omitempty"`
+	Grade string `xml:"cas:grade,omitempty"`
+}
+
+// authFailure 认证失败
+type authFailure struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"><title>模拟统一身份认证</title></head>
+<body style="font-family: Arial, sans-serif; max-width: 360px; margin: 60px auto;">
+    <h2>模拟统一身份认证</h2>
+    <p style="color: #999;">仅用于本地开发，输入任意学号即可登录</p>
+    <form method="post">
+        <input type="hidden" name="service" value="{{.}}">
+        <p><input name="username" placeholder="学号" required style="width: 100%;"></p>
+        <p><input name="password" type="password" placeholder="密码（任意）" style="width: 100%;"></p>
+        <p><button type="submit">登录</button></p>
+    </form>
+</body>
+</html>`))
+
+func main() {
+	addr := flag.String("addr", ":9100", "监听地址")
+	user := flag.String("user", "", "设置后跳过登录表单，直接以该学号登录")
+	mailDomain := flag.String("mail-domain", "stu.example.edu.cn", "mail 属性的域名，为空时不返回邮箱")
+	major := flag.String("major", "计算机科学与技术", "major 属性")
+	grade := flag.String("grade", "2023", "grade 属性")
+	flag.Parse()
+
+	s := &server{
+		autoUser:   *user,
+		mailDomain: *mailDomain,
+		major:      *major,
+		grade:      *grade,
+		tickets:    make(map[string]*ticket),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", s.login)
+	mux.HandleFunc("/serviceValidate", s.validate(false))
+	mux.HandleFunc("/p3/serviceValidate", s.validate(true))
+
+	log.Printf("模拟CAS服务已启动: 监听 %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+// login 登录页：GET 展示登录表单，POST 签发服务票据并跳转回 service
+func (s *server) login(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	service := r.Form.Get("service")
+	serviceURL, err := url.Parse(service)
+	if err != nil || serviceURL.Scheme == "" {
+		http.Error(w, "invalid service", http.StatusBadRequest)
+		return
+	}
+
+	username := s.autoUser
+	if r.Method == http.MethodPost {
+		username = strings.TrimSpace(r.PostForm.Get("username"))
+	}
+	if username == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		loginPage.Execute(w, service)
+		return
+	}
+
+	id := "ST-" + randomString()
+	s.mu.Lock()
+	s.tickets[id] = &ticket{Service: service, User: username, ExpiresAt: time.Now().Add(ticketTTL)}
+	s.mu.Unlock()
+
+	query := serviceURL.Query()
+	query.Set("ticket", id)
+	serviceURL.RawQuery = query.Encode()
+	http.Redirect(w, r, serviceURL.String(), http.StatusFound)
+}
+
+// validate 票据校验，withAttributes 为 true 时返回属性（CAS 3.0）
+func (s *server) validate(withAttributes bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, service := r.URL.Query().Get("ticket"), r.URL.Query().Get("service")
+
+		s.mu.Lock()
+		t, ok := s.tickets[id]
+		delete(s.tickets, id)
+		s.mu.Unlock()
+
+		resp := serviceResponse{XMLNS: "http://www.yale.edu/tp/cas"}
+		switch {
+		case !ok || time.Now().After(t.ExpiresAt):
+			resp.Failure = &authFailure{Code: "INVALID_TICKET", Message: "Ticket " + id + " not recognized"}
+		case t.Service != service:
+			resp.Failure = &authFailure{Code: "INVALID_SERVICE", Message: "Ticket was issued for a different service"}
+		default:
+			resp.Success = &authSuccess{User: t.User}
+			if withAttributes {
+				attrs := &attributes{CN: t.User, Major: s.major, Grade: s.grade}
+				if s.mailDomain != "" {
+					attrs.Mail = t.User + "@" + s.mailDomain
+				}
+				resp.Success.Attributes = attrs
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.Write([]byte(xml.Header))
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "  ")
+		if err := enc.Encode(resp); err != nil {
+			log.Printf("输出响应失败: %v", err)
+		}
+	}
+}
+
+// randomString 生成随机字符串
+func randomString() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
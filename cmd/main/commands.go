@@ -0,0 +1,373 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/jwks"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/validator"
+)
+
+// command 子命令
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+// commands 支持的子命令
+var commands = []command{
+	{"serve", "启动HTTP服务（默认）", runServe},
+	{"migrate", "迁移数据库表结构并初始化预置角色", runMigrate},
+	{"create-admin", "创建管理员账户", runCreateAdmin},
+	{"reset-password", "重置账户密码并撤销其全部会话", runResetPassword},
+	{"list-admins", "列出管理员账户", runListAdmins},
+	{"generate-jwt-key", "生成JWT非对称签名密钥（无需配置文件）", runGenerateJWTKey},
+}
+
+// findCommand 按名称查找子命令
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// printUsage 打印用法说明
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "用法: main <子命令> [参数]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "子命令:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "使用 main <子命令> -h 查看子命令参数")
+}
+
+// newFlagSet 创建子命令参数集
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ExitOnError)
+}
+
+// bootstrap 加载配置并初始化日志、验证器、数据库与Redis
+// requireRedis 为 false 时Redis不可用只记录警告，依赖Redis的步骤（撤销会话、解除锁定）将被跳过
+func bootstrap(configPath string, requireRedis bool) (*config.Config, error) {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载配置失败: %w", err)
+	}
+
+	if err := logger.InitLogger(&logger.Config{
+		Level:      cfg.Log.Level,
+		Format:     cfg.Log.Format,
+		Output:     cfg.Log.Output,
+		MaxSize:    cfg.Log.MaxSize,
+		MaxBackups: cfg.Log.MaxBackups,
+		MaxAge:     cfg.Log.MaxAge,
+		Compress:   cfg.Log.Compress,
+	}); err != nil {
+		return nil, fmt.Errorf("初始化日志失败: %w", err)
+	}
+
+	validator.InitValidator()
+
+	if err := config.InitDatabase(&cfg.Database); err != nil {
+		return nil, fmt.Errorf("初始化数据库失败: %w", err)
+	}
+
+	if err := config.InitRedis(&cfg.Redis); err != nil {
+		if requireRedis {
+			return nil, fmt.Errorf("初始化Redis失败: %w", err)
+		}
+		logger.Warnf("Redis不可用，将跳过依赖Redis的操作: %v", err)
+	}
+	return cfg, nil
+}
+
+// shutdown 关闭数据库与Redis连接
+func shutdown() {
+	if err := config.CloseDatabase(); err != nil {
+		logger.Errorf("关闭数据库连接失败: %v", err)
+	}
+	if err := config.CloseRedis(); err != nil {
+		logger.Errorf("关闭Redis连接失败: %v", err)
+	}
+}
+
+// migrateDatabase 自动迁移数据库表并初始化系统预置角色
+func migrateDatabase() error {
+	if err := config.AutoMigrate(
+		&models.Role{},
+		&models.User{},
+		&models.Lab{},
+		&models.Application{},
+		&models.Notification{},
+		&models.InterviewApplication{},
+		&models.FileUpload{},
+		&models.ApplicationStatusLog{},
+		&models.VerificationCodeLog{},
+		&models.OperationLog{},
+		&models.EmailLog{},
+		&models.ErasureRequest{},
+		&models.Offer{},
+		&models.UserTwoFactor{},
+		&models.RecoveryCode{},
+		&models.WebAuthnCredential{},
+		&models.PasswordResetToken{},
+		&models.UserSession{},
+		&models.APIToken{},
+		&models.PasswordHistory{},
+	); err != nil {
+		return fmt.Errorf("数据库迁移失败: %w", err)
+	}
+
+	if err := services.NewRoleService().EnsureDefaultRoles(); err != nil {
+		return fmt.Errorf("初始化角色失败: %w", err)
+	}
+	return nil
+}
+
+// runMigrate 迁移数据库
+func runMigrate(args []string) error {
+	fs := newFlagSet("migrate")
+	configPath := fs.String("config", "config.yaml", "配置文件路径")
+	fs.Parse(args)
+
+	if _, err := bootstrap(*configPath, false); err != nil {
+		return err
+	}
+	defer shutdown()
+
+	if err := migrateDatabase(); err != nil {
+		return err
+	}
+	fmt.Println("数据库迁移完成")
+	return nil
+}
+
+// runCreateAdmin 创建管理员账户
+func runCreateAdmin(args []string) error {
+	fs := newFlagSet("create-admin")
+	configPath := fs.String("config", "config.yaml", "配置文件路径")
+	email := fs.String("email", "", "管理员邮箱（必填）")
+	username := fs.String("username", "", "用户名，默认取邮箱前缀")
+	password := fs.String("password", "", "初始密码，未指定时读取环境变量 ADMIN_PASSWORD，仍为空则随机生成并打印")
+	role := fs.String("role", "super_admin", "角色: super_admin 或 admin")
+	ifNotExists := fs.Bool("if-not-exists", false, "邮箱已存在时跳过而不报错，便于在容器启动脚本中重复执行")
+	fs.Parse(args)
+
+	if *email == "" {
+		return errors.New("必须指定 -email")
+	}
+	if *username == "" {
+		*username = strings.SplitN(*email, "@", 2)[0]
+	}
+	if *password == "" {
+		*password = os.Getenv("ADMIN_PASSWORD")
+	}
+
+	req := &models.UserCreateRequest{
+		Username: *username,
+		Email:    strings.ToLower(strings.TrimSpace(*email)),
+		Password: *password,
+		Role:     *role,
+	}
+	if req.Role != "admin" && req.Role != "super_admin" {
+		return fmt.Errorf("无效的角色: %s", req.Role)
+	}
+
+	if _, err := bootstrap(*configPath, false); err != nil {
+		return err
+	}
+	defer shutdown()
+
+	if err := validator.ValidateStruct(req); err != nil {
+		return fmt.Errorf("参数无效: %w", err)
+	}
+
+	userService := services.NewUserService()
+	if existing, err := userService.GetUserByEmail(req.Email); err == nil {
+		if *ifNotExists {
+			fmt.Printf("账户已存在，跳过: %s（角色 %s）\n", existing.Email, existing.Role)
+			return nil
+		}
+		return fmt.Errorf("邮箱已被注册: %s", existing.Email)
+	}
+
+	user, initialPassword, err := userService.CreateUser(req)
+	if err != nil {
+		return err
+	}
+
+	services.NewAuditService().Record(services.AuditEntry{
+		Action:       "user.create",
+		ResourceType: "user",
+		ResourceID:   &user.ID,
+		Details:      models.JSONMap{"role": user.Role, "source": "cli"},
+	})
+
+	fmt.Printf("已创建%s: %s（ID %d，用户名 %s）\n", roleLabel(user.Role), user.Email, user.ID, user.Username)
+	if req.Password == "" {
+		fmt.Printf("初始密码: %s\n请登录后立即修改密码。\n", initialPassword)
+	}
+	return nil
+}
+
+// runResetPassword 重置账户密码
+func runResetPassword(args []string) error {
+	fs := newFlagSet("reset-password")
+	configPath := fs.String("config", "config.yaml", "配置文件路径")
+	email := fs.String("email", "", "账户邮箱（必填）")
+	password := fs.String("password", "", "新密码，未指定时读取环境变量 NEW_PASSWORD，仍为空则随机生成并打印")
+	fs.Parse(args)
+
+	if *email == "" {
+		return errors.New("必须指定 -email")
+	}
+	if *password == "" {
+		*password = os.Getenv("NEW_PASSWORD")
+	}
+
+	if _, err := bootstrap(*configPath, false); err != nil {
+		return err
+	}
+	defer shutdown()
+
+	userService := services.NewUserService()
+	user, err := userService.GetUserByEmail(strings.ToLower(strings.TrimSpace(*email)))
+	if err != nil {
+		return fmt.Errorf("账户不存在: %s", *email)
+	}
+
+	newPassword, err := userService.ResetPassword(user.ID, *password)
+	if err != nil {
+		return err
+	}
+
+	// 撤销全部会话并解除登录锁定
+	if err := services.NewTokenService().RevokeAllSessions(user.ID); err != nil {
+		fmt.Fprintf(os.Stderr, "警告: 撤销会话失败，旧会话将在过期后失效: %v\n", err)
+	}
+	if _, err := services.NewLoginGuardService().Unlock(user.Email); err != nil {
+		fmt.Fprintf(os.Stderr, "警告: 解除登录锁定失败: %v\n", err)
+	}
+
+	services.NewAuditService().Record(services.AuditEntry{
+		Action:       "user.reset_password",
+		ResourceType: "user",
+		ResourceID:   &user.ID,
+		Details:      models.JSONMap{"source": "cli"},
+	})
+
+	fmt.Printf("已重置 %s 的密码\n", user.Email)
+	if *password == "" {
+		fmt.Printf("新密码: %s\n请登录后立即修改密码。\n", newPassword)
+	}
+	return nil
+}
+
+// runListAdmins 列出管理员账户
+func runListAdmins(args []string) error {
+	fs := newFlagSet("list-admins")
+	configPath := fs.String("config", "config.yaml", "配置文件路径")
+	fs.Parse(args)
+
+	if _, err := bootstrap(*configPath, false); err != nil {
+		return err
+	}
+	defer shutdown()
+
+	admins, err := services.NewUserService().ListAdmins()
+	if err != nil {
+		return err
+	}
+	if len(admins) == 0 {
+		fmt.Println("没有管理员账户，可使用 create-admin 创建")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\t用户名\t邮箱\t角色\t状态\t创建时间")
+	for _, admin := range admins {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", admin.ID, admin.Username, admin.Email, admin.Role, admin.Status,
+			admin.CreatedAt.Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
+
+// runGenerateJWTKey 生成JWT非对称签名密钥
+// 在输出目录生成 <kid>.pem（私钥，权限0600）与 <kid>.pub.pem（公钥），
+// 并打印可直接粘贴到 config.yaml 的 jwt.keys 配置片段
+func runGenerateJWTKey(args []string) error {
+	fs := newFlagSet("generate-jwt-key")
+	alg := fs.String("alg", jwks.AlgEdDSA, "签名算法: EdDSA 或 RS256")
+	bits := fs.Int("bits", 3072, "RSA密钥长度，仅 RS256 有效")
+	kid := fs.String("kid", "", "密钥ID，默认按日期与算法生成")
+	out := fs.String("out", "keys/jwt", "密钥输出目录")
+	fs.Parse(args)
+
+	if *kid == "" {
+		*kid = fmt.Sprintf("%s-%s", time.Now().Format("20060102"), strings.ToLower(*alg))
+	}
+
+	key, err := jwks.GenerateKey(*alg, *bits)
+	if err != nil {
+		return err
+	}
+
+	privatePEM, err := jwks.EncodePrivateKeyPEM(key)
+	if err != nil {
+		return err
+	}
+	publicPEM, err := jwks.EncodePublicKeyPEM(key.Public())
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*out, 0o700); err != nil {
+		return err
+	}
+	privatePath := filepath.Join(*out, *kid+".pem")
+	publicPath := filepath.Join(*out, *kid+".pub.pem")
+	if _, err := os.Stat(privatePath); err == nil {
+		return fmt.Errorf("密钥文件已存在: %s", privatePath)
+	}
+	if err := os.WriteFile(privatePath, privatePEM, 0o600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(publicPath, publicPEM, 0o644); err != nil {
+		return err
+	}
+
+	fmt.Printf("私钥: %s\n公钥: %s\n\n", privatePath, publicPath)
+	fmt.Println("在 config.yaml 中启用（原签发密钥改为仅验证并设置 verify_until）:")
+	fmt.Printf(`
+jwt:
+  active_key: %q
+  keys:
+    - id: %q
+      private_key_file: %q
+`, *kid, *kid, privatePath)
+	return nil
+}
+
+// roleLabel 角色显示名称
+func roleLabel(role string) string {
+	if role == "super_admin" {
+		return "超级管理员"
+	}
+	return "管理员"
+}
@@ -6,18 +6,16 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
 	"lab-recruitment-platform/internal/config"
-	"lab-recruitment-platform/internal/handlers"
 	"lab-recruitment-platform/internal/middleware"
-	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/internal/scheduler"
+	"lab-recruitment-platform/internal/services"
 	"lab-recruitment-platform/pkg/logger"
-	"lab-recruitment-platform/pkg/validator"
 )
 
 // @title 实验室招新平台 API
@@ -41,51 +39,51 @@ import (
 // @description 请输入JWT令牌，格式：Bearer <token>
 
 func main() {
-	// 加载配置
-	cfg, err := config.LoadConfig("config.yaml")
-	if err != nil {
-		fmt.Printf("加载配置失败: %v\n", err)
-		os.Exit(1)
+	// 第一个参数为子命令，省略时启动服务以兼容原有部署方式
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
 	}
 
-	// 初始化日志
-	if err := logger.InitLogger(&logger.Config{
-		Level:      cfg.Log.Level,
-		Format:     cfg.Log.Format,
-		Output:     cfg.Log.Output,
-		MaxSize:    cfg.Log.MaxSize,
-		MaxBackups: cfg.Log.MaxBackups,
-		MaxAge:     cfg.Log.MaxAge,
-		Compress:   cfg.Log.Compress,
-	}); err != nil {
-		fmt.Printf("初始化日志失败: %v\n", err)
-		os.Exit(1)
+	cmd, ok := findCommand(name)
+	if !ok {
+		if name != "help" {
+			fmt.Fprintf(os.Stderr, "未知的子命令: %s\n\n", name)
+		}
+		printUsage()
+		if name != "help" {
+			os.Exit(2)
+		}
+		return
 	}
 
-	logger.Info("开始启动实验室招新平台...")
+	if err := cmd.run(args); err != nil {
+		fmt.Fprintf(os.Stderr, "%s 失败: %v\n", name, err)
+		os.Exit(1)
+	}
+}
 
-	// 初始化验证器
-	validator.InitValidator()
+// runServe 启动HTTP服务
+func runServe(args []string) error {
+	fs := newFlagSet("serve")
+	configPath := fs.String("config", "config.yaml", "配置文件路径")
+	fs.Parse(args)
 
-	// 初始化数据库
-	if err := config.InitDatabase(&cfg.Database); err != nil {
-		logger.Fatalf("初始化数据库失败: %v", err)
+	cfg, err := bootstrap(*configPath, true)
+	if err != nil {
+		return err
 	}
 
-	// 初始化Redis
-	if err := config.InitRedis(&cfg.Redis); err != nil {
-		logger.Fatalf("初始化Redis失败: %v", err)
+	logger.Info("开始启动实验室招新平台...")
+
+	// 加载JWT签名密钥
+	if err := middleware.InitJWTKeys(&cfg.JWT); err != nil {
+		logger.Fatalf("加载JWT签名密钥失败: %v", err)
 	}
 
 	// 自动迁移数据库表
-	if err := config.AutoMigrate(
-		&models.User{},
-		&models.Lab{},
-		&models.Application{},
-		&models.Notification{},
-		&models.InterviewApplication{},
-	); err != nil {
-		logger.Fatalf("数据库迁移失败: %v", err)
+	if err := migrateDatabase(); err != nil {
+		logger.Fatalf("%v", err)
 	}
 
 	// 设置Gin模式
@@ -93,121 +91,8 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// 创建Gin引擎
-	r := gin.New()
-
-	// 添加中间件
-	r.Use(middleware.CORSMiddleware())
-	r.Use(middleware.RequestLoggerMiddleware())
-	r.Use(gin.Recovery())
-
-	// 健康检查
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "ok",
-			"message": "实验室招新平台运行正常",
-			"time":    time.Now().Format("2006-01-02 15:04:05"),
-		})
-	})
-
-	// API路由组
-	api := r.Group("/api/v1")
-	{
-		// 认证相关路由
-		authHandler := handlers.NewAuthHandler()
-		auth := api.Group("/auth")
-		{
-			auth.POST("/login", authHandler.Login)
-			auth.POST("/logout", middleware.AuthMiddleware(), authHandler.Logout)
-			auth.POST("/refresh", middleware.AuthMiddleware(), authHandler.RefreshToken)
-			auth.GET("/profile", middleware.AuthMiddleware(), authHandler.GetProfile)
-			auth.PUT("/profile", middleware.AuthMiddleware(), authHandler.UpdateProfile)
-			auth.POST("/change-password", middleware.AuthMiddleware(), authHandler.ChangePassword)
-		}
-
-		// 申请相关路由
-		applicationHandler := handlers.NewApplicationHandler()
-		api.POST("/send-code", applicationHandler.SendCode)
-		api.POST("/apply", applicationHandler.Apply)
-
-		// 管理员申请管理路由
-		admin := api.Group("/admin")
-		admin.Use(middleware.AuthMiddleware()) // 需要登录验证
-		{
-			admin.GET("/applications", applicationHandler.ListApplications)
-			admin.GET("/applications/stats", applicationHandler.GetApplicationStats)
-			admin.GET("/applications/:id", applicationHandler.GetApplication)
-			admin.PUT("/applications/:id", applicationHandler.UpdateApplication)
-			admin.DELETE("/applications/:id", applicationHandler.DeleteApplication)
-		}
-
-		// 用户管理路由（需要管理员权限）
-		// userHandler := handlers.NewUserHandler()
-		// users := api.Group("/users")
-		// users.Use(middleware.AdminMiddleware())
-		// {
-		// 	users.GET("", userHandler.ListUsers)
-		// 	users.GET("/:id", userHandler.GetUser)
-		// 	users.PUT("/:id", userHandler.UpdateUser)
-		// 	users.DELETE("/:id", userHandler.DeleteUser)
-		// 	users.POST("/:id/reset-password", userHandler.ResetPassword)
-		// 	users.PUT("/:id/status", userHandler.UpdateUserStatus)
-		// 	users.GET("/stats", userHandler.GetUserStats)
-		// }
-
-		// 实验室管理路由
-		// labHandler := handlers.NewLabHandler()
-		// labs := api.Group("/labs")
-		// {
-		// 	labs.GET("", labHandler.ListLabs)
-		// 	labs.GET("/:id", labHandler.GetLab)
-		// 	labs.POST("", middleware.AuthMiddleware(), labHandler.CreateLab)
-		// 	labs.PUT("/:id", middleware.AuthMiddleware(), labHandler.UpdateLab)
-		// 	labs.DELETE("/:id", middleware.AdminMiddleware(), labHandler.DeleteLab)
-		// 	labs.GET("/:id/applications", middleware.AuthMiddleware(), labHandler.GetLabApplications)
-		// }
-
-		// 申请管理路由
-		// applicationHandler := handlers.NewApplicationHandler()
-		// applications := api.Group("/applications")
-		// applications.Use(middleware.AuthMiddleware())
-		// {
-		// 	applications.GET("", applicationHandler.ListApplications)
-		// 	applications.GET("/:id", applicationHandler.GetApplication)
-		// 	applications.POST("", applicationHandler.CreateApplication)
-		// 	applications.PUT("/:id", applicationHandler.UpdateApplication)
-		// 	applications.DELETE("/:id", applicationHandler.DeleteApplication)
-		// 	applications.POST("/:id/review", middleware.AdminMiddleware(), applicationHandler.ReviewApplication)
-		// 	applications.GET("/stats", middleware.AdminMiddleware(), applicationHandler.GetApplicationStats)
-		// }
-
-		// 通知管理路由
-		// notificationHandler := handlers.NewNotificationHandler()
-		// notifications := api.Group("/notifications")
-		// notifications.Use(middleware.AuthMiddleware())
-		// {
-		// 	notifications.GET("", notificationHandler.ListNotifications)
-		// 	notifications.GET("/:id", notificationHandler.GetNotification)
-		// 	notifications.PUT("/:id/read", notificationHandler.MarkAsRead)
-		// 	notifications.PUT("/read-all", notificationHandler.MarkAllAsRead)
-		// 	notifications.GET("/stats", notificationHandler.GetNotificationStats)
-		// }
-
-		// 文件上传路由
-		// uploadHandler := handlers.NewUploadHandler()
-		// upload := api.Group("/upload")
-		// upload.Use(middleware.AuthMiddleware())
-		// {
-		// 	upload.POST("/image", uploadHandler.UploadImage)
-		// 	upload.POST("/file", uploadHandler.UploadFile)
-		// }
-	}
-
-	// Swagger文档
-	if cfg.Server.IsDevelopment() {
-		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-		logger.Info("Swagger文档地址: http://localhost:" + cfg.Server.Port + "/swagger/index.html")
-	}
+	// 注册路由
+	r := setupRouter(cfg)
 
 	// 创建HTTP服务器
 	srv := &http.Server{
@@ -218,6 +103,34 @@ func main() {
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
+	// 启动定时任务
+	jobs := scheduler.New()
+	if cfg.Trash.RetentionDays > 0 {
+		interviewService := services.NewInterviewApplicationService()
+		jobs.Register("purge-trash", cfg.Trash.PurgeInterval, func(ctx context.Context) error {
+			_, err := interviewService.PurgeExpiredApplications(cfg.Trash.GetRetention())
+			return err
+		})
+	}
+	if cfg.Retention.Enabled {
+		retentionService := services.NewRetentionService()
+		jobs.Register("anonymize-pii", cfg.Retention.Interval, func(ctx context.Context) error {
+			_, err := retentionService.Run(nil, "scheduled")
+			return err
+		})
+	}
+	tokenService := services.NewTokenService()
+	jobs.Register("purge-sessions", 24*time.Hour, func(ctx context.Context) error {
+		_, err := tokenService.PurgeSessionRecords(cfg.JWT.RefreshExpireTime)
+		return err
+	})
+	offerService := services.NewOfferService()
+	jobs.Register("expire-offers", cfg.Offer.CheckInterval, func(ctx context.Context) error {
+		_, err := offerService.ExpireOffers()
+		return err
+	})
+	jobs.Start()
+
 	// 启动服务器
 	go func() {
 		logger.Infof("服务器启动在端口: %s", cfg.Server.Port)
@@ -241,6 +154,9 @@ func main() {
 		logger.Errorf("服务器关闭失败: %v", err)
 	}
 
+	// 停止定时任务
+	jobs.Stop()
+
 	// 关闭数据库连接
 	if err := config.CloseDatabase(); err != nil {
 		logger.Errorf("关闭数据库连接失败: %v", err)
@@ -252,4 +168,5 @@ func main() {
 	}
 
 	logger.Info("服务器已关闭")
-} 
\ No newline at end of file
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/handlers"
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// setupRouter 创建Gin引擎并注册全部中间件与路由
+func setupRouter(cfg *config.Config) *gin.Engine {
+	// 创建Gin引擎
+	r := gin.New()
+
+	// 只信任来自已配置反向代理的 X-Forwarded-For，否则客户端可伪造 ClientIP 绕过按IP限制
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Errorf("设置可信代理失败: %v", err)
+	}
+
+	// 添加中间件
+	r.Use(middleware.CORSMiddleware())
+	r.Use(middleware.RequestLoggerMiddleware())
+	r.Use(gin.Recovery())
+
+	// 健康检查
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "ok",
+			"message": "实验室招新平台运行正常",
+			"time":    time.Now().Format("2006-01-02 15:04:05"),
+		})
+	})
+
+	// JWT公钥集合，供其他服务验证本平台签发的令牌
+	r.GET("/.well-known/jwks.json", handlers.NewJWKSHandler().GetJWKS)
+
+	// API路由组
+	api := r.Group("/api/v1")
+	{
+		// 认证相关路由
+		authHandler := handlers.NewAuthHandler()
+		calendarHandler := handlers.NewCalendarHandler()
+		twoFactorHandler := handlers.NewTwoFactorHandler()
+		webAuthnHandler := handlers.NewWebAuthnHandler()
+		sessionHandler := handlers.NewSessionHandler()
+		apiTokenHandler := handlers.NewAPITokenHandler()
+		ssoHandler := handlers.NewSSOHandler()
+		auth := api.Group("/auth")
+		{
+			auth.POST("/login", authHandler.Login)
+			auth.POST("/register", authHandler.Register)
+			auth.GET("/verify-email", authHandler.VerifyEmail)
+			auth.POST("/verify-email", authHandler.VerifyEmail)
+			auth.POST("/resend-verification", authHandler.ResendVerification)
+			auth.POST("/forgot-password", authHandler.ForgotPassword)
+			auth.POST("/reset-password", authHandler.ResetPassword)
+			auth.GET("/password-policy", authHandler.GetPasswordPolicy)
+			auth.GET("/sso/providers", ssoHandler.ListProviders)
+			auth.POST("/sso/exchange", ssoHandler.ExchangeLoginCode)
+			auth.GET("/oidc/login", ssoHandler.OIDCLogin)
+			auth.GET("/oidc/callback", ssoHandler.OIDCCallback)
+			auth.GET("/cas/login", ssoHandler.CASLogin)
+			auth.GET("/cas/callback", ssoHandler.CASCallback)
+			auth.GET("/2fa", middleware.SessionAuthMiddleware(), twoFactorHandler.GetStatus)
+			auth.POST("/2fa/setup", middleware.OptionalAuthMiddleware(), twoFactorHandler.Setup)
+			auth.POST("/2fa/enable", middleware.OptionalAuthMiddleware(), twoFactorHandler.Enable)
+			auth.POST("/2fa/verify", twoFactorHandler.Verify)
+			auth.POST("/2fa/disable", middleware.SessionAuthMiddleware(), twoFactorHandler.Disable)
+			auth.POST("/2fa/recovery-codes", middleware.SessionAuthMiddleware(), twoFactorHandler.RegenerateRecoveryCodes)
+			auth.POST("/webauthn/register/begin", middleware.OptionalAuthMiddleware(), webAuthnHandler.BeginRegistration)
+			auth.POST("/webauthn/register/finish", middleware.OptionalAuthMiddleware(), webAuthnHandler.FinishRegistration)
+			auth.POST("/webauthn/login/begin", webAuthnHandler.BeginLogin)
+			auth.POST("/webauthn/login/finish", webAuthnHandler.FinishLogin)
+			auth.GET("/webauthn/credentials", middleware.SessionAuthMiddleware(), webAuthnHandler.ListCredentials)
+			auth.PUT("/webauthn/credentials/:id", middleware.SessionAuthMiddleware(), webAuthnHandler.RenameCredential)
+			auth.DELETE("/webauthn/credentials/:id", middleware.SessionAuthMiddleware(), webAuthnHandler.DeleteCredential)
+			auth.POST("/logout", middleware.SessionAuthMiddleware(), authHandler.Logout)
+			auth.GET("/sessions", middleware.SessionAuthMiddleware(), sessionHandler.ListSessions)
+			auth.DELETE("/sessions", middleware.SessionAuthMiddleware(), sessionHandler.RevokeOtherSessions)
+			auth.DELETE("/sessions/:id", middleware.SessionAuthMiddleware(), sessionHandler.RevokeSession)
+			auth.GET("/api-tokens", middleware.SessionAuthMiddleware(), apiTokenHandler.ListTokens)
+			auth.POST("/api-tokens", middleware.SessionAuthMiddleware(), apiTokenHandler.CreateToken)
+			auth.DELETE("/api-tokens/:id", middleware.SessionAuthMiddleware(), apiTokenHandler.RevokeToken)
+			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.GET("/profile", middleware.SessionAuthMiddleware(), authHandler.GetProfile)
+			auth.PUT("/profile", middleware.SessionAuthMiddleware(), authHandler.UpdateProfile)
+			auth.POST("/change-password", middleware.SessionAuthMiddleware(), authHandler.ChangePassword)
+			auth.GET("/calendar-feed", middleware.SessionAuthMiddleware(), calendarHandler.GetFeedURL)
+			auth.POST("/calendar-feed/reset", middleware.SessionAuthMiddleware(), calendarHandler.ResetFeedURL)
+		}
+
+		// 面试日历订阅（通过私有令牌访问）
+		api.GET("/calendar/:token/interviews.ics", calendarHandler.InterviewerFeed)
+
+		// 申请相关路由
+		applicationHandler := handlers.NewApplicationHandler()
+		uploadHandler := handlers.NewUploadHandler()
+		analyticsHandler := handlers.NewAnalyticsHandler()
+		retentionHandler := handlers.NewRetentionHandler()
+		privacyHandler := handlers.NewPrivacyHandler()
+		offerHandler := handlers.NewOfferHandler()
+		blindReviewHandler := handlers.NewBlindReviewHandler()
+		api.POST("/send-code", applicationHandler.SendCode)
+		api.POST("/apply", applicationHandler.Apply)
+		api.POST("/upload/attachment", uploadHandler.UploadAttachment)
+
+		// 申请人个人数据导出与删除（凭邮箱验证码）
+		api.POST("/privacy/export", privacyHandler.ExportData)
+		api.POST("/privacy/erasure", privacyHandler.SubmitErasureRequest)
+
+		// 录取通知答复（通过邮件中的私有令牌访问）
+		api.GET("/offers/:token", offerHandler.GetOffer)
+		api.POST("/offers/:token/accept", offerHandler.AcceptOffer)
+		api.POST("/offers/:token/decline", offerHandler.DeclineOffer)
+
+		// 管理员申请管理路由
+		roleHandler := handlers.NewRoleHandler()
+		canRead := middleware.RequirePermission(models.PermApplicationRead)
+		canDecide := middleware.RequirePermission(models.PermApplicationDecide)
+		canSchedule := middleware.RequirePermission(models.PermApplicationSchedule)
+		canDelete := middleware.RequirePermission(models.PermApplicationDelete)
+		canOffer := middleware.RequirePermission(models.PermOfferManage)
+		canStats := middleware.RequirePermission(models.PermStatsRead)
+		canPrivacy := middleware.RequirePermission(models.PermPrivacyManage)
+		canRoles := middleware.RequirePermission(models.PermRoleManage)
+		admin := api.Group("/admin")
+		admin.Use(middleware.AuthMiddleware(), middleware.RequireStaff()) // 需要登录且具有管理权限
+		{
+			admin.GET("/applications", canRead, applicationHandler.ListApplications)
+			admin.GET("/applications/stats", canStats, applicationHandler.GetApplicationStats)
+			admin.GET("/analytics", canStats, analyticsHandler.GetRecruitmentAnalytics)
+			admin.GET("/applications/trash", canDelete, applicationHandler.ListDeletedApplications)
+			admin.POST("/applications/trash/:id/restore", canDelete, applicationHandler.RestoreApplication)
+			admin.DELETE("/applications/trash/:id", middleware.SuperAdminMiddleware(), applicationHandler.PurgeApplication)
+			admin.GET("/retention/preview", canPrivacy, retentionHandler.Preview)
+			admin.POST("/retention/run", middleware.SuperAdminMiddleware(), retentionHandler.Run)
+			admin.GET("/audit-logs", canPrivacy, retentionHandler.ListAuditLogs)
+			admin.GET("/erasure-requests", canPrivacy, privacyHandler.ListErasureRequests)
+			admin.PUT("/erasure-requests/:id/review", canPrivacy, privacyHandler.ReviewErasureRequest)
+			admin.GET("/offers", canOffer, offerHandler.ListOffers)
+			admin.GET("/offers/capacity", canOffer, offerHandler.GetCapacity)
+			admin.GET("/waitlist", canOffer, offerHandler.ListWaitlist)
+			admin.PUT("/users/:id/roles", canRoles, roleHandler.SetUserRoles)
+			admin.GET("/permissions", canRoles, roleHandler.ListPermissions)
+			admin.GET("/roles", canRoles, roleHandler.ListRoles)
+			admin.POST("/roles", canRoles, roleHandler.CreateRole)
+			admin.GET("/roles/:id", canRoles, roleHandler.GetRole)
+			admin.PUT("/roles/:id", canRoles, roleHandler.UpdateRole)
+			admin.DELETE("/roles/:id", canRoles, roleHandler.DeleteRole)
+			admin.GET("/applications/:id", canRead, applicationHandler.GetApplication)
+			admin.POST("/applications/:id/reveal", canRead, blindReviewHandler.RevealApplication)
+			admin.PUT("/applications/:id", canDecide, applicationHandler.UpdateApplication)
+			admin.DELETE("/applications/:id", canDelete, applicationHandler.DeleteApplication)
+			admin.PUT("/applications/:id/schedule", canSchedule, applicationHandler.ScheduleInterview)
+			admin.GET("/applications/:id/invite.ics", canRead, applicationHandler.DownloadInvite)
+			admin.POST("/applications/:id/offer", canOffer, offerHandler.CreateOffer)
+			admin.PUT("/applications/:id/waitlist", canOffer, offerHandler.SetWaitlistRank)
+			admin.DELETE("/applications/:id/waitlist", canOffer, offerHandler.RemoveFromWaitlist)
+			admin.GET("/applications/:id/attachments", canRead, uploadHandler.ListApplicationAttachments)
+			admin.POST("/applications/:id/attachments", canDecide, uploadHandler.UploadApplicationAttachment)
+			admin.GET("/attachments/:id/download", canRead, uploadHandler.DownloadAttachment)
+		}
+
+		// 用户管理路由（需要用户管理权限）
+		userHandler := handlers.NewUserHandler()
+		users := api.Group("/users")
+		users.Use(middleware.AuthMiddleware(), middleware.RequirePermission(models.PermUserManage))
+		{
+			users.POST("", userHandler.CreateUser)
+			users.GET("", userHandler.ListUsers)
+			users.GET("/stats", userHandler.GetUserStats)
+			users.GET("/:id", userHandler.GetUser)
+			users.PUT("/:id", userHandler.UpdateUser)
+			users.DELETE("/:id", userHandler.DeleteUser)
+			users.POST("/:id/reset-password", userHandler.ResetPassword)
+			users.PUT("/:id/status", userHandler.UpdateUserStatus)
+			users.POST("/:id/unlock", userHandler.UnlockUser)
+			users.POST("/:id/2fa/reset", userHandler.ResetTwoFactor)
+			users.GET("/:id/sessions", userHandler.ListUserSessions)
+			users.DELETE("/:id/sessions", userHandler.RevokeUserSessions)
+			users.GET("/:id/api-tokens", userHandler.ListUserAPITokens)
+			users.DELETE("/:id/api-tokens", userHandler.RevokeUserAPITokens)
+		}
+
+		// 实验室管理路由
+		// labHandler := handlers.NewLabHandler()
+		// labs := api.Group("/labs")
+		// {
+		// 	labs.GET("", labHandler.ListLabs)
+		// 	labs.GET("/:id", labHandler.GetLab)
+		// 	labs.POST("", middleware.AuthMiddleware(), labHandler.CreateLab)
+		// 	labs.PUT("/:id", middleware.AuthMiddleware(), labHandler.UpdateLab)
+		// 	labs.DELETE("/:id", middleware.AdminMiddleware(), labHandler.DeleteLab)
+		// 	labs.GET("/:id/applications", middleware.AuthMiddleware(), labHandler.GetLabApplications)
+		// }
+
+		// 申请管理路由
+		// applicationHandler := handlers.NewApplicationHandler()
+		// applications := api.Group("/applications")
+		// applications.Use(middleware.AuthMiddleware())
+		// {
+		// 	applications.GET("", applicationHandler.ListApplications)
+		// 	applications.GET("/:id", applicationHandler.GetApplication)
+		// 	applications.POST("", applicationHandler.CreateApplication)
+		// 	applications.PUT("/:id", applicationHandler.UpdateApplication)
+		// 	applications.DELETE("/:id", applicationHandler.DeleteApplication)
+		// 	applications.POST("/:id/review", middleware.AdminMiddleware(), applicationHandler.ReviewApplication)
+		// 	applications.GET("/stats", middleware.AdminMiddleware(), applicationHandler.GetApplicationStats)
+		// }
+
+		// 通知管理路由
+		// notificationHandler := handlers.NewNotificationHandler()
+		// notifications := api.Group("/notifications")
+		// notifications.Use(middleware.AuthMiddleware())
+		// {
+		// 	notifications.GET("", notificationHandler.ListNotifications)
+		// 	notifications.GET("/:id", notificationHandler.GetNotification)
+		// 	notifications.PUT("/:id/read", notificationHandler.MarkAsRead)
+		// 	notifications.PUT("/read-all", notificationHandler.MarkAllAsRead)
+		// 	notifications.GET("/stats", notificationHandler.GetNotificationStats)
+		// }
+	}
+
+	// Swagger文档
+	if cfg.Server.IsDevelopment() {
+		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+		logger.Info("Swagger文档地址: http://localhost:" + cfg.Server.Port + "/swagger/index.html")
+	}
+
+	return r
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// routeParam 匹配路由中的路径参数
+var routeParam = regexp.MustCompile(`[:*][A-Za-z_]+`)
+
+// newTestRouter 加载最小配置（可追加额外配置）并创建与线上一致的路由，不连接数据库与Redis
+func newTestRouter(t *testing.T, extra string) *gin.Engine {
+	t.Helper()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := extra + `database:
+  username: test
+  database: test
+jwt:
+  secret: router-test-secret-0123456789abcdef
+log:
+  level: error
+  output: stdout
+`
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatalf("写入测试配置失败: %v", err)
+	}
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("加载测试配置失败: %v", err)
+	}
+	if err := logger.InitLogger(&logger.Config{Level: cfg.Log.Level, Output: cfg.Log.Output}); err != nil {
+		t.Fatalf("初始化日志失败: %v", err)
+	}
+	if err := middleware.InitJWTKeys(&cfg.JWT); err != nil {
+		t.Fatalf("加载JWT签名密钥失败: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	return setupRouter(cfg)
+}
+
+// TestStudentCannotAccessStaffRoutes 学生令牌访问所有管理员与用户管理路由都必须返回403
+func TestStudentCannotAccessStaffRoutes(t *testing.T) {
+	r := newTestRouter(t, "")
+
+	token, err := middleware.GenerateToken(&models.User{ID: 1, Email: "student@example.com", Role: "student"}, "")
+	if err != nil {
+		t.Fatalf("签发学生令牌失败: %v", err)
+	}
+
+	prefixes := []string{"/api/v1/admin", "/api/v1/users"}
+	checked := 0
+	for _, route := range r.Routes() {
+		protected := false
+		for _, prefix := range prefixes {
+			if route.Path == prefix || strings.HasPrefix(route.Path, prefix+"/") {
+				protected = true
+			}
+		}
+		if !protected {
+			continue
+		}
+
+		checked++
+		path := routeParam.ReplaceAllString(route.Path, "1")
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			req := httptest.NewRequest(route.Method, path, strings.NewReader("{}"))
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("%s %s 返回 %d，期望 403", route.Method, path, w.Code)
+			}
+		})
+	}
+
+	if checked == 0 {
+		t.Fatal("未找到任何管理员路由")
+	}
+}
+
+// TestClientIPTrustedProxies 只有来自可信代理的请求才使用 X-Forwarded-For 作为客户端IP
+func TestClientIPTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     string
+		remoteAddr string
+		want       string
+	}{
+		{"未配置可信代理时忽略请求头", "", "203.0.113.7:51234", "203.0.113.7"},
+		{"来自可信代理", "server:\n  trusted_proxies: [\"10.0.0.0/8\"]\n", "10.0.0.2:51234", "198.51.100.9"},
+		{"不是可信代理时忽略请求头", "server:\n  trusted_proxies: [\"10.0.0.0/8\"]\n", "203.0.113.7:51234", "203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRouter(t, tt.config)
+			r.GET("/test/client-ip", func(c *gin.Context) {
+				c.String(http.StatusOK, c.ClientIP())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test/client-ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "198.51.100.9")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+// oidcmock 本地开发用的 OpenID Connect 模拟身份提供方
+//
+// 用法:
+//
+//	go run ./cmd/oidcmock -addr :9000 -client-id lab-recruitment
+//	go run ./cmd/oidcmock -email teacher@example.edu.cn   # 跳过登录表单直接以该邮箱登录
+//
+// 提供发现文档、授权页（输入任意邮箱即可登录）、令牌端点（校验PKCE）与公钥集合，
+// ID令牌使用启动时生成的 Ed25519 密钥签名。配合 config.yaml:
+//
+//	oidc:
+//	  enabled: true
+//	  issuer: "http://localhost:9000"
+//	  client_id: "lab-recruitment"
+//	  redirect_url: "http://localhost:8080/api/v1/auth/oidc/callback"
+//
+// 仅用于开发与联调，切勿用于生产环境。
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"lab-recruitment-platform/pkg/jwks"
+)
+
+// codeTTL 授权码有效期
+const codeTTL = time.Minute
+
+// authorization 已签发授权码对应的授权请求
+type authorization struct {
+	ClientID      string
+	RedirectURI   string
+	Nonce         string
+	CodeChallenge string
+	Email         string
+	Name          string
+	ExpiresAt     time.Time
+}
+
+// provider 模拟身份提供方
+type provider struct {
+	issuer    string
+	clientID  string
+	autoEmail string
+	key       crypto.Signer
+	kid       string
+
+	mu    sync.Mutex
+	codes map[string]*authorization
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"><title>模拟统一身份认证</title></head>
+<body style="font-family: Arial, sans-serif; max-width: 360px; margin: 60px auto;">
+    <h2>模拟统一身份认证</h2>
+    <p style="color: #999;">仅用于本地开发，输入任意邮箱即可登录</p>
+    <form method="post">
+        {{range $k, $v := .}}<input type="hidden" name="{{$k}}" value="{{index $v 0}}">
+        {{end}}<p><input name="email" type="email" placeholder="邮箱" required style="width: 100%;"></p>
+        <p><input name="name" placeholder="姓名" style="width: 100%;"></p>
+        <p><button type="submit">登录</button></p>
+    </form>
+</body>
+</html>`))
+
+func main() {
+	addr := flag.String("addr", ":9000", "监听地址")
+	issuer := flag.String("issuer", "http://localhost:9000", "签发方地址，须与平台 oidc.issuer 一致")
+	clientID := flag.String("client-id", "lab-recruitment", "允许的客户端ID")
+	email := flag.String("email", "", "设置后跳过登录表单，直接以该邮箱登录")
+	flag.Parse()
+
+	key, err := jwks.GenerateKey(jwks.AlgEdDSA, 0)
+	if err != nil {
+		log.Fatalf("生成签名密钥失败: %v", err)
+	}
+
+	p := &provider{
+		issuer:    strings.TrimRight(*issuer, "/"),
+		clientID:  *clientID,
+		autoEmail: *email,
+		key:       key,
+		kid:       "oidcmock-" + time.Now().Format("20060102150405"),
+		codes:     make(map[string]*authorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/jwks", p.jwks)
+
+	log.Printf("模拟身份提供方已启动: issuer=%s, client_id=%s, 监听 %s", p.issuer, p.clientID, *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+// discovery 发现文档
+func (p *provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.issuer,
+		"authorization_endpoint":                p.issuer + "/authorize",
+		"token_endpoint":                        p.issuer + "/token",
+		"jwks_uri":                              p.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{jwks.AlgEdDSA},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"scopes_supported":                      []string{"openid", "email", "profile"},
+	})
+}
+
+// authorize 授权页：GET 展示登录表单，POST 签发授权码并跳转回客户端
+func (p *provider) authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	params := r.Form
+
+	if params.Get("client_id") != p.clientID || params.Get("response_type") != "code" {
+		http.Error(w, "unknown client_id or unsupported response_type", http.StatusBadRequest)
+		return
+	}
+	if params.Get("code_challenge") == "" || params.Get("code_challenge_method") != "S256" {
+		http.Error(w, "PKCE (S256) is required", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(params.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	email, name := p.autoEmail, ""
+	if r.Method == http.MethodPost {
+		email, name = params.Get("email"), params.Get("name")
+	}
+	if email == "" {
+		form := url.Values{}
+		for _, k := range []string{"client_id", "response_type", "redirect_uri", "scope", "state", "nonce", "code_challenge", "code_challenge_method"} {
+			form.Set(k, params.Get(k))
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		loginPage.Execute(w, form)
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = &authorization{
+		ClientID:      p.clientID,
+		RedirectURI:   redirectURI.String(),
+		Nonce:         params.Get("nonce"),
+		CodeChallenge: params.Get("code_challenge"),
+		Email:         strings.ToLower(email),
+		Name:          name,
+		ExpiresAt:     time.Now().Add(codeTTL),
+	}
+	p.mu.Unlock()
+
+	query := redirectURI.Query()
+	query.Set("code", code)
+	query.Set("state", params.Get("state"))
+	redirectURI.RawQuery = query.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// token 令牌端点：校验授权码、回调地址与PKCE后签发ID令牌
+func (p *provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	auth, ok := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	if r.PostForm.Get("grant_type") != "authorization_code" || !ok || time.Now().After(auth.ExpiresAt) ||
+		auth.RedirectURI != r.PostForm.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(challenge[:]) != auth.CodeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	now := time.Now()
+	sub := sha256.Sum256([]byte(auth.Email))
+	claims := jwt.MapClaims{
+		"iss":                p.issuer,
+		"sub":                hex.EncodeToString(sub[:8]),
+		"aud":                auth.ClientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(5 * time.Minute).Unix(),
+		"nonce":              auth.Nonce,
+		"email":              auth.Email,
+		"email_verified":     true,
+		"name":               auth.Name,
+		"preferred_username": strings.SplitN(auth.Email, "@", 2)[0],
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = p.kid
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// jwks 公钥集合
+func (p *provider) jwks(w http.ResponseWriter, r *http.Request) {
+	jwk, err := jwks.NewJWK(p.kid, p.key.Public())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, jwks.Set{Keys: []jwks.JWK{jwk}})
+}
+
+// writeJSON 输出JSON响应
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("输出响应失败: %v", err)
+	}
+}
+
+// randomString 生成随机字符串
+func randomString() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1 +1,12 @@
-./main
\ No newline at end of file
+#!/bin/sh
+set -e
+
+# 迁移数据库表结构并初始化预置角色
+./main migrate
+
+# 设置 ADMIN_EMAIL 时创建初始超级管理员（已存在则跳过），密码取自 ADMIN_PASSWORD，未设置时随机生成并输出到日志
+if [ -n "$ADMIN_EMAIL" ]; then
+    ./main create-admin -email "$ADMIN_EMAIL" -if-not-exists
+fi
+
+exec ./main serve
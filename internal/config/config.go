@@ -1,8 +1,12 @@
 package config
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"fmt"
 	"log"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -10,12 +14,25 @@ import (
 
 // Config 应用配置结构
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	Log      LogConfig      `mapstructure:"log"`
-	Upload   UploadConfig   `mapstructure:"upload"`
+	Server         ServerConfig         `mapstructure:"server"`
+	Database       DatabaseConfig       `mapstructure:"database"`
+	Redis          RedisConfig          `mapstructure:"redis"`
+	JWT            JWTConfig            `mapstructure:"jwt"`
+	Log            LogConfig            `mapstructure:"log"`
+	Upload         UploadConfig         `mapstructure:"upload"`
+	Mail           MailConfig           `mapstructure:"mail"`
+	Trash          TrashConfig          `mapstructure:"trash"`
+	Retention      RetentionConfig      `mapstructure:"retention"`
+	Offer          OfferConfig          `mapstructure:"offer"`
+	BlindReview    BlindReviewConfig    `mapstructure:"blind_review"`
+	Register       RegisterConfig       `mapstructure:"register"`
+	LoginGuard     LoginGuardConfig     `mapstructure:"login_guard"`
+	TwoFactor      TwoFactorConfig      `mapstructure:"two_factor"`
+	WebAuthn       WebAuthnConfig       `mapstructure:"webauthn"`
+	PasswordReset  PasswordResetConfig  `mapstructure:"password_reset"`
+	PasswordPolicy PasswordPolicyConfig `mapstructure:"password_policy"`
+	OIDC           OIDCConfig           `mapstructure:"oidc"`
+	CAS            CASConfig            `mapstructure:"cas"`
 }
 
 // ServerConfig 服务器配置
@@ -25,6 +42,9 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	BaseURL      string        `mapstructure:"base_url"`
+	// TrustedProxies 可信反向代理的IP或网段，仅信任来自这些地址的 X-Forwarded-For；为空时使用连接的对端地址
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 // DatabaseConfig 数据库配置
@@ -50,8 +70,21 @@ type RedisConfig struct {
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	Secret     string        `mapstructure:"secret"`
-	ExpireTime time.Duration `mapstructure:"expire_time"`
+	Secret            string        `mapstructure:"secret"`
+	ExpireTime        time.Duration `mapstructure:"expire_time"`
+	RefreshExpireTime time.Duration `mapstructure:"refresh_expire_time"`
+	// ActiveKey 当前用于签发令牌的密钥ID，为空时使用 Secret 以 HS256 签发
+	ActiveKey string         `mapstructure:"active_key"`
+	Keys      []JWTKeyConfig `mapstructure:"keys"`
+}
+
+// JWTKeyConfig JWT签名密钥配置，算法由密钥类型决定（RSA 为 RS256，Ed25519 为 EdDSA）
+type JWTKeyConfig struct {
+	ID             string `mapstructure:"id"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+	// VerifyUntil 轮换后仅用于验证的截止时间（RFC 3339），为空表示一直有效
+	VerifyUntil string `mapstructure:"verify_until"`
 }
 
 // LogConfig 日志配置
@@ -72,11 +105,158 @@ type UploadConfig struct {
 	AllowedExt string `mapstructure:"allowed_ext"`
 }
 
+// MailConfig 邮件配置
+type MailConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	FromName string `mapstructure:"from_name"`
+}
+
+// TrashConfig 回收站配置
+type TrashConfig struct {
+	RetentionDays int           `mapstructure:"retention_days"`
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+}
+
+// RetentionConfig 个人信息保留策略配置
+type RetentionConfig struct {
+	Enabled  bool              `mapstructure:"enabled"`
+	Interval time.Duration     `mapstructure:"interval"`
+	Policies []RetentionPolicy `mapstructure:"policies"`
+}
+
+// RetentionPolicy 按申请状态配置的保留期限
+type RetentionPolicy struct {
+	Status    string `mapstructure:"status"`
+	AfterDays int    `mapstructure:"after_days"`
+}
+
+// OfferConfig 录取通知配置
+type OfferConfig struct {
+	LabID         uint          `mapstructure:"lab_id"`
+	ResponseDays  int           `mapstructure:"response_days"`
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+}
+
+// BlindReviewConfig 盲审配置
+type BlindReviewConfig struct {
+	Stages    []string `mapstructure:"stages"`
+	ShowMajor bool     `mapstructure:"show_major"`
+	ShowGrade bool     `mapstructure:"show_grade"`
+}
+
+// RegisterConfig 学生自助注册配置
+type RegisterConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	VerifyTTL      time.Duration `mapstructure:"verify_ttl"`
+	ResendInterval time.Duration `mapstructure:"resend_interval"`
+}
+
+// LoginGuardConfig 登录防暴力破解配置
+type LoginGuardConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	MaxAttempts     int           `mapstructure:"max_attempts"`
+	IPMaxAttempts   int           `mapstructure:"ip_max_attempts"`
+	Window          time.Duration `mapstructure:"window"`
+	LockoutDuration time.Duration `mapstructure:"lockout_duration"`
+	BaseDelay       time.Duration `mapstructure:"base_delay"`
+	MaxDelay        time.Duration `mapstructure:"max_delay"`
+}
+
+// TwoFactorConfig 两步验证配置
+type TwoFactorConfig struct {
+	Issuer           string        `mapstructure:"issuer"`
+	RequireForAdmins bool          `mapstructure:"require_for_admins"`
+	PreAuthTTL       time.Duration `mapstructure:"pre_auth_ttl"`
+	MaxAttempts      int           `mapstructure:"max_attempts"`
+	RecoveryCodes    int           `mapstructure:"recovery_codes"`
+	Skew             int           `mapstructure:"skew"`
+}
+
+// WebAuthnConfig 通行密钥（WebAuthn）配置
+type WebAuthnConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	AdminOnly     bool          `mapstructure:"admin_only"`
+	RPID          string        `mapstructure:"rp_id"`
+	RPDisplayName string        `mapstructure:"rp_display_name"`
+	RPOrigins     []string      `mapstructure:"rp_origins"`
+	Timeout       time.Duration `mapstructure:"timeout"`
+}
+
+// PasswordResetConfig 找回密码配置
+type PasswordResetConfig struct {
+	TokenTTL        time.Duration `mapstructure:"token_ttl"`
+	RequestInterval time.Duration `mapstructure:"request_interval"`
+	URL             string        `mapstructure:"url"`
+}
+
+// PasswordPolicyConfig 密码策略配置
+type PasswordPolicyConfig struct {
+	MinLength      int    `mapstructure:"min_length"`
+	MaxLength      int    `mapstructure:"max_length"`
+	RequireUpper   bool   `mapstructure:"require_upper"`
+	RequireLower   bool   `mapstructure:"require_lower"`
+	RequireDigit   bool   `mapstructure:"require_digit"`
+	RequireSymbol  bool   `mapstructure:"require_symbol"`
+	MinCharClasses int    `mapstructure:"min_char_classes"`
+	ForbidUserInfo bool   `mapstructure:"forbid_user_info"`
+	CheckCommon    bool   `mapstructure:"check_common"`
+	CommonListFile string `mapstructure:"common_list_file"`
+	HistoryCount   int    `mapstructure:"history_count"`
+}
+
+// OIDCConfig OpenID Connect 单点登录配置
+type OIDCConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	DisplayName    string        `mapstructure:"display_name"`
+	Issuer         string        `mapstructure:"issuer"`
+	ClientID       string        `mapstructure:"client_id"`
+	ClientSecret   string        `mapstructure:"client_secret"`
+	RedirectURL    string        `mapstructure:"redirect_url"`
+	Scopes         []string      `mapstructure:"scopes"`
+	FrontendURL    string        `mapstructure:"frontend_url"`
+	AutoCreate     bool          `mapstructure:"auto_create"`
+	DefaultRole    string        `mapstructure:"default_role"`
+	AllowedDomains []string      `mapstructure:"allowed_domains"`
+	StateTTL       time.Duration `mapstructure:"state_ttl"`
+}
+
+// CASConfig CAS 统一身份认证配置
+type CASConfig struct {
+	Enabled     bool                `mapstructure:"enabled"`
+	DisplayName string              `mapstructure:"display_name"`
+	ServerURL   string              `mapstructure:"server_url"`
+	ServiceURL  string              `mapstructure:"service_url"`
+	Version     string              `mapstructure:"version"`
+	FrontendURL string              `mapstructure:"frontend_url"`
+	AutoCreate  bool                `mapstructure:"auto_create"`
+	EmailDomain string              `mapstructure:"email_domain"`
+	Attributes  CASAttributesConfig `mapstructure:"attributes"`
+}
+
+// CASAttributesConfig CAS 属性名映射
+type CASAttributesConfig struct {
+	Email string `mapstructure:"email"`
+	Name  string `mapstructure:"name"`
+	Major string `mapstructure:"major"`
+	Grade string `mapstructure:"grade"`
+}
+
 var (
 	// GlobalConfig 全局配置实例
 	GlobalConfig *Config
 )
 
+// placeholderJWTSecrets 示例配置与文档中出现过的JWT密钥，启动时拒绝使用
+var placeholderJWTSecrets = []string{
+	"your-secret-key-here-change-in-production",
+	"your_jwt_secret_key_change_in_production",
+	"your_jwt_secret_key_here",
+	"your_jwt_secret_key",
+}
+
 // LoadConfig 加载配置
 func LoadConfig(configPath string) (*Config, error) {
 	viper.SetConfigFile(configPath)
@@ -119,6 +299,7 @@ func setDefaults() {
 	viper.SetDefault("server.read_timeout", "30s")
 	viper.SetDefault("server.write_timeout", "30s")
 	viper.SetDefault("server.idle_timeout", "60s")
+	viper.SetDefault("server.base_url", "http://localhost:8080")
 
 	// 数据库默认配置
 	viper.SetDefault("database.host", "localhost")
@@ -134,7 +315,8 @@ func setDefaults() {
 	viper.SetDefault("redis.db", 0)
 
 	// JWT默认配置
-	viper.SetDefault("jwt.expire_time", "24h")
+	viper.SetDefault("jwt.expire_time", "15m")
+	viper.SetDefault("jwt.refresh_expire_time", "168h")
 
 	// 日志默认配置
 	viper.SetDefault("log.level", "info")
@@ -149,6 +331,93 @@ func setDefaults() {
 	viper.SetDefault("upload.path", "uploads")
 	viper.SetDefault("upload.max_size", 10485760) // 10MB
 	viper.SetDefault("upload.allowed_ext", "jpg,jpeg,png,gif,pdf,doc,docx")
+
+	// 邮件默认配置
+	viper.SetDefault("mail.host", "smtp.qq.com")
+	viper.SetDefault("mail.port", 587)
+	viper.SetDefault("mail.from_name", "EPI实验室")
+
+	// 回收站默认配置
+	viper.SetDefault("trash.retention_days", 30)
+	viper.SetDefault("trash.purge_interval", "24h")
+
+	// 个人信息保留策略默认配置
+	viper.SetDefault("retention.enabled", false)
+	viper.SetDefault("retention.interval", "24h")
+
+	// 录取通知默认配置
+	viper.SetDefault("offer.lab_id", 0)
+	viper.SetDefault("offer.response_days", 7)
+	viper.SetDefault("offer.check_interval", "1h")
+
+	// 盲审默认配置
+	viper.SetDefault("blind_review.stages", []string{})
+	viper.SetDefault("blind_review.show_major", true)
+	viper.SetDefault("blind_review.show_grade", true)
+
+	// 学生注册默认配置
+	viper.SetDefault("register.enabled", true)
+	viper.SetDefault("register.verify_ttl", "24h")
+	viper.SetDefault("register.resend_interval", "1m")
+
+	// 登录防暴力破解默认配置
+	viper.SetDefault("login_guard.enabled", true)
+	viper.SetDefault("login_guard.max_attempts", 5)
+	viper.SetDefault("login_guard.ip_max_attempts", 20)
+	viper.SetDefault("login_guard.window", "15m")
+	viper.SetDefault("login_guard.lockout_duration", "15m")
+	viper.SetDefault("login_guard.base_delay", "1s")
+	viper.SetDefault("login_guard.max_delay", "30s")
+
+	// 两步验证默认配置
+	viper.SetDefault("two_factor.issuer", "EPI实验室招新平台")
+	viper.SetDefault("two_factor.require_for_admins", false)
+	viper.SetDefault("two_factor.pre_auth_ttl", "5m")
+	viper.SetDefault("two_factor.max_attempts", 5)
+	viper.SetDefault("two_factor.recovery_codes", 10)
+	viper.SetDefault("two_factor.skew", 1)
+
+	// 通行密钥默认配置
+	viper.SetDefault("webauthn.enabled", true)
+	viper.SetDefault("webauthn.admin_only", true)
+	viper.SetDefault("webauthn.rp_id", "localhost")
+	viper.SetDefault("webauthn.rp_display_name", "EPI实验室招新平台")
+	viper.SetDefault("webauthn.rp_origins", []string{"http://localhost:8080"})
+	viper.SetDefault("webauthn.timeout", "5m")
+
+	// 找回密码默认配置
+	viper.SetDefault("password_reset.token_ttl", "30m")
+	viper.SetDefault("password_reset.request_interval", "1m")
+	viper.SetDefault("password_reset.url", "")
+
+	// 密码策略默认配置
+	viper.SetDefault("password_policy.min_length", 8)
+	viper.SetDefault("password_policy.max_length", 72)
+	viper.SetDefault("password_policy.require_upper", false)
+	viper.SetDefault("password_policy.require_lower", false)
+	viper.SetDefault("password_policy.require_digit", false)
+	viper.SetDefault("password_policy.require_symbol", false)
+	viper.SetDefault("password_policy.min_char_classes", 2)
+	viper.SetDefault("password_policy.forbid_user_info", true)
+	viper.SetDefault("password_policy.check_common", true)
+	viper.SetDefault("password_policy.common_list_file", "")
+	viper.SetDefault("password_policy.history_count", 5)
+
+	// 单点登录默认配置
+	viper.SetDefault("oidc.enabled", false)
+	viper.SetDefault("oidc.display_name", "统一身份认证")
+	viper.SetDefault("oidc.scopes", []string{"openid", "email", "profile"})
+	viper.SetDefault("oidc.auto_create", true)
+	viper.SetDefault("oidc.default_role", "student")
+	viper.SetDefault("oidc.state_ttl", "10m")
+	viper.SetDefault("cas.enabled", false)
+	viper.SetDefault("cas.display_name", "统一身份认证（学号登录）")
+	viper.SetDefault("cas.version", "3.0")
+	viper.SetDefault("cas.auto_create", true)
+	viper.SetDefault("cas.attributes.email", "mail")
+	viper.SetDefault("cas.attributes.name", "cn")
+	viper.SetDefault("cas.attributes.major", "major")
+	viper.SetDefault("cas.attributes.grade", "grade")
 }
 
 // bindEnvs 绑定环境变量
@@ -156,6 +425,8 @@ func bindEnvs() {
 	// 服务器环境变量
 	viper.BindEnv("server.port", "SERVER_PORT")
 	viper.BindEnv("server.mode", "SERVER_MODE")
+	viper.BindEnv("server.base_url", "SERVER_BASE_URL")
+	viper.BindEnv("server.trusted_proxies", "TRUSTED_PROXIES")
 
 	// 数据库环境变量
 	viper.BindEnv("database.host", "DB_HOST")
@@ -173,6 +444,12 @@ func bindEnvs() {
 	// JWT环境变量
 	viper.BindEnv("jwt.secret", "JWT_SECRET")
 	viper.BindEnv("jwt.expire_time", "JWT_EXPIRE_HOURS")
+	viper.BindEnv("jwt.refresh_expire_time", "JWT_REFRESH_EXPIRE")
+	viper.BindEnv("jwt.active_key", "JWT_ACTIVE_KEY")
+
+	// 单点登录环境变量
+	viper.BindEnv("oidc.client_id", "OIDC_CLIENT_ID")
+	viper.BindEnv("oidc.client_secret", "OIDC_CLIENT_SECRET")
 
 	// 日志环境变量
 	viper.BindEnv("log.level", "LOG_LEVEL")
@@ -181,6 +458,12 @@ func bindEnvs() {
 	// 文件上传环境变量
 	viper.BindEnv("upload.path", "UPLOAD_PATH")
 	viper.BindEnv("upload.max_size", "MAX_FILE_SIZE")
+
+	// 邮件环境变量
+	viper.BindEnv("mail.host", "SMTP_HOST")
+	viper.BindEnv("mail.port", "SMTP_PORT")
+	viper.BindEnv("mail.username", "SMTP_USER")
+	viper.BindEnv("mail.password", "SMTP_PASS")
 }
 
 // validateConfig 验证配置
@@ -189,6 +472,9 @@ func validateConfig(config *Config) error {
 	if config.Server.Port == "" {
 		return fmt.Errorf("服务器端口不能为空")
 	}
+	if err := validateTrustedProxies(config.Server.TrustedProxies); err != nil {
+		return err
+	}
 
 	// 验证数据库配置
 	if config.Database.Host == "" {
@@ -202,13 +488,124 @@ func validateConfig(config *Config) error {
 	}
 
 	// 验证JWT配置
-	if config.JWT.Secret == "" {
-		return fmt.Errorf("JWT密钥不能为空")
+	if err := validateJWTSecret(config.JWT.Secret); err != nil {
+		return err
+	}
+	if config.JWT.RefreshExpireTime <= config.JWT.ExpireTime {
+		return fmt.Errorf("刷新令牌有效期必须大于访问令牌有效期")
+	}
+	if err := validateJWTKeys(&config.JWT); err != nil {
+		return err
+	}
+
+	// 验证个人信息保留策略
+	for _, policy := range config.Retention.Policies {
+		if !isApplicationStatus(policy.Status) {
+			return fmt.Errorf("保留策略状态无效: %s", policy.Status)
+		}
+		if policy.AfterDays <= 0 {
+			return fmt.Errorf("保留策略天数必须大于0: %s", policy.Status)
+		}
+	}
+
+	// 验证盲审阶段
+	for _, stage := range config.BlindReview.Stages {
+		if !isApplicationStatus(stage) {
+			return fmt.Errorf("盲审阶段无效: %s", stage)
+		}
+	}
+
+	// 验证录取通知配置
+	if config.Offer.ResponseDays <= 0 {
+		return fmt.Errorf("录取通知答复期限必须大于0")
+	}
+
+	// 验证注册配置
+	if config.Register.VerifyTTL <= 0 {
+		return fmt.Errorf("邮箱验证链接有效期必须大于0")
+	}
+
+	// 验证两步验证配置
+	if config.TwoFactor.PreAuthTTL <= 0 || config.TwoFactor.MaxAttempts <= 0 {
+		return fmt.Errorf("两步验证预认证有效期与尝试次数必须大于0")
+	}
+	if config.TwoFactor.RecoveryCodes <= 0 || config.TwoFactor.Skew < 0 {
+		return fmt.Errorf("两步验证恢复码数量必须大于0且时间偏移不能为负")
+	}
+
+	// 验证通行密钥配置
+	if config.WebAuthn.Enabled {
+		if config.WebAuthn.RPID == "" || len(config.WebAuthn.RPOrigins) == 0 {
+			return fmt.Errorf("通行密钥的RP ID与允许的来源不能为空")
+		}
+		if config.WebAuthn.Timeout <= 0 {
+			return fmt.Errorf("通行密钥认证超时时间必须大于0")
+		}
+	}
+
+	// 验证找回密码配置
+	if config.PasswordReset.TokenTTL <= 0 {
+		return fmt.Errorf("重置密码链接有效期必须大于0")
+	}
+
+	// 验证密码策略配置（bcrypt 只使用密码的前72个字节）
+	if config.PasswordPolicy.MinLength < 6 {
+		return fmt.Errorf("密码最小长度不能小于6")
+	}
+	if config.PasswordPolicy.MaxLength < config.PasswordPolicy.MinLength || config.PasswordPolicy.MaxLength > 72 {
+		return fmt.Errorf("密码最大长度必须介于最小长度与72之间")
+	}
+	if config.PasswordPolicy.MinCharClasses < 0 || config.PasswordPolicy.MinCharClasses > 4 {
+		return fmt.Errorf("密码至少包含的字符类别数必须介于0到4之间")
+	}
+	if config.PasswordPolicy.HistoryCount < 0 {
+		return fmt.Errorf("密码历史记录数不能为负数")
+	}
+
+	// 验证单点登录配置
+	if config.OIDC.Enabled {
+		if config.OIDC.Issuer == "" || config.OIDC.ClientID == "" || config.OIDC.RedirectURL == "" {
+			return fmt.Errorf("单点登录的issuer、client_id与redirect_url不能为空")
+		}
+		if config.OIDC.DefaultRole != "student" && config.OIDC.DefaultRole != "admin" {
+			return fmt.Errorf("单点登录自动创建账户的默认角色只能为student或admin")
+		}
+		if config.OIDC.StateTTL <= 0 {
+			return fmt.Errorf("单点登录状态有效期必须大于0")
+		}
+	}
+
+	if config.CAS.Enabled {
+		if config.CAS.ServerURL == "" || config.CAS.ServiceURL == "" {
+			return fmt.Errorf("CAS的server_url与service_url不能为空")
+		}
+		if config.CAS.Version != "2.0" && config.CAS.Version != "3.0" {
+			return fmt.Errorf("CAS协议版本只能为2.0或3.0")
+		}
+	}
+
+	// 验证登录防暴力破解配置
+	if config.LoginGuard.Enabled {
+		if config.LoginGuard.MaxAttempts <= 0 || config.LoginGuard.IPMaxAttempts <= 0 {
+			return fmt.Errorf("登录失败次数阈值必须大于0")
+		}
+		if config.LoginGuard.Window <= 0 || config.LoginGuard.LockoutDuration <= 0 {
+			return fmt.Errorf("登录失败统计窗口与锁定时长必须大于0")
+		}
 	}
 
 	return nil
 }
 
+// isApplicationStatus 判断是否为有效的面试申请状态
+func isApplicationStatus(status string) bool {
+	switch status {
+	case "pending", "interviewed", "passed", "rejected":
+		return true
+	}
+	return false
+}
+
 // GetDSN 获取数据库连接字符串
 func (c *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local",
@@ -225,12 +622,129 @@ func (c *JWTConfig) GetJWTExpireHours() int {
 	return int(c.ExpireTime.Hours())
 }
 
+// DeriveKey 由 JWT 密钥派生指定用途的 HMAC 密钥，不同用途使用互相独立的密钥
+func (c *JWTConfig) DeriveKey(purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(c.Secret))
+	mac.Write([]byte("lab-recruitment-platform/" + purpose))
+	return mac.Sum(nil)
+}
+
+// GetAllowedExts 获取允许上传的文件扩展名列表（小写，不含点）
+func (c *UploadConfig) GetAllowedExts() []string {
+	exts := make([]string, 0)
+	for _, ext := range strings.Split(c.AllowedExt, ",") {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if ext != "" {
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
+
+// IsTestMode 是否为邮件测试模式（未配置账号时只记录日志不实际发送）
+func (c *MailConfig) IsTestMode() bool {
+	return c.Host == "test" || c.Username == "" || c.Password == ""
+}
+
+// GetRetention 获取回收站保留时长
+func (c *TrashConfig) GetRetention() time.Duration {
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// GetResponseWindow 获取录取通知默认答复期限
+func (c *OfferConfig) GetResponseWindow() time.Duration {
+	return time.Duration(c.ResponseDays) * 24 * time.Hour
+}
+
+// IsBlind 判断指定申请状态是否处于盲审阶段
+func (c *BlindReviewConfig) IsBlind(status string) bool {
+	for _, stage := range c.Stages {
+		if stage == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled 是否开启了盲审
+func (c *BlindReviewConfig) Enabled() bool {
+	return len(c.Stages) > 0
+}
+
 // IsDevelopment 是否为开发环境
 func (c *ServerConfig) IsDevelopment() bool {
 	return c.Mode == "debug" || c.Mode == "development"
 }
 
+// validateJWTSecret 验证JWT密钥已设置且不是示例配置中的占位值
+func validateJWTSecret(secret string) error {
+	if secret == "" {
+		return fmt.Errorf("JWT密钥不能为空")
+	}
+	for _, placeholder := range placeholderJWTSecrets {
+		if secret == placeholder {
+			return fmt.Errorf("JWT密钥仍为示例值，请通过 jwt.secret 或环境变量 JWT_SECRET 设置随机密钥")
+		}
+	}
+	return nil
+}
+
+// validateTrustedProxies 验证可信代理均为IP地址或CIDR网段
+func validateTrustedProxies(proxies []string) error {
+	for _, proxy := range proxies {
+		if net.ParseIP(proxy) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			return fmt.Errorf("可信代理地址格式错误: %q", proxy)
+		}
+	}
+	return nil
+}
+
+// validateJWTKeys 验证JWT签名密钥配置
+func validateJWTKeys(cfg *JWTConfig) error {
+	ids := make(map[string]bool, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		if key.ID == "" {
+			return fmt.Errorf("JWT密钥ID不能为空")
+		}
+		if ids[key.ID] {
+			return fmt.Errorf("JWT密钥ID重复: %s", key.ID)
+		}
+		ids[key.ID] = true
+
+		if key.PrivateKeyFile == "" && key.PublicKeyFile == "" {
+			return fmt.Errorf("JWT密钥 %s 未配置私钥或公钥文件", key.ID)
+		}
+		if key.VerifyUntil != "" {
+			if _, err := time.Parse(time.RFC3339, key.VerifyUntil); err != nil {
+				return fmt.Errorf("JWT密钥 %s 的 verify_until 格式错误，应为RFC 3339时间", key.ID)
+			}
+		}
+	}
+
+	if cfg.ActiveKey == "" {
+		return nil
+	}
+	for _, key := range cfg.Keys {
+		if key.ID == cfg.ActiveKey {
+			if key.PrivateKeyFile == "" {
+				return fmt.Errorf("当前签发密钥 %s 必须配置私钥文件", key.ID)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("当前签发密钥 %s 不在密钥列表中", cfg.ActiveKey)
+}
+
+// VerifyUntilTime 解析验证截止时间，未配置时返回零值
+func (k *JWTKeyConfig) VerifyUntilTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, k.VerifyUntil)
+	return t
+}
+
 // IsProduction 是否为生产环境
 func (c *ServerConfig) IsProduction() bool {
 	return c.Mode == "release" || c.Mode == "production"
-} 
\ No newline at end of file
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestValidateJWTSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr bool
+	}{
+		{"随机密钥", "2f1c9e0a7b4d6e8f3a5c7e9b1d3f5a7c", false},
+		{"为空", "", true},
+		{"config.yaml 示例值", "your-secret-key-here-change-in-production", true},
+		{"docker-compose 示例值", "your_jwt_secret_key_change_in_production", true},
+		{"文档示例值", "your_jwt_secret_key", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateJWTSecret(tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateJWTSecret(%q) error = %v, wantErr %v", tt.secret, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJWTConfigDeriveKey(t *testing.T) {
+	cfg := &JWTConfig{Secret: "2f1c9e0a7b4d6e8f3a5c7e9b1d3f5a7c"}
+	other := &JWTConfig{Secret: "another-secret-0123456789abcdef"}
+
+	tests := []struct {
+		name     string
+		a, b     []byte
+		wantSame bool
+	}{
+		{"相同用途结果稳定", cfg.DeriveKey("email-verification"), cfg.DeriveKey("email-verification"), true},
+		{"不同用途互相独立", cfg.DeriveKey("email-verification"), cfg.DeriveKey("candidate-code"), false},
+		{"不同密钥互相独立", cfg.DeriveKey("candidate-code"), other.DeriveKey("candidate-code"), false},
+		{"派生密钥不等于原密钥", cfg.DeriveKey(""), []byte(cfg.Secret), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := bytes.Equal(tt.a, tt.b); same != tt.wantSame {
+				t.Errorf("bytes.Equal = %v, want %v", same, tt.wantSame)
+			}
+			if len(tt.a) != 32 {
+				t.Errorf("派生密钥长度 = %d, want 32", len(tt.a))
+			}
+		})
+	}
+}
+
+func TestValidateJWTKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     JWTConfig
+		wantErr bool
+	}{
+		{"未配置非对称密钥", JWTConfig{}, false},
+		{"签发密钥与宽限期验证密钥", JWTConfig{
+			ActiveKey: "new",
+			Keys: []JWTKeyConfig{
+				{ID: "new", PrivateKeyFile: "keys/new.pem"},
+				{ID: "old", PublicKeyFile: "keys/old.pub.pem", VerifyUntil: "2026-10-19T00:00:00+08:00"},
+			},
+		}, false},
+		{"密钥ID为空", JWTConfig{Keys: []JWTKeyConfig{{PrivateKeyFile: "k.pem"}}}, true},
+		{"密钥ID重复", JWTConfig{Keys: []JWTKeyConfig{{ID: "a", PrivateKeyFile: "a.pem"}, {ID: "a", PublicKeyFile: "a.pub.pem"}}}, true},
+		{"未配置密钥文件", JWTConfig{Keys: []JWTKeyConfig{{ID: "a"}}}, true},
+		{"verify_until格式错误", JWTConfig{Keys: []JWTKeyConfig{{ID: "a", PublicKeyFile: "a.pub.pem", VerifyUntil: "2026-10-19"}}}, true},
+		{"签发密钥不在列表中", JWTConfig{ActiveKey: "b", Keys: []JWTKeyConfig{{ID: "a", PrivateKeyFile: "a.pem"}}}, true},
+		{"签发密钥只有公钥", JWTConfig{ActiveKey: "a", Keys: []JWTKeyConfig{{ID: "a", PublicKeyFile: "a.pub.pem"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateJWTKeys(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateJWTKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []string
+		wantErr bool
+	}{
+		{"未配置", nil, false},
+		{"IPv4地址与网段", []string{"127.0.0.1", "172.16.0.0/12"}, false},
+		{"IPv6地址与网段", []string{"::1", "fd00::/8"}, false},
+		{"主机名", []string{"nginx"}, true},
+		{"网段格式错误", []string{"10.0.0.0/33"}, true},
+		{"包含空格", []string{" 10.0.0.1"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTrustedProxies(tt.proxies)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateTrustedProxies(%v) error = %v, wantErr %v", tt.proxies, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+)
+
+// AnalyticsHandler 招新数据分析处理器
+type AnalyticsHandler struct {
+	analyticsService *services.AnalyticsService
+}
+
+// NewAnalyticsHandler 创建数据分析处理器实例
+func NewAnalyticsHandler() *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: services.NewAnalyticsService(),
+	}
+}
+
+// GetRecruitmentAnalytics 获取招新数据分析（管理员接口）
+// @Summary 获取招新数据分析
+// @Description 获取每日申请趋势、专业/年级分布、转化漏斗和状态停留时长，默认统计最近30天
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "开始日期（YYYY-MM-DD）"
+// @Param to query string false "结束日期（YYYY-MM-DD，含当天）"
+// @Success 200 {object} response.Response{data=models.RecruitmentAnalytics}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /admin/analytics [get]
+func (h *AnalyticsHandler) GetRecruitmentAnalytics(c *gin.Context) {
+	filter, err := services.NewAnalyticsFilter(c.Query("from"), c.Query("to"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	analytics, err := h.analyticsService.GetRecruitmentAnalytics(filter)
+	if err != nil {
+		logger.Errorf("获取招新数据分析失败: %v", err)
+		response.InternalServerError(c, "获取统计数据失败")
+		return
+	}
+
+	response.Success(c, analytics)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+	"lab-recruitment-platform/pkg/validator"
+)
+
+// APITokenHandler 个人API令牌处理器
+type APITokenHandler struct {
+	userService     *services.UserService
+	apiTokenService *services.APITokenService
+	auditService    *services.AuditService
+}
+
+// NewAPITokenHandler 创建个人API令牌处理器实例
+func NewAPITokenHandler() *APITokenHandler {
+	return &APITokenHandler{
+		userService:     services.NewUserService(),
+		apiTokenService: services.NewAPITokenService(),
+		auditService:    services.NewAuditService(),
+	}
+}
+
+// ListTokens 获取当前用户的API令牌
+// @Summary API令牌列表
+// @Description 列出当前账户的API令牌（含已撤销与已过期的），不返回令牌明文
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.APIToken}
+// @Router /auth/api-tokens [get]
+func (h *APITokenHandler) ListTokens(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未登录")
+		return
+	}
+
+	tokens, err := h.apiTokenService.ListTokens(userID)
+	if err != nil {
+		logger.Errorf("获取API令牌列表失败: %v", err)
+		response.InternalServerError(c, "获取API令牌列表失败")
+		return
+	}
+	response.Success(c, gin.H{
+		"tokens":           tokens,
+		"available_scopes": models.APITokenScopes,
+	})
+}
+
+// CreateToken 创建API令牌
+// @Summary 创建API令牌
+// @Description 创建供脚本与集成使用的个人API令牌，权限范围只能从账户当前拥有的权限中选择，令牌明文仅在创建时返回一次
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.APITokenCreateRequest true "令牌名称、权限范围与有效期"
+// @Success 200 {object} response.Response{data=models.APITokenCreateResponse}
+// @Failure 400 {object} response.Response
+// @Router /auth/api-tokens [post]
+func (h *APITokenHandler) CreateToken(c *gin.Context) {
+	var req models.APITokenCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未登录")
+		return
+	}
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil {
+		response.NotFound(c, "用户不存在")
+		return
+	}
+
+	record, token, err := h.apiTokenService.CreateToken(user, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAPITokenScope) || errors.Is(err, services.ErrTooManyAPITokens) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Errorf("创建API令牌失败: %v", err)
+		response.InternalServerError(c, "创建API令牌失败")
+		return
+	}
+
+	h.audit(c, "auth.api_token_create", userID, models.JSONMap{
+		"token_id": record.ID,
+		"name":     record.Name,
+		"scopes":   []string(record.Scopes),
+	})
+	response.SuccessWithMessage(c, "API令牌已创建，请立即保存，关闭后将无法再次查看", models.APITokenCreateResponse{
+		Token:    token,
+		APIToken: record,
+	})
+}
+
+// RevokeToken 撤销API令牌
+// @Summary 撤销API令牌
+// @Description 撤销后使用该令牌的请求立即失效
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API令牌ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /auth/api-tokens/{id} [delete]
+func (h *APITokenHandler) RevokeToken(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的API令牌ID")
+		return
+	}
+
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未登录")
+		return
+	}
+
+	if err := h.apiTokenService.RevokeToken(userID, uint(id)); err != nil {
+		if errors.Is(err, services.ErrAPITokenNotFound) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		logger.Errorf("撤销API令牌失败: %v", err)
+		response.InternalServerError(c, "撤销API令牌失败")
+		return
+	}
+
+	h.audit(c, "auth.api_token_revoke", userID, models.JSONMap{"token_id": id})
+	response.SuccessWithMessage(c, "API令牌已撤销", nil)
+}
+
+// audit 记录API令牌相关操作
+func (h *APITokenHandler) audit(c *gin.Context, action string, userID uint, details models.JSONMap) {
+	h.auditService.Record(services.AuditEntry{
+		UserID:       &userID,
+		Action:       action,
+		ResourceType: "user",
+		ResourceID:   &userID,
+		Details:      details,
+	}.WithRequest(c))
+}
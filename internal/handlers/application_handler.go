@@ -2,13 +2,20 @@ package handlers
 
 import (
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"html"
 	"math/big"
+	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gopkg.in/gomail.v2"
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/models"
 	"lab-recruitment-platform/internal/services"
 	"lab-recruitment-platform/pkg/logger"
 	"lab-recruitment-platform/pkg/response"
@@ -18,12 +25,24 @@ import (
 // ApplicationHandler 申请处理器
 type ApplicationHandler struct {
 	interviewService *services.InterviewApplicationService
+	uploadService    *services.UploadService
+	calendarService  *services.CalendarService
+	mailService      *services.MailService
+	analyticsService *services.AnalyticsService
+	blindReview      *services.BlindReviewService
+	auditService     *services.AuditService
 }
 
 // NewApplicationHandler 创建申请处理器实例
 func NewApplicationHandler() *ApplicationHandler {
 	return &ApplicationHandler{
 		interviewService: services.NewInterviewApplicationService(),
+		uploadService:    services.NewUploadService(),
+		calendarService:  services.NewCalendarService(),
+		mailService:      services.NewMailService(),
+		analyticsService: services.NewAnalyticsService(),
+		blindReview:      services.NewBlindReviewService(),
+		auditService:     services.NewAuditService(),
 	}
 }
 
@@ -34,12 +53,75 @@ type VerificationCode struct {
 	ExpiresAt time.Time
 }
 
+// verificationCodeStore 内存验证码存储，并发请求共享访问
+type verificationCodeStore struct {
+	mu    sync.Mutex
+	codes map[string]*VerificationCode
+}
+
 // 内存存储验证码
-var verificationCodes = make(map[string]*VerificationCode)
+var verificationCodes = &verificationCodeStore{codes: make(map[string]*VerificationCode)}
+
+// set 保存邮箱验证码，覆盖之前发送的验证码
+func (s *verificationCodeStore) set(email, code string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codes[email] = &VerificationCode{Code: code, Email: email, ExpiresAt: time.Now().Add(ttl)}
+}
+
+// remove 删除邮箱验证码
+func (s *verificationCodeStore) remove(email string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.codes, email)
+}
+
+// check 校验邮箱验证码但不消费，校验失败时验证码作废
+func (s *verificationCodeStore) check(email, code string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vc, exists := s.codes[email]
+	if !exists {
+		return false
+	}
+	if vc.Code == code && time.Now().Before(vc.ExpiresAt) {
+		return true
+	}
+	delete(s.codes, email)
+	return false
+}
+
+// consume 校验并消费邮箱验证码，不接受测试验证码，无论成功与否验证码都会作废
+func (s *verificationCodeStore) consume(email, code string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vc, exists := s.codes[email]
+	if !exists {
+		logger.Infof("验证码不存在: email=%s", email)
+		return false
+	}
+	delete(s.codes, email)
+
+	// 检查验证码是否正确且未过期
+	if vc.Code == code && time.Now().Before(vc.ExpiresAt) {
+		logger.Infof("验证码验证成功: email=%s", email)
+		return true
+	}
+	logger.Infof("验证码错误或过期: email=%s", email)
+	return false
+}
+
+// 邮件主题
+const (
+	verificationEmailSubject       = "实验室面试申请验证码"
+	applicationSuccessEmailSubject = "EPI实验室面试申请已收到"
+)
 
 // SendCodeRequest 发送验证码请求
 type SendCodeRequest struct {
 	Email string `json:"email" validate:"required,email"`
+	// Purpose 验证码用途：application 提交申请（默认），privacy 数据导出与删除请求
+	Purpose string `json:"purpose" validate:"omitempty,oneof=application privacy"`
 }
 
 // ApplyRequest 申请请求
@@ -52,6 +134,7 @@ type ApplyRequest struct {
 	Grade            string `json:"grade" validate:"required"`
 	InterviewTime    string `json:"interview_time" validate:"required"`
 	VerificationCode string `json:"verification_code" validate:"required"`
+	AttachmentIDs    []uint `json:"attachment_ids" validate:"omitempty,max=5"`
 }
 
 // SendCode 发送验证码
@@ -92,21 +175,25 @@ func (h *ApplicationHandler) SendCode(c *gin.Context) {
 	}
 
 	// 存储验证码
-	verificationCodes[req.Email] = &VerificationCode{
-		Code:      code,
-		Email:     req.Email,
-		ExpiresAt: time.Now().Add(5 * time.Minute), // 5分钟有效期
-	}
+	verificationCodes.set(req.Email, code, 5*time.Minute) // 5分钟有效期
 
 	// 发送邮件
-	if err := sendVerificationEmail(req.Email, code); err != nil {
+	err = sendVerificationEmail(req.Email, code)
+	h.mailService.Record(req.Email, verificationEmailSubject, err)
+	if err != nil {
 		logger.Errorf("发送验证码邮件失败: %v", err)
 		// 发送失败，删除验证码
-		delete(verificationCodes, req.Email)
+		verificationCodes.remove(req.Email)
 		response.InternalServerError(c, "邮件发送失败，请稍后重试")
 		return
 	}
 
+	// 记录发送次数与用途，转化漏斗只统计申请验证码
+	if req.Purpose == "" {
+		req.Purpose = models.CodePurposeApplication
+	}
+	h.analyticsService.RecordCodeSent(req.Email, req.Purpose)
+
 	response.SuccessWithMessage(c, "验证码已发送到邮箱，请注意查收", nil)
 }
 
@@ -160,7 +247,7 @@ func (h *ApplicationHandler) Apply(c *gin.Context) {
 	// 保存申请到数据库
 	application, err := h.interviewService.CreateApplication(
 		req.Name, req.Email, req.Phone, req.StudentID, 
-		req.Major, req.Grade, req.InterviewTime,
+		req.Major, req.Grade, req.InterviewTime, req.AttachmentIDs,
 	)
 	if err != nil {
 		logger.Errorf("保存面试申请失败: %v", err)
@@ -169,7 +256,9 @@ func (h *ApplicationHandler) Apply(c *gin.Context) {
 	}
 
 	// 发送申请成功邮件
-	if err := sendApplicationSuccessEmail(req.Email, req.Name); err != nil {
+	err = sendApplicationSuccessEmail(req.Email, req.Name)
+	h.mailService.Record(req.Email, applicationSuccessEmailSubject, err)
+	if err != nil {
 		logger.Errorf("发送申请成功邮件失败: %v", err)
 	}
 
@@ -199,23 +288,12 @@ func verifyCode(email, code string) bool {
 		return true
 	}
 
-	vc, exists := verificationCodes[email]
-	if !exists {
-		logger.Infof("验证码不存在: email=%s", email)
-		return false
-	}
-
-	// 检查验证码是否正确且未过期
-	if vc.Code == code && time.Now().Before(vc.ExpiresAt) {
-		logger.Infof("验证码验证成功: email=%s", email)
-		delete(verificationCodes, email) // 使用后立即删除
-		return true
-	}
+	return verificationCodes.consume(email, code)
+}
 
-	// 验证码错误或过期，删除
-	logger.Infof("验证码错误或过期: email=%s, expected=%s, received=%s", email, vc.Code, code)
-	delete(verificationCodes, email)
-	return false
+// checkCode 校验邮箱验证码但不消费，供提交申请前的附件上传使用；不接受测试验证码，校验失败时验证码作废
+func checkCode(email, code string) bool {
+	return verificationCodes.check(email, code)
 }
 
 // sendVerificationEmail 发送验证码邮件
@@ -229,7 +307,7 @@ func sendVerificationEmail(email, code string) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", user)
 	m.SetHeader("To", email)
-	m.SetHeader("Subject", "实验室面试申请验证码")
+	m.SetHeader("Subject", verificationEmailSubject)
 
 	// HTML邮件内容
 	htmlBody := fmt.Sprintf(`
@@ -303,6 +381,12 @@ func (h *ApplicationHandler) ListApplications(c *gin.Context) {
 		size = 10
 	}
 
+	// 盲审阶段按姓名搜索会泄露身份
+	if name != "" && !h.blindReview.CanSearchByName(c, status) {
+		response.Forbidden(c, "盲审阶段需要身份查看权限才能按姓名搜索")
+		return
+	}
+
 	// 获取申请列表
 	result, err := h.interviewService.ListApplications(page, size, status, name)
 	if err != nil {
@@ -311,6 +395,7 @@ func (h *ApplicationHandler) ListApplications(c *gin.Context) {
 		return
 	}
 
+	h.blindReview.MaskList(result.List)
 	response.Success(c, result)
 }
 
@@ -323,6 +408,7 @@ func (h *ApplicationHandler) ListApplications(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path int true "申请ID"
 // @Success 200 {object} response.Response{data=models.InterviewApplicationResponse}
+// @Header 200 {string} ETag "申请版本号，更新和删除时通过 If-Match 回传"
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 404 {object} response.Response
@@ -342,7 +428,8 @@ func (h *ApplicationHandler) GetApplication(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, application.ToResponse())
+	c.Header("ETag", application.ETag())
+	response.Success(c, h.blindReview.Mask(application.ToResponse()))
 }
 
 // UpdateApplication 更新面试申请状态（管理员接口）
@@ -353,11 +440,13 @@ func (h *ApplicationHandler) GetApplication(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "申请ID"
+// @Param If-Match header string false "GetApplication 返回的 ETag"
 // @Param request body models.InterviewApplicationUpdateRequest true "更新信息"
 // @Success 200 {object} response.Response{data=models.InterviewApplicationResponse}
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response{data=models.InterviewApplicationResponse}
 // @Router /admin/applications/{id} [put]
 func (h *ApplicationHandler) UpdateApplication(c *gin.Context) {
 	idStr := c.Param("id")
@@ -377,6 +466,7 @@ func (h *ApplicationHandler) UpdateApplication(c *gin.Context) {
 	var req struct {
 		Status       string `json:"status" validate:"required,oneof=pending interviewed passed rejected"`
 		AdminRemarks string `json:"admin_remarks"`
+		Version      *uint  `json:"version"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -389,14 +479,124 @@ func (h *ApplicationHandler) UpdateApplication(c *gin.Context) {
 		return
 	}
 
-	application, err := h.interviewService.UpdateApplication(uint(id), req.Status, req.AdminRemarks)
+	var operatorID *uint
+	if userID, exists := middleware.GetCurrentUserID(c); exists {
+		operatorID = &userID
+	}
+
+	expectedVersion, ok := expectedVersion(c, req.Version)
+	if !ok {
+		return
+	}
+
+	application, err := h.interviewService.UpdateApplication(uint(id), req.Status, req.AdminRemarks, operatorID, expectedVersion)
 	if err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			h.respondVersionConflict(c, uint(id))
+			return
+		}
 		logger.Errorf("更新面试申请失败: %v", err)
 		response.BadRequest(c, err.Error())
 		return
 	}
 
-	response.SuccessWithMessage(c, "申请状态更新成功", application.ToResponse())
+	c.Header("ETag", application.ETag())
+	response.SuccessWithMessage(c, "申请状态更新成功", h.blindReview.Mask(application.ToResponse()))
+}
+
+// ScheduleInterview 安排面试（管理员接口）
+// @Summary 安排面试
+// @Description 设置面试时间、地点和面试官，并向申请人发送带日历邀请的确认邮件
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Param request body models.InterviewScheduleRequest true "面试安排"
+// @Success 200 {object} response.Response{data=models.InterviewApplicationResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /admin/applications/{id}/schedule [put]
+func (h *ApplicationHandler) ScheduleInterview(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的申请ID")
+		return
+	}
+
+	var req models.InterviewScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误，面试时间须为RFC3339格式")
+		return
+	}
+
+	// 验证请求参数
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	application, err := h.interviewService.ScheduleInterview(uint(id), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			h.respondVersionConflict(c, uint(id))
+			return
+		}
+		logger.Errorf("安排面试失败: %v", err)
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	// 发送带日历邀请的确认邮件
+	invite, err := h.calendarService.BuildInvite(application)
+	if err != nil {
+		logger.Errorf("生成面试日历失败: %v", err)
+	} else if err := sendInterviewConfirmationEmail(h.mailService, application, invite); err != nil {
+		logger.Errorf("发送面试确认邮件失败: %v", err)
+	}
+
+	response.SuccessWithMessage(c, "面试安排成功", h.blindReview.Mask(application.ToResponse()))
+}
+
+// DownloadInvite 下载面试日历邀请（管理员接口）
+// @Summary 下载面试日历邀请
+// @Description 下载指定面试的 .ics 日历文件
+// @Tags 管理
+// @Produce text/calendar
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Success 200 {file} file
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /admin/applications/{id}/invite.ics [get]
+func (h *ApplicationHandler) DownloadInvite(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的申请ID")
+		return
+	}
+
+	application, err := h.interviewService.GetApplicationByID(uint(id))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	// 日历邀请包含申请人姓名和邮箱
+	if err := h.blindReview.Reveal(c, application, "invite", ""); err != nil {
+		response.Forbidden(c, err.Error())
+		return
+	}
+
+	invite, err := h.calendarService.BuildInvite(application)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="interview-%d.ics"`, application.ID))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", invite)
 }
 
 // DeleteApplication 删除面试申请（管理员接口）
@@ -407,10 +607,12 @@ func (h *ApplicationHandler) UpdateApplication(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "申请ID"
+// @Param If-Match header string false "GetApplication 返回的 ETag"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response{data=models.InterviewApplicationResponse}
 // @Router /admin/applications/{id} [delete]
 func (h *ApplicationHandler) DeleteApplication(c *gin.Context) {
 	idStr := c.Param("id")
@@ -420,8 +622,27 @@ func (h *ApplicationHandler) DeleteApplication(c *gin.Context) {
 		return
 	}
 
-	err = h.interviewService.DeleteApplication(uint(id))
+	var req struct {
+		Version *uint `json:"version"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "请求参数错误")
+			return
+		}
+	}
+
+	expectedVersion, ok := expectedVersion(c, req.Version)
+	if !ok {
+		return
+	}
+
+	err = h.interviewService.DeleteApplication(uint(id), expectedVersion)
 	if err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			h.respondVersionConflict(c, uint(id))
+			return
+		}
 		logger.Errorf("删除面试申请失败: %v", err)
 		response.BadRequest(c, err.Error())
 		return
@@ -430,6 +651,111 @@ func (h *ApplicationHandler) DeleteApplication(c *gin.Context) {
 	response.SuccessWithMessage(c, "申请删除成功", nil)
 }
 
+// ListDeletedApplications 获取回收站中的面试申请（管理员接口）
+// @Summary 获取回收站列表
+// @Description 获取已删除（软删除）的面试申请列表，支持分页和姓名搜索
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param name query string false "姓名搜索"
+// @Success 200 {object} response.Response{data=models.InterviewApplicationListResponse}
+// @Failure 401 {object} response.Response
+// @Router /admin/applications/trash [get]
+func (h *ApplicationHandler) ListDeletedApplications(c *gin.Context) {
+	page, size := response.GetPaginationParams(c)
+
+	result, err := h.interviewService.ListDeletedApplications(page, size, c.Query("name"))
+	if err != nil {
+		logger.Errorf("获取回收站列表失败: %v", err)
+		response.InternalServerError(c, "获取回收站列表失败")
+		return
+	}
+
+	h.blindReview.MaskList(result.List)
+	response.Success(c, result)
+}
+
+// RestoreApplication 从回收站恢复面试申请（管理员接口）
+// @Summary 恢复已删除的面试申请
+// @Description 从回收站恢复面试申请，若同一邮箱已有有效申请则返回409
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Success 200 {object} response.Response{data=models.InterviewApplicationResponse}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /admin/applications/trash/{id}/restore [post]
+func (h *ApplicationHandler) RestoreApplication(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的申请ID")
+		return
+	}
+
+	application, err := h.interviewService.RestoreApplication(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrApplicationEmailConflict) {
+			response.Conflict(c, err.Error())
+			return
+		}
+		logger.Errorf("恢复面试申请失败: %v", err)
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "申请已恢复", application.ToResponse())
+}
+
+// PurgeApplication 永久删除回收站中的面试申请（超级管理员接口）
+// @Summary 永久删除面试申请
+// @Description 永久删除回收站中的面试申请及其附件，操作不可恢复
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /admin/applications/trash/{id} [delete]
+func (h *ApplicationHandler) PurgeApplication(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的申请ID")
+		return
+	}
+
+	if err := h.interviewService.PurgeApplication(uint(id)); err != nil {
+		logger.Errorf("永久删除面试申请失败: %v", err)
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	// 永久删除不可恢复，记录申请ID与操作人（不记录申请人信息）
+	applicationID := uint(id)
+	var operatorID *uint
+	if userID, exists := middleware.GetCurrentUserID(c); exists {
+		operatorID = &userID
+	}
+	h.auditService.Record(services.AuditEntry{
+		UserID:       operatorID,
+		Action:       "application.purge",
+		ResourceType: "interview_application",
+		ResourceID:   &applicationID,
+		Details:      models.JSONMap{"candidate_code": h.blindReview.CandidateCode(applicationID)},
+	}.WithRequest(c))
+
+	response.SuccessWithMessage(c, "申请已永久删除", nil)
+}
+
 // GetApplicationStats 获取面试申请统计（管理员接口）
 // @Summary 获取面试申请统计
 // @Description 获取各状态的申请数量统计
@@ -451,6 +777,40 @@ func (h *ApplicationHandler) GetApplicationStats(c *gin.Context) {
 	response.Success(c, stats)
 }
 
+// expectedVersion 从 If-Match 请求头或请求体中解析期望版本号，失败时已写入响应
+func expectedVersion(c *gin.Context, bodyVersion *uint) (*uint, bool) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return bodyVersion, true
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`)
+	version, err := strconv.ParseUint(tag, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "If-Match 格式错误")
+		return nil, false
+	}
+	if bodyVersion != nil && uint64(*bodyVersion) != version {
+		response.BadRequest(c, "If-Match 与请求体中的版本号不一致")
+		return nil, false
+	}
+
+	v := uint(version)
+	return &v, true
+}
+
+// respondVersionConflict 返回409及申请的当前记录
+func (h *ApplicationHandler) respondVersionConflict(c *gin.Context, id uint) {
+	current, err := h.interviewService.GetApplicationByID(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	c.Header("ETag", current.ETag())
+	response.ConflictWithData(c, services.ErrVersionConflict.Error(), h.blindReview.Mask(current.ToResponse()))
+}
+
 // sendApplicationSuccessEmail 发送申请成功邮件
 func sendApplicationSuccessEmail(email, name string) error {
 	host := "smtp.qq.com"
@@ -461,7 +821,7 @@ func sendApplicationSuccessEmail(email, name string) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", user)
 	m.SetHeader("To", email)
-	m.SetHeader("Subject", "EPI实验室面试申请已收到")
+	m.SetHeader("Subject", applicationSuccessEmailSubject)
 
 	htmlBody := fmt.Sprintf(`
 <!DOCTYPE html>
@@ -500,4 +860,48 @@ func sendApplicationSuccessEmail(email, name string) error {
 
 	d := gomail.NewDialer(host, port, user, password)
 	return d.DialAndSend(m)
-} 
\ No newline at end of file
+}
+
+// sendInterviewConfirmationEmail 发送面试确认邮件（附带日历邀请）
+func sendInterviewConfirmationEmail(mailService *services.MailService, application *models.InterviewApplication, invite []byte) error {
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>面试安排</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 20px; text-align: center; border-radius: 8px 8px 0 0; }
+        .content { background: #f9f9f9; padding: 20px; border-radius: 0 0 8px 8px; }
+        .schedule { background: #1890ff; color: white; padding: 15px; border-radius: 4px; margin: 20px 0; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>🧪 EPI实验室</h1>
+        </div>
+        <div class="content">
+            <p>亲爱的 %s：</p>
+            <p>您的面试已安排，具体信息如下：</p>
+            <div class="schedule">
+                <p>📅 时间：%s（约%d分钟）</p>
+                <p>📍 地点：%s</p>
+            </div>
+            <p>邮件附件中包含日历邀请，打开即可添加到手机或电脑日历。</p>
+            <p>如需改期，请直接回复本邮件与我们联系。</p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(application.Name), application.InterviewAt.Local().Format("2006-01-02 15:04"),
+		application.DurationMinutes, html.EscapeString(application.Location))
+
+	return mailService.Send(application.Email, "EPI实验室面试安排", htmlBody, services.MailAttachment{
+		Filename:    "interview.ics",
+		ContentType: "text/calendar; charset=utf-8; method=REQUEST",
+		Content:     invite,
+	})
+}
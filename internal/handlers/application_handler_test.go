@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lab-recruitment-platform/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestExpectedVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	uintPtr := func(v uint) *uint { return &v }
+
+	tests := []struct {
+		name        string
+		ifMatch     string
+		bodyVersion *uint
+		want        *uint
+		wantOK      bool
+	}{
+		{"未提供版本号", "", nil, nil, true},
+		{"仅请求体版本号", "", uintPtr(2), uintPtr(2), true},
+		{"If-Match为*", "*", uintPtr(2), uintPtr(2), true},
+		{"带引号的ETag", `"3"`, nil, uintPtr(3), true},
+		{"弱ETag", `W/"3"`, nil, uintPtr(3), true},
+		{"不带引号", "3", nil, uintPtr(3), true},
+		{"与请求体一致", `"3"`, uintPtr(3), uintPtr(3), true},
+		{"与请求体不一致", `"3"`, uintPtr(2), nil, false},
+		{"非数字", `"abc"`, nil, nil, false},
+		{"负数", `"-1"`, nil, nil, false},
+		{"超出范围", `"4294967296"`, nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/applications/1", nil)
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			got, ok := expectedVersion(c, tt.bodyVersion)
+			if ok != tt.wantOK {
+				t.Fatalf("expectedVersion() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				if w.Code != http.StatusBadRequest {
+					t.Errorf("状态码 = %d, want %d", w.Code, http.StatusBadRequest)
+				}
+				return
+			}
+			if c.Writer.Written() {
+				t.Error("成功时不应写入响应")
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("expectedVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpectedVersionAcceptsETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// 客户端原样回传响应中的 ETag 时应能解析出同一版本号
+	application := &models.InterviewApplication{Version: 7}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/applications/1", nil)
+	c.Request.Header.Set("If-Match", application.ETag())
+
+	got, ok := expectedVersion(c, nil)
+	if !ok || got == nil || *got != application.Version {
+		t.Errorf("expectedVersion(%s) = (%v, %v), want %d", application.ETag(), got, ok, application.Version)
+	}
+}
+
+func TestVerificationCodeStore(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored string
+		ttl    time.Duration
+		op     func(s *verificationCodeStore, email, code string) bool
+		code   string
+		want   bool
+		remain bool
+	}{
+		{"校验正确的验证码后保留", "123456", time.Minute, (*verificationCodeStore).check, "123456", true, true},
+		{"校验错误的验证码后作废", "123456", time.Minute, (*verificationCodeStore).check, "654321", false, false},
+		{"校验过期的验证码", "123456", -time.Second, (*verificationCodeStore).check, "123456", false, false},
+		{"消费正确的验证码后删除", "123456", time.Minute, (*verificationCodeStore).consume, "123456", true, false},
+		{"消费错误的验证码后作废", "123456", time.Minute, (*verificationCodeStore).consume, "654321", false, false},
+		{"消费过期的验证码", "123456", -time.Second, (*verificationCodeStore).consume, "123456", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &verificationCodeStore{codes: make(map[string]*VerificationCode)}
+			s.set("alice@example.com", tt.stored, tt.ttl)
+
+			if got := tt.op(s, "alice@example.com", tt.code); got != tt.want {
+				t.Errorf("结果 = %v, want %v", got, tt.want)
+			}
+			if _, remain := s.codes["alice@example.com"]; remain != tt.remain {
+				t.Errorf("验证码保留 = %v, want %v", remain, tt.remain)
+			}
+			if s.consume("bob@example.com", tt.stored) {
+				t.Error("其他邮箱不应通过校验")
+			}
+		})
+	}
+}
+
+func TestVerificationCodeStoreConcurrent(t *testing.T) {
+	s := &verificationCodeStore{codes: make(map[string]*VerificationCode)}
+	s.set("alice@example.com", "123456", time.Minute)
+
+	// 同一验证码被并发提交时只能成功消费一次
+	var wg sync.WaitGroup
+	var consumed atomic.Int32
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			email := fmt.Sprintf("user%d@example.com", i%5)
+			s.set(email, "000000", time.Minute)
+			s.check(email, "000000")
+			if s.consume("alice@example.com", "123456") {
+				consumed.Add(1)
+			}
+			s.remove(email)
+		}(i)
+	}
+	wg.Wait()
+
+	if got := consumed.Load(); got != 1 {
+		t.Errorf("验证码被消费 %d 次, want 1", got)
+	}
+}
@@ -1,7 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/config"
 	"lab-recruitment-platform/internal/middleware"
 	"lab-recruitment-platform/internal/models"
 	"lab-recruitment-platform/internal/services"
@@ -12,21 +20,154 @@ import (
 
 // AuthHandler 认证处理器
 type AuthHandler struct {
-	userService *services.UserService
+	userService         *services.UserService
+	registrationService *services.RegistrationService
+	passwordReset       *services.PasswordResetService
+	passwordPolicy      *services.PasswordPolicyService
+	tokenService        *services.TokenService
+	loginGuard          *services.LoginGuardService
+	twoFactor           *services.TwoFactorService
+	webauthn            *services.WebAuthnService
+	auditService        *services.AuditService
+	mailService         *services.MailService
 }
 
 // NewAuthHandler 创建认证处理器实例
 func NewAuthHandler() *AuthHandler {
 	return &AuthHandler{
-		userService: services.NewUserService(),
+		userService:         services.NewUserService(),
+		registrationService: services.NewRegistrationService(),
+		passwordReset:       services.NewPasswordResetService(),
+		passwordPolicy:      services.NewPasswordPolicyService(),
+		tokenService:        services.NewTokenService(),
+		loginGuard:          services.NewLoginGuardService(),
+		twoFactor:           services.NewTwoFactorService(),
+		webauthn:            services.NewWebAuthnService(),
+		auditService:        services.NewAuditService(),
+		mailService:         services.NewMailService(),
+	}
+}
+
+// Register 学生注册
+// @Summary 学生注册
+// @Description 使用用户名、邮箱和密码注册学生账户，需通过邮件中的链接验证邮箱后才能登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body models.UserRegisterRequest true "注册信息"
+// @Success 200 {object} response.Response{data=models.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /auth/register [post]
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req models.UserRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	// 验证请求参数
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	user, token, err := h.registrationService.Register(&req)
+	if err != nil {
+		if errors.Is(err, services.ErrRegistrationDisabled) {
+			response.Forbidden(c, err.Error())
+			return
+		}
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.sendVerificationEmail(user, token); err != nil {
+		logger.Errorf("发送邮箱验证邮件失败: %v", err)
+	}
+
+	response.SuccessWithMessage(c, "注册成功，请查收验证邮件完成邮箱验证", user.ToResponse())
+}
+
+// VerifyEmail 验证邮箱
+// @Summary 验证邮箱
+// @Description 通过验证邮件中的链接激活账户，支持 GET 查询参数或 POST 请求体传递令牌
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param token query string false "验证令牌"
+// @Param request body models.VerifyEmailRequest false "验证令牌"
+// @Success 200 {object} response.Response{data=models.UserResponse}
+// @Failure 400 {object} response.Response
+// @Router /auth/verify-email [get]
+// @Router /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	req := models.VerifyEmailRequest{Token: c.Query("token")}
+	if req.Token == "" && c.Request.Method == "POST" {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "请求参数错误")
+			return
+		}
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
 	}
+
+	user, err := h.registrationService.VerifyEmail(req.Token)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "邮箱验证成功，账户已激活", user.ToResponse())
 }
 
+// ResendVerification 重新发送验证邮件
+// @Summary 重新发送验证邮件
+// @Description 为尚未验证邮箱的注册账户重新发送验证邮件，之前的验证链接随即失效
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body models.ResendVerificationRequest true "注册邮箱"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /auth/resend-verification [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req models.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	user, token, err := h.registrationService.ResendVerification(req.Email)
+	if err != nil {
+		if errors.Is(err, services.ErrResendTooFrequent) {
+			response.TooManyRequests(c, err.Error())
+			return
+		}
+		logger.Errorf("重新发送验证邮件失败: %v", err)
+		response.InternalServerError(c, "重新发送验证邮件失败")
+		return
+	}
+
+	// 账户不存在或已验证时同样返回成功，避免泄露账户状态
+	if user != nil {
+		if err := h.sendVerificationEmail(user, token); err != nil {
+			logger.Errorf("发送邮箱验证邮件失败: %v", err)
+			response.InternalServerError(c, "邮件发送失败，请稍后重试")
+			return
+		}
+	}
 
+	response.SuccessWithMessage(c, "如该邮箱存在待验证的账户，验证邮件已重新发送", nil)
+}
 
 // Login 用户登录
 // @Summary 用户登录
-// @Description 用户登录接口
+// @Description 用户登录接口；已启用两步验证时返回预认证令牌，需调用 /auth/2fa/verify 或 /auth/webauthn/login 完成登录
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -47,10 +188,18 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// 检查账户及来源IP是否被限制登录
+	if wait, err := h.loginGuard.Check(req.Email, c.ClientIP()); err != nil {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		response.TooManyRequests(c, err.Error())
+		return
+	}
+
 	// 根据邮箱获取用户
 	user, err := h.userService.GetUserByEmail(req.Email)
 	if err != nil {
 		logger.Warnf("用户登录失败，邮箱不存在: %s", req.Email)
+		h.recordLoginFailure(c, req.Email, nil)
 		response.Unauthorized(c, "邮箱或密码错误")
 		return
 	}
@@ -58,18 +207,92 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// 验证密码
 	if !h.userService.VerifyPassword(user, req.Password) {
 		logger.Warnf("用户登录失败，密码错误: %s", req.Email)
+		h.recordLoginFailure(c, req.Email, user)
 		response.Unauthorized(c, "邮箱或密码错误")
 		return
 	}
+	h.loginGuard.RecordSuccess(req.Email)
 
 	// 检查用户状态
+	if user.IsPendingVerification() {
+		response.Forbidden(c, "邮箱尚未验证，请先完成邮箱验证")
+		return
+	}
 	if !user.IsActive() {
 		response.Forbidden(c, "账户已被禁用")
 		return
 	}
 
-	// 生成JWT令牌
-	token, err := middleware.GenerateToken(user)
+	// 已启用或被要求启用两步验证时，仅签发预认证令牌
+	if h.requireSecondFactor(c, user) {
+		return
+	}
+
+	completeLogin(c, h.tokenService, user, nil)
+}
+
+// requireSecondFactor 判断登录是否需要第二步验证，需要时已写入响应
+func (h *AuthHandler) requireSecondFactor(c *gin.Context, user *models.User) bool {
+	totpEnabled, err := h.twoFactor.IsEnabled(user.ID)
+	if err != nil {
+		logger.Errorf("查询两步验证状态失败: %v", err)
+		response.InternalServerError(c, "登录失败，请稍后重试")
+		return true
+	}
+	hasPasskey, err := h.webauthn.HasCredentials(user)
+	if err != nil {
+		logger.Errorf("查询通行密钥失败: %v", err)
+		response.InternalServerError(c, "登录失败，请稍后重试")
+		return true
+	}
+
+	methods := make([]string, 0, 2)
+	if totpEnabled {
+		methods = append(methods, "totp")
+	}
+	if hasPasskey {
+		methods = append(methods, "webauthn")
+	}
+
+	purpose := services.MFAPurposeVerify
+	message := "请完成两步验证"
+	if len(methods) == 0 {
+		if !h.twoFactor.IsRequired(user) {
+			return false
+		}
+		purpose = services.MFAPurposeEnroll
+		message = "管理员账户必须启用两步验证，请先完成绑定"
+	}
+
+	mfaToken, err := h.twoFactor.CreatePreAuthToken(user.ID, purpose)
+	if err != nil {
+		logger.Errorf("签发预认证令牌失败: %v", err)
+		response.InternalServerError(c, "登录失败，请稍后重试")
+		return true
+	}
+
+	response.SuccessWithMessage(c, message, gin.H{
+		"mfa_required":            purpose == services.MFAPurposeVerify,
+		"mfa_enrollment_required": purpose == services.MFAPurposeEnroll,
+		"mfa_methods":             methods,
+		"mfa_token":               mfaToken,
+		"expires_in":              int(h.twoFactor.PreAuthTTL().Seconds()),
+	})
+	return true
+}
+
+// completeLogin 创建会话并返回访问令牌与刷新令牌，extra 中的字段一并返回
+func completeLogin(c *gin.Context, tokenService *services.TokenService, user *models.User, extra gin.H) {
+	sessionID, refreshToken, err := tokenService.CreateSession(user.ID, models.SessionMeta{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	})
+	if err != nil {
+		logger.Errorf("创建会话失败: %v", err)
+		response.InternalServerError(c, "登录失败，请稍后重试")
+		return
+	}
+	tokens, err := issueTokens(user, sessionID, refreshToken)
 	if err != nil {
 		logger.Errorf("生成令牌失败: %v", err)
 		response.InternalServerError(c, "生成令牌失败")
@@ -77,10 +300,11 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// 返回用户信息和令牌
-	response.SuccessWithMessage(c, "登录成功", gin.H{
-		"user":  user.ToResponse(),
-		"token": token,
-	})
+	for k, v := range extra {
+		tokens[k] = v
+	}
+	tokens["user"] = user.ToResponse()
+	response.SuccessWithMessage(c, "登录成功", tokens)
 }
 
 // GetProfile 获取用户信息
@@ -187,49 +411,167 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	response.SuccessWithMessage(c, "密码修改成功", nil)
+	// 修改密码后撤销全部会话，需重新登录
+	if err := h.tokenService.RevokeAllSessions(userID); err != nil {
+		logger.Errorf("撤销用户会话失败: %v", err)
+	}
+
+	response.SuccessWithMessage(c, "密码修改成功，请重新登录", nil)
+}
+
+// ForgotPassword 找回密码
+// @Summary 找回密码
+// @Description 向账户邮箱发送一次性重置密码链接；无论邮箱是否存在均返回相同结果
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body models.ForgotPasswordRequest true "邮箱"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	user, token, err := h.passwordReset.RequestReset(req.Email, c.ClientIP())
+	if err != nil {
+		logger.Errorf("生成重置密码令牌失败: %v", err)
+	}
+	if user != nil {
+		// 异步发送，使邮箱已注册与未注册时的响应耗时一致；发送失败同样返回成功，避免暴露邮箱是否已注册
+		ip := c.ClientIP()
+		go func() {
+			if err := h.sendPasswordResetEmail(user, token, ip); err != nil {
+				logger.Errorf("发送重置密码邮件失败: %v", err)
+			}
+		}()
+	}
+
+	response.SuccessWithMessage(c, "如该邮箱已注册，重置密码链接已发送，请查收邮件", nil)
+}
+
+// ResetPassword 通过邮件令牌重置密码
+// @Summary 重置密码
+// @Description 使用重置邮件中的令牌设置新密码，令牌仅可使用一次；成功后撤销该用户的全部会话并发送通知邮件
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "令牌与新密码"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	user, err := h.passwordReset.ResetPassword(req.Token, req.NewPassword)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) || errors.Is(err, services.ErrPasswordPolicy) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	// 撤销全部会话，并解除因密码错误导致的临时锁定
+	if err := h.tokenService.RevokeAllSessions(user.ID); err != nil {
+		logger.Errorf("撤销用户会话失败: %v", err)
+	}
+	if _, err := h.loginGuard.Unlock(user.Email); err != nil {
+		logger.Warnf("解除登录锁定失败: %v", err)
+	}
+
+	h.auditService.Record(services.AuditEntry{
+		UserID:       &user.ID,
+		Action:       "auth.password_reset",
+		ResourceType: "user",
+		ResourceID:   &user.ID,
+	}.WithRequest(c))
+
+	if err := h.sendPasswordChangedEmail(user, c.ClientIP()); err != nil {
+		logger.Errorf("发送密码变更通知邮件失败: %v", err)
+	}
+
+	response.SuccessWithMessage(c, "密码已重置，请使用新密码登录", nil)
+}
+
+// GetPasswordPolicy 获取密码策略
+// @Summary 获取密码策略
+// @Description 返回当前密码策略及文字说明，供注册、修改与重置密码页面提示用户
+// @Tags 认证
+// @Produce json
+// @Success 200 {object} response.Response{data=models.PasswordPolicyResponse}
+// @Router /auth/password-policy [get]
+func (h *AuthHandler) GetPasswordPolicy(c *gin.Context) {
+	response.Success(c, h.passwordPolicy.Describe())
 }
 
 // RefreshToken 刷新令牌
 // @Summary 刷新令牌
-// @Description 刷新JWT令牌
+// @Description 使用刷新令牌换取新的访问令牌与刷新令牌，旧刷新令牌随即失效；重复使用已轮换的刷新令牌将撤销整个会话
 // @Tags 认证
 // @Accept json
 // @Produce json
-// @Security BearerAuth
+// @Param request body RefreshTokenRequest true "刷新令牌"
 // @Success 200 {object} response.Response{data=gin.H}
 // @Failure 401 {object} response.Response
 // @Router /auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
-	userID, exists := middleware.GetCurrentUserID(c)
-	if !exists {
-		response.Unauthorized(c, "用户未登录")
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
 		return
 	}
 
-	user, err := h.userService.GetUserByID(userID)
+	userID, sessionID, refreshToken, err := h.tokenService.Rotate(req.RefreshToken)
 	if err != nil {
-		logger.Errorf("获取用户信息失败: %v", err)
-		response.NotFound(c, "用户不存在")
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+			response.Unauthorized(c, err.Error())
+			return
+		}
+		logger.Errorf("刷新令牌失败: %v", err)
+		response.InternalServerError(c, "刷新令牌失败")
+		return
+	}
+
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil || !user.IsActive() {
+		h.tokenService.RevokeSession(userID, sessionID, models.SessionEndDisabled)
+		response.Unauthorized(c, "账户不可用，请重新登录")
 		return
 	}
 
 	// 生成新的JWT令牌
-	token, err := middleware.GenerateToken(user)
+	tokens, err := issueTokens(user, sessionID, refreshToken)
 	if err != nil {
 		logger.Errorf("生成令牌失败: %v", err)
 		response.InternalServerError(c, "生成令牌失败")
 		return
 	}
+	h.tokenService.TouchSession(sessionID, c.ClientIP())
 
-	response.SuccessWithMessage(c, "令牌刷新成功", gin.H{
-		"token": token,
-	})
+	response.SuccessWithMessage(c, "令牌刷新成功", tokens)
 }
 
 // Logout 用户登出
 // @Summary 用户登出
-// @Description 用户登出接口
+// @Description 注销当前访问令牌并撤销所属会话的刷新令牌
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -237,13 +579,220 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 // @Success 200 {object} response.Response
 // @Router /auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
-	// 在实际应用中，可以将令牌加入黑名单
-	// 这里简单返回成功响应
+	claims, exists := middleware.GetCurrentClaims(c)
+	if !exists {
+		response.Unauthorized(c, "用户未登录")
+		return
+	}
+
+	if err := h.tokenService.DenyAccessToken(claims.ID, claims.ExpiresAt.Time); err != nil {
+		logger.Errorf("注销访问令牌失败: %v", err)
+		response.InternalServerError(c, "登出失败，请稍后重试")
+		return
+	}
+	if err := h.tokenService.RevokeSession(claims.UserID, claims.SessionID, models.SessionEndLogout); err != nil {
+		logger.Errorf("撤销会话失败: %v", err)
+	}
+
 	response.SuccessWithMessage(c, "登出成功", nil)
 }
 
+// recordLoginFailure 记录登录失败，账户因此被锁定时写入审计日志并通知账户所有者
+func (h *AuthHandler) recordLoginFailure(c *gin.Context, email string, user *models.User) {
+	locked, err := h.loginGuard.RecordFailure(email, c.ClientIP())
+	if err != nil {
+		logger.Errorf("记录登录失败次数失败: %v", err)
+		return
+	}
+	if !locked {
+		return
+	}
+
+	entry := services.AuditEntry{
+		Action:       "auth.lockout",
+		ResourceType: "user",
+		Details: models.JSONMap{
+			"email":    email,
+			"duration": h.loginGuard.LockoutDuration().String(),
+		},
+	}
+	if user != nil {
+		entry.UserID = &user.ID
+		entry.ResourceID = &user.ID
+	}
+	h.auditService.Record(entry.WithRequest(c))
+
+	if user != nil {
+		if err := sendLockoutEmail(h.mailService, user, c.ClientIP(), h.loginGuard.LockoutDuration()); err != nil {
+			logger.Errorf("发送账户锁定通知邮件失败: %v", err)
+		}
+	}
+}
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// issueTokens 签发访问令牌并组装令牌响应
+func issueTokens(user *models.User, sessionID, refreshToken string) (gin.H, error) {
+	token, err := middleware.GenerateToken(user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{
+		"token":         token,
+		"refresh_token": refreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(config.GlobalConfig.JWT.ExpireTime.Seconds()),
+	}, nil
+}
+
 // ChangePasswordRequest 修改密码请求
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" validate:"required"`
-	NewPassword string `json:"new_password" validate:"required,min=6"`
-} 
\ No newline at end of file
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+// sendVerificationEmail 发送邮箱验证邮件
+func (h *AuthHandler) sendVerificationEmail(user *models.User, token string) error {
+	link := h.registrationService.VerifyURL(token)
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>邮箱验证</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 20px; text-align: center; border-radius: 8px 8px 0 0; }
+        .content { background: #f9f9f9; padding: 20px; border-radius: 0 0 8px 8px; }
+        .button { display: inline-block; background: #1890ff; color: white; padding: 10px 20px; border-radius: 4px; text-decoration: none; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>🧪 EPI实验室</h1>
+        </div>
+        <div class="content">
+            <p>%s 您好：</p>
+            <p>感谢注册EPI实验室招新平台，请点击下方按钮验证邮箱并激活账户：</p>
+            <p><a class="button" href="%s">验证邮箱</a></p>
+            <p>如按钮无法点击，请复制以下链接到浏览器打开：<br>%s</p>
+            <p><strong>链接有效期：%s</strong></p>
+            <p>如果这不是您的操作，请忽略此邮件。</p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(user.Username), link, link, h.registrationService.VerifyTTL())
+
+	return h.mailService.Send(user.Email, "EPI实验室邮箱验证", htmlBody)
+}
+
+// sendPasswordResetEmail 发送重置密码邮件
+func (h *AuthHandler) sendPasswordResetEmail(user *models.User, token, ip string) error {
+	link := h.passwordReset.ResetURL(token)
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>重置密码</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 20px; text-align: center; border-radius: 8px 8px 0 0; }
+        .content { background: #f9f9f9; padding: 20px; border-radius: 0 0 8px 8px; }
+        .button { display: inline-block; background: #1890ff; color: white; padding: 10px 20px; border-radius: 4px; text-decoration: none; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>🧪 EPI实验室</h1>
+        </div>
+        <div class="content">
+            <p>%s 您好：</p>
+            <p>我们收到了重置您账户密码的申请（来自 IP：%s），请点击下方按钮设置新密码：</p>
+            <p><a class="button" href="%s">重置密码</a></p>
+            <p>如按钮无法点击，请复制以下链接到浏览器打开：<br>%s</p>
+            <p><strong>链接有效期：%s，且仅可使用一次</strong></p>
+            <p>如果这不是您的操作，请忽略此邮件，您的密码不会被修改。</p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(user.Username), ip, link, link, h.passwordReset.TokenTTL())
+
+	return h.mailService.Send(user.Email, "EPI实验室重置密码", htmlBody)
+}
+
+// sendPasswordChangedEmail 发送密码已重置通知邮件
+func (h *AuthHandler) sendPasswordChangedEmail(user *models.User, ip string) error {
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>密码已重置</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 20px; text-align: center; border-radius: 8px 8px 0 0; }
+        .content { background: #f9f9f9; padding: 20px; border-radius: 0 0 8px 8px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>🧪 EPI实验室</h1>
+        </div>
+        <div class="content">
+            <p>%s 您好：</p>
+            <p>您的账户密码已于 %s 通过找回密码流程重置，操作来自 IP：%s。所有已登录的设备均已退出。</p>
+            <p>如果这不是您本人操作，请立即联系管理员。</p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(user.Username), time.Now().Format("2006-01-02 15:04:05"), ip)
+
+	return h.mailService.Send(user.Email, "EPI实验室账户安全提醒", htmlBody)
+}
+
+// sendLockoutEmail 发送账户临时锁定通知邮件
+func sendLockoutEmail(mailService *services.MailService, user *models.User, ip string, duration time.Duration) error {
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>账户临时锁定</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 20px; text-align: center; border-radius: 8px 8px 0 0; }
+        .content { background: #f9f9f9; padding: 20px; border-radius: 0 0 8px 8px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>🧪 EPI实验室</h1>
+        </div>
+        <div class="content">
+            <p>%s 您好：</p>
+            <p>您的账户在 %s 因多次密码错误被临时锁定，最近一次尝试来自 IP：%s。</p>
+            <p><strong>锁定时长：%s</strong>，到期后可再次登录，也可联系管理员提前解锁。</p>
+            <p>如果这些尝试不是您本人操作，建议在解锁后立即修改密码。</p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(user.Username), time.Now().Format("2006-01-02 15:04:05"), ip, duration)
+
+	return mailService.Send(user.Email, "EPI实验室账户安全提醒", htmlBody)
+}
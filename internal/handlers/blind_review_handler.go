@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/response"
+	"lab-recruitment-platform/pkg/validator"
+)
+
+// BlindReviewHandler 盲审处理器
+type BlindReviewHandler struct {
+	interviewService *services.InterviewApplicationService
+	blindReview      *services.BlindReviewService
+}
+
+// NewBlindReviewHandler 创建盲审处理器实例
+func NewBlindReviewHandler() *BlindReviewHandler {
+	return &BlindReviewHandler{
+		interviewService: services.NewInterviewApplicationService(),
+		blindReview:      services.NewBlindReviewService(),
+	}
+}
+
+// RevealRequest 查看申请人身份请求
+type RevealRequest struct {
+	Reason string `json:"reason" validate:"max=200"`
+}
+
+// RevealApplication 查看盲审申请的申请人身份（管理员接口）
+// @Summary 查看申请人身份
+// @Description 持有身份查看权限的管理员查看盲审阶段申请的完整信息，每次查看都会写入审计日志
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Param request body RevealRequest false "查看原因"
+// @Success 200 {object} response.Response{data=models.InterviewApplicationResponse}
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /admin/applications/{id}/reveal [post]
+func (h *BlindReviewHandler) RevealApplication(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的申请ID")
+		return
+	}
+
+	var req RevealRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "请求参数错误")
+			return
+		}
+		if !validator.ValidateRequest(c, &req) {
+			return
+		}
+	}
+
+	application, err := h.interviewService.GetApplicationByID(uint(id))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	if err := h.blindReview.Reveal(c, application, "reveal", req.Reason); err != nil {
+		if errors.Is(err, services.ErrRevealForbidden) {
+			response.Forbidden(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	resp := application.ToResponse()
+	resp.CandidateCode = h.blindReview.CandidateCode(application.ID)
+	response.Success(c, resp)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+)
+
+// CalendarHandler 面试日历处理器
+type CalendarHandler struct {
+	calendarService *services.CalendarService
+}
+
+// NewCalendarHandler 创建面试日历处理器实例
+func NewCalendarHandler() *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: services.NewCalendarService(),
+	}
+}
+
+// GetFeedURL 获取面试日历订阅地址
+// @Summary 获取面试日历订阅地址
+// @Description 获取当前面试官的私有日历订阅地址，可在手机日历中订阅
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=gin.H}
+// @Failure 401 {object} response.Response
+// @Router /auth/calendar-feed [get]
+func (h *CalendarHandler) GetFeedURL(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未登录")
+		return
+	}
+
+	feedURL, err := h.calendarService.GetFeedURL(userID)
+	if err != nil {
+		logger.Errorf("获取日历订阅地址失败: %v", err)
+		response.InternalServerError(c, "获取日历订阅地址失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"feed_url": feedURL,
+	})
+}
+
+// ResetFeedURL 重置面试日历订阅地址
+// @Summary 重置面试日历订阅地址
+// @Description 重新生成订阅令牌，旧地址立即失效
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=gin.H}
+// @Failure 401 {object} response.Response
+// @Router /auth/calendar-feed/reset [post]
+func (h *CalendarHandler) ResetFeedURL(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未登录")
+		return
+	}
+
+	feedURL, err := h.calendarService.ResetFeedToken(userID)
+	if err != nil {
+		logger.Errorf("重置日历订阅地址失败: %v", err)
+		response.InternalServerError(c, "重置日历订阅地址失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "订阅地址已重置", gin.H{
+		"feed_url": feedURL,
+	})
+}
+
+// InterviewerFeed 面试官日历订阅源
+// @Summary 面试官日历订阅源
+// @Description 通过私有令牌获取面试官即将进行的面试（iCalendar格式）
+// @Tags 日历
+// @Produce text/calendar
+// @Param token path string true "订阅令牌"
+// @Success 200 {file} file
+// @Failure 404 {object} response.Response
+// @Router /calendar/{token}/interviews.ics [get]
+func (h *CalendarHandler) InterviewerFeed(c *gin.Context) {
+	user, err := h.calendarService.GetUserByFeedToken(c.Param("token"))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	feed, err := h.calendarService.BuildInterviewerFeed(user)
+	if err != nil {
+		logger.Errorf("生成面试日历失败: %v", err)
+		response.InternalServerError(c, "生成面试日历失败")
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", feed)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/middleware"
+)
+
+// JWKSHandler JWT公钥集合处理器
+type JWKSHandler struct{}
+
+// NewJWKSHandler 创建JWT公钥集合处理器实例
+func NewJWKSHandler() *JWKSHandler {
+	return &JWKSHandler{}
+}
+
+// GetJWKS 获取JWT验证公钥集合
+// @Summary JWT公钥集合
+// @Description 返回当前签发密钥及轮换宽限期内旧密钥的公钥（RFC 7517），响应不使用统一响应格式；使用HS256时为空集合
+// @Tags 认证
+// @Produce json
+// @Success 200 {object} jwks.Set
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, middleware.JWKS())
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+	"lab-recruitment-platform/pkg/validator"
+)
+
+// OfferHandler 录取通知处理器
+type OfferHandler struct {
+	offerService *services.OfferService
+	blindReview  *services.BlindReviewService
+}
+
+// NewOfferHandler 创建录取通知处理器实例
+func NewOfferHandler() *OfferHandler {
+	return &OfferHandler{
+		offerService: services.NewOfferService(),
+		blindReview:  services.NewBlindReviewService(),
+	}
+}
+
+// CreateOffer 发放录取通知（管理员接口）
+// @Summary 发放录取通知
+// @Description 向已通过的申请人发放带答复期限的录取通知，受实验室最大成员数限制
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Param request body models.OfferCreateRequest false "答复期限"
+// @Success 200 {object} response.Response{data=models.Offer}
+// @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /admin/applications/{id}/offer [post]
+func (h *OfferHandler) CreateOffer(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的申请ID")
+		return
+	}
+
+	var req models.OfferCreateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "请求参数错误")
+			return
+		}
+		if !validator.ValidateRequest(c, &req) {
+			return
+		}
+	}
+
+	var operatorID *uint
+	if userID, exists := middleware.GetCurrentUserID(c); exists {
+		operatorID = &userID
+	}
+
+	offer, err := h.offerService.CreateOffer(uint(id), req.ResponseDays, operatorID)
+	if err != nil {
+		if errors.Is(err, services.ErrOfferCapacityFull) {
+			response.Conflict(c, err.Error())
+			return
+		}
+		logger.Errorf("发放录取通知失败: %v", err)
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "录取通知已发放", offer)
+}
+
+// ListOffers 获取录取通知列表（管理员接口）
+// @Summary 获取录取通知列表
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param status query string false "状态过滤" Enums(pending,accepted,declined,expired)
+// @Success 200 {object} response.Response{data=models.OfferListResponse}
+// @Failure 401 {object} response.Response
+// @Router /admin/offers [get]
+func (h *OfferHandler) ListOffers(c *gin.Context) {
+	page, size := response.GetPaginationParams(c)
+
+	result, err := h.offerService.ListOffers(page, size, c.Query("status"))
+	if err != nil {
+		logger.Errorf("获取录取通知列表失败: %v", err)
+		response.InternalServerError(c, "获取录取通知列表失败")
+		return
+	}
+
+	for i := range result.List {
+		if result.List[i].Application != nil {
+			h.blindReview.Mask(result.List[i].Application)
+		}
+	}
+	response.Success(c, result)
+}
+
+// GetCapacity 获取录取名额使用情况（管理员接口）
+// @Summary 获取录取名额
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=models.OfferCapacity}
+// @Failure 401 {object} response.Response
+// @Router /admin/offers/capacity [get]
+func (h *OfferHandler) GetCapacity(c *gin.Context) {
+	capacity, err := h.offerService.GetCapacity()
+	if err != nil {
+		logger.Errorf("获取录取名额失败: %v", err)
+		response.InternalServerError(c, "获取录取名额失败")
+		return
+	}
+
+	response.Success(c, capacity)
+}
+
+// ListWaitlist 获取候补名单（管理员接口）
+// @Summary 获取候补名单
+// @Description 按排名返回已面试且在候补名单中的申请
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.InterviewApplicationResponse}
+// @Failure 401 {object} response.Response
+// @Router /admin/waitlist [get]
+func (h *OfferHandler) ListWaitlist(c *gin.Context) {
+	list, err := h.offerService.ListWaitlist()
+	if err != nil {
+		logger.Errorf("获取候补名单失败: %v", err)
+		response.InternalServerError(c, "获取候补名单失败")
+		return
+	}
+
+	h.blindReview.MaskList(list)
+	response.Success(c, list)
+}
+
+// SetWaitlistRank 设置候补排名（管理员接口）
+// @Summary 加入候补名单
+// @Description 将已面试的申请加入候补名单或调整排名，排名数字越小越优先
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Param request body models.WaitlistRequest true "候补排名"
+// @Success 200 {object} response.Response{data=models.InterviewApplicationResponse}
+// @Failure 400 {object} response.Response
+// @Router /admin/applications/{id}/waitlist [put]
+func (h *OfferHandler) SetWaitlistRank(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的申请ID")
+		return
+	}
+
+	var req models.WaitlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	application, err := h.offerService.SetWaitlistRank(uint(id), &req.Rank)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "候补排名已更新", h.blindReview.Mask(application.ToResponse()))
+}
+
+// RemoveFromWaitlist 移出候补名单（管理员接口）
+// @Summary 移出候补名单
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Success 200 {object} response.Response{data=models.InterviewApplicationResponse}
+// @Failure 400 {object} response.Response
+// @Router /admin/applications/{id}/waitlist [delete]
+func (h *OfferHandler) RemoveFromWaitlist(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的申请ID")
+		return
+	}
+
+	application, err := h.offerService.SetWaitlistRank(uint(id), nil)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "已移出候补名单", h.blindReview.Mask(application.ToResponse()))
+}
+
+// GetOffer 查看录取通知
+// @Summary 查看录取通知
+// @Description 申请人通过邮件中的链接查看录取通知及答复期限
+// @Tags 录取
+// @Produce json
+// @Param token path string true "答复令牌"
+// @Success 200 {object} response.Response{data=models.OfferPublicResponse}
+// @Failure 404 {object} response.Response
+// @Router /offers/{token} [get]
+func (h *OfferHandler) GetOffer(c *gin.Context) {
+	offer, err := h.offerService.GetOfferByToken(c.Param("token"))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, offer.ToPublicResponse())
+}
+
+// AcceptOffer 接受录取
+// @Summary 接受录取
+// @Tags 录取
+// @Produce json
+// @Param token path string true "答复令牌"
+// @Success 200 {object} response.Response{data=models.OfferPublicResponse}
+// @Failure 400 {object} response.Response
+// @Router /offers/{token}/accept [post]
+func (h *OfferHandler) AcceptOffer(c *gin.Context) {
+	offer, err := h.offerService.Accept(c.Param("token"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "🎉 已确认接受录取，欢迎加入EPI实验室！", offer.ToPublicResponse())
+}
+
+// DeclineOffer 放弃录取
+// @Summary 放弃录取
+// @Description 放弃后名额将自动递补给候补名单中的下一位申请人
+// @Tags 录取
+// @Produce json
+// @Param token path string true "答复令牌"
+// @Success 200 {object} response.Response{data=models.OfferPublicResponse}
+// @Failure 400 {object} response.Response
+// @Router /offers/{token}/decline [post]
+func (h *OfferHandler) DeclineOffer(c *gin.Context) {
+	offer, err := h.offerService.Decline(c.Param("token"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "已放弃录取，感谢您的关注", offer.ToPublicResponse())
+}
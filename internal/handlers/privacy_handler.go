@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+	"lab-recruitment-platform/pkg/validator"
+)
+
+// PrivacyHandler 个人数据处理器
+type PrivacyHandler struct {
+	privacyService *services.PrivacyService
+	auditService   *services.AuditService
+}
+
+// NewPrivacyHandler 创建个人数据处理器实例
+func NewPrivacyHandler() *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: services.NewPrivacyService(),
+		auditService:   services.NewAuditService(),
+	}
+}
+
+// DataExportRequest 个人数据导出请求
+type DataExportRequest struct {
+	Email            string `json:"email" validate:"required,email"`
+	VerificationCode string `json:"verification_code" validate:"required,len=6"`
+	Format           string `json:"format" validate:"omitempty,oneof=json zip"`
+}
+
+// ErasureSubmitRequest 个人数据删除请求
+type ErasureSubmitRequest struct {
+	Email            string `json:"email" validate:"required,email"`
+	VerificationCode string `json:"verification_code" validate:"required,len=6"`
+	Reason           string `json:"reason" validate:"max=500"`
+}
+
+// ExportData 导出个人数据
+// @Summary 导出个人数据
+// @Description 申请人凭邮箱验证码（通过 /send-code 以 purpose=privacy 获取）下载本人的申请、附件和邮件记录，format=zip 时包含附件原文件
+// @Tags 个人数据
+// @Accept json
+// @Produce json,application/zip
+// @Param request body DataExportRequest true "导出请求"
+// @Success 200 {object} response.Response{data=models.PersonalDataExport}
+// @Failure 400 {object} response.Response
+// @Router /privacy/export [post]
+func (h *PrivacyHandler) ExportData(c *gin.Context) {
+	var req DataExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	if !verificationCodes.consume(req.Email, req.VerificationCode) {
+		response.BadRequest(c, "验证码错误或已过期")
+		return
+	}
+
+	export, files, err := h.privacyService.ExportData(req.Email)
+	if err != nil {
+		logger.Errorf("导出个人数据失败: %v", err)
+		response.InternalServerError(c, "导出个人数据失败")
+		return
+	}
+
+	h.auditService.Record(services.AuditEntry{
+		Action:       "privacy.export",
+		ResourceType: "personal_data",
+		Details: models.JSONMap{
+			"format":       req.Format,
+			"applications": len(export.Applications),
+			"attachments":  len(files),
+		},
+	}.WithRequest(c))
+
+	if req.Format != "zip" {
+		response.Success(c, export)
+		return
+	}
+
+	archive, err := h.privacyService.BuildExportArchive(export, files)
+	if err != nil {
+		logger.Errorf("打包个人数据失败: %v", err)
+		response.InternalServerError(c, "导出个人数据失败")
+		return
+	}
+
+	filename := fmt.Sprintf("personal-data-%s.zip", time.Now().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// SubmitErasureRequest 提交个人数据删除请求
+// @Summary 提交个人数据删除请求
+// @Description 申请人凭邮箱验证码（通过 /send-code 以 purpose=privacy 获取）申请删除本人数据，经管理员审核后执行
+// @Tags 个人数据
+// @Accept json
+// @Produce json
+// @Param request body ErasureSubmitRequest true "删除请求"
+// @Success 200 {object} response.Response{data=models.ErasureRequest}
+// @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /privacy/erasure [post]
+func (h *PrivacyHandler) SubmitErasureRequest(c *gin.Context) {
+	var req ErasureSubmitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	if !verificationCodes.consume(req.Email, req.VerificationCode) {
+		response.BadRequest(c, "验证码错误或已过期")
+		return
+	}
+
+	request, err := h.privacyService.CreateErasureRequest(req.Email, req.Reason)
+	if err != nil {
+		if errors.Is(err, services.ErrErasureRequestPending) {
+			response.Conflict(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "删除请求已提交，管理员审核后将处理您的数据", request)
+}
+
+// ListErasureRequests 获取删除请求列表（管理员接口）
+// @Summary 获取个人数据删除请求列表
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param status query string false "状态过滤" Enums(pending,approved,rejected)
+// @Success 200 {object} response.Response{data=models.ErasureRequestListResponse}
+// @Failure 401 {object} response.Response
+// @Router /admin/erasure-requests [get]
+func (h *PrivacyHandler) ListErasureRequests(c *gin.Context) {
+	page, size := response.GetPaginationParams(c)
+
+	result, err := h.privacyService.ListErasureRequests(page, size, c.Query("status"))
+	if err != nil {
+		logger.Errorf("获取删除请求列表失败: %v", err)
+		response.InternalServerError(c, "获取删除请求列表失败")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// ReviewErasureRequest 审核删除请求（管理员接口）
+// @Summary 审核个人数据删除请求
+// @Description 批准后按 mode 永久删除（delete，默认）或匿名化（anonymize）申请人全部数据，操作不可恢复
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "删除请求ID"
+// @Param request body models.ErasureReviewRequest true "审核结果"
+// @Success 200 {object} response.Response{data=models.ErasureRequest}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /admin/erasure-requests/{id}/review [put]
+func (h *PrivacyHandler) ReviewErasureRequest(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的请求ID")
+		return
+	}
+
+	var req models.ErasureReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	var operatorID *uint
+	if userID, exists := middleware.GetCurrentUserID(c); exists {
+		operatorID = &userID
+	}
+
+	request, err := h.privacyService.ReviewErasureRequest(uint(id), &req, operatorID)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "删除请求已处理", request)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+)
+
+// RetentionHandler 个人信息保留策略处理器
+type RetentionHandler struct {
+	retentionService *services.RetentionService
+	auditService     *services.AuditService
+}
+
+// NewRetentionHandler 创建保留策略处理器实例
+func NewRetentionHandler() *RetentionHandler {
+	return &RetentionHandler{
+		retentionService: services.NewRetentionService(),
+		auditService:     services.NewAuditService(),
+	}
+}
+
+// Preview 预演匿名化（管理员接口）
+// @Summary 预演个人信息匿名化
+// @Description 按当前保留策略列出将被匿名化的申请，不做任何修改
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=models.RetentionReport}
+// @Failure 401 {object} response.Response
+// @Router /admin/retention/preview [get]
+func (h *RetentionHandler) Preview(c *gin.Context) {
+	report, err := h.retentionService.Preview()
+	if err != nil {
+		logger.Errorf("预演匿名化失败: %v", err)
+		response.InternalServerError(c, "预演匿名化失败")
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// Run 立即执行匿名化（超级管理员接口）
+// @Summary 执行个人信息匿名化
+// @Description 按当前保留策略立即匿名化到期申请，操作不可恢复并写入审计记录
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=models.RetentionReport}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /admin/retention/run [post]
+func (h *RetentionHandler) Run(c *gin.Context) {
+	var operatorID *uint
+	if userID, exists := middleware.GetCurrentUserID(c); exists {
+		operatorID = &userID
+	}
+
+	report, err := h.retentionService.Run(operatorID, "manual")
+	if err != nil {
+		logger.Errorf("执行匿名化失败: %v", err)
+		response.InternalServerError(c, "执行匿名化失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "匿名化执行完成", report)
+}
+
+// ListAuditLogs 获取审计记录列表（管理员接口）
+// @Summary 获取审计记录列表
+// @Description 按时间倒序列出审计记录，包括每次匿名化执行与个人数据删除
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param action query string false "操作类型过滤，如 retention.anonymize"
+// @Success 200 {object} response.Response{data=models.OperationLogListResponse}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /admin/audit-logs [get]
+func (h *RetentionHandler) ListAuditLogs(c *gin.Context) {
+	page, size := response.GetPaginationParams(c)
+
+	result, err := h.auditService.ListLogs(page, size, c.Query("action"))
+	if err != nil {
+		logger.Errorf("获取审计记录列表失败: %v", err)
+		response.InternalServerError(c, "获取审计记录列表失败")
+		return
+	}
+
+	response.Success(c, result)
+}
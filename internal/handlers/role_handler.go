@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+	"lab-recruitment-platform/pkg/validator"
+)
+
+// RoleHandler 角色权限处理器
+type RoleHandler struct {
+	roleService  *services.RoleService
+	userService  *services.UserService
+	auditService *services.AuditService
+	tokenService *services.TokenService
+}
+
+// NewRoleHandler 创建角色权限处理器实例
+func NewRoleHandler() *RoleHandler {
+	return &RoleHandler{
+		roleService:  services.NewRoleService(),
+		userService:  services.NewUserService(),
+		auditService: services.NewAuditService(),
+		tokenService: services.NewTokenService(),
+	}
+}
+
+// ListPermissions 获取系统支持的权限列表
+// @Summary 权限列表
+// @Description 获取系统支持的全部权限标识及说明
+// @Tags 角色
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.PermissionInfo}
+// @Router /admin/permissions [get]
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	response.Success(c, models.AllPermissions)
+}
+
+// ListRoles 获取角色列表
+// @Summary 角色列表
+// @Description 获取全部角色及其权限
+// @Tags 角色
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.RoleResponse}
+// @Router /admin/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles()
+	if err != nil {
+		response.InternalServerError(c, "获取角色列表失败")
+		return
+	}
+
+	list := make([]models.RoleResponse, 0, len(roles))
+	for i := range roles {
+		list = append(list, roles[i].ToResponse())
+	}
+	response.Success(c, list)
+}
+
+// GetRole 获取角色详情
+// @Summary 角色详情
+// @Tags 角色
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "角色ID"
+// @Success 200 {object} response.Response{data=models.RoleResponse}
+// @Failure 404 {object} response.Response
+// @Router /admin/roles/{id} [get]
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	id, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	role, err := h.roleService.GetRoleByID(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+	response.Success(c, role.ToResponse())
+}
+
+// CreateRole 创建角色
+// @Summary 创建角色
+// @Tags 角色
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.RoleCreateRequest true "角色信息"
+// @Success 200 {object} response.Response{data=models.RoleResponse}
+// @Failure 400 {object} response.Response
+// @Router /admin/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req models.RoleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	role, err := h.roleService.CreateRole(&req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	h.recordAudit(c, "role.create", "role", role.ID, models.JSONMap{
+		"name":        role.Name,
+		"permissions": []string(role.Permissions),
+	})
+	response.SuccessWithMessage(c, "角色创建成功", role.ToResponse())
+}
+
+// UpdateRole 更新角色
+// @Summary 更新角色
+// @Description 更新角色信息，权限变更时撤销该角色下所有用户的现有会话使新权限立即生效
+// @Tags 角色
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "角色ID"
+// @Param request body models.RoleUpdateRequest true "角色信息"
+// @Success 200 {object} response.Response{data=models.RoleResponse}
+// @Failure 400 {object} response.Response
+// @Router /admin/roles/{id} [put]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	id, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RoleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(id, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if req.Permissions != nil {
+		userIDs, err := h.roleService.RoleUserIDs(role.ID)
+		if err != nil {
+			logger.Errorf("获取角色用户失败: role_id=%d, %v", role.ID, err)
+		}
+		h.revokeSessions(userIDs)
+	}
+
+	h.recordAudit(c, "role.update", "role", role.ID, models.JSONMap{
+		"name":        role.Name,
+		"permissions": []string(role.Permissions),
+	})
+	response.SuccessWithMessage(c, "角色更新成功", role.ToResponse())
+}
+
+// DeleteRole 删除角色
+// @Summary 删除角色
+// @Description 删除自定义角色并解除其与用户的关联，同时撤销这些用户的现有会话，系统预置角色不可删除
+// @Tags 角色
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "角色ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /admin/roles/{id} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	id, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	userIDs, err := h.roleService.RoleUserIDs(id)
+	if err != nil {
+		response.InternalServerError(c, "获取角色用户失败")
+		return
+	}
+
+	if err := h.roleService.DeleteRole(id); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	h.revokeSessions(userIDs)
+
+	h.recordAudit(c, "role.delete", "role", id, nil)
+	response.SuccessWithMessage(c, "角色删除成功", nil)
+}
+
+// SetUserRoles 分配用户角色
+// @Summary 分配用户角色
+// @Description 替换用户被分配的角色，并撤销该用户的现有会话使新权限立即生效
+// @Tags 角色
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param request body models.UserRolesRequest true "角色ID列表"
+// @Success 200 {object} response.Response{data=models.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /admin/users/{id}/roles [put]
+func (h *RoleHandler) SetUserRoles(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的用户ID")
+		return
+	}
+
+	var req models.UserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if currentID, exists := middleware.GetCurrentUserID(c); exists && currentID == uint(id) {
+		response.Forbidden(c, "不能修改自己的角色")
+		return
+	}
+
+	target, err := h.userService.GetUserByID(uint(id))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if target.IsAdmin() && !middleware.IsCurrentUserSuperAdmin(c) {
+		response.Forbidden(c, "需要超级管理员权限")
+		return
+	}
+
+	user, err := h.userService.SetUserRoles(uint(id), req.RoleIDs)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	// 令牌中携带的权限已过期，撤销会话使新权限立即生效
+	h.revokeSessions([]uint{user.ID})
+
+	h.recordAudit(c, "user.roles", "user", user.ID, models.JSONMap{
+		"role_ids":    req.RoleIDs,
+		"permissions": user.Permissions(),
+	})
+	response.SuccessWithMessage(c, "用户角色已更新", user.ToResponse())
+}
+
+// revokeSessions 撤销用户的现有会话，使令牌中携带的权限立即失效
+func (h *RoleHandler) revokeSessions(userIDs []uint) {
+	for _, userID := range userIDs {
+		if err := h.tokenService.RevokeAllSessions(userID); err != nil {
+			logger.Errorf("撤销用户会话失败: user_id=%d, %v", userID, err)
+		}
+	}
+}
+
+// recordAudit 记录角色相关操作日志
+func (h *RoleHandler) recordAudit(c *gin.Context, action, resourceType string, resourceID uint, details models.JSONMap) {
+	var operatorID *uint
+	if userID, exists := middleware.GetCurrentUserID(c); exists {
+		operatorID = &userID
+	}
+	h.auditService.Record(services.AuditEntry{
+		UserID:       operatorID,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   &resourceID,
+		Details:      details,
+	}.WithRequest(c))
+}
+
+// parseRoleID 解析路径中的角色ID
+func parseRoleID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的角色ID")
+		return 0, false
+	}
+	return uint(id), true
+}
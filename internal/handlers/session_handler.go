@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+)
+
+// SessionHandler 登录会话处理器
+type SessionHandler struct {
+	tokenService *services.TokenService
+	auditService *services.AuditService
+}
+
+// NewSessionHandler 创建登录会话处理器实例
+func NewSessionHandler() *SessionHandler {
+	return &SessionHandler{
+		tokenService: services.NewTokenService(),
+		auditService: services.NewAuditService(),
+	}
+}
+
+// ListSessions 获取当前用户的登录会话
+// @Summary 登录设备列表
+// @Description 列出当前账户仍有效的登录会话（设备、IP、登录与最近活动时间），current 标记当前会话
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.UserSession}
+// @Router /auth/sessions [get]
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	claims, exists := middleware.GetCurrentClaims(c)
+	if !exists {
+		response.Unauthorized(c, "用户未登录")
+		return
+	}
+
+	sessions, err := h.tokenService.ListSessions(claims.UserID, claims.SessionID)
+	if err != nil {
+		logger.Errorf("获取会话列表失败: %v", err)
+		response.InternalServerError(c, "获取会话列表失败")
+		return
+	}
+	response.Success(c, sessions)
+}
+
+// RevokeSession 撤销当前用户的指定会话
+// @Summary 退出指定设备
+// @Description 撤销指定会话，该会话的访问令牌与刷新令牌立即失效
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "会话ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /auth/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的会话ID")
+		return
+	}
+
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未登录")
+		return
+	}
+
+	if err := h.tokenService.RevokeSessionByID(userID, uint(id)); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		logger.Errorf("撤销会话失败: %v", err)
+		response.InternalServerError(c, "撤销会话失败")
+		return
+	}
+
+	h.audit(c, "auth.session_revoke", userID, models.JSONMap{"session_id": id})
+	response.SuccessWithMessage(c, "已退出该设备", nil)
+}
+
+// RevokeOtherSessions 撤销当前用户除本会话外的全部会话
+// @Summary 退出其他设备
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=gin.H}
+// @Router /auth/sessions [delete]
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	claims, exists := middleware.GetCurrentClaims(c)
+	if !exists {
+		response.Unauthorized(c, "用户未登录")
+		return
+	}
+
+	revoked, err := h.tokenService.RevokeOtherSessions(claims.UserID, claims.SessionID)
+	if err != nil {
+		logger.Errorf("撤销其他会话失败: %v", err)
+		response.InternalServerError(c, "撤销其他会话失败")
+		return
+	}
+
+	h.audit(c, "auth.session_revoke_others", claims.UserID, models.JSONMap{"revoked": revoked})
+	response.SuccessWithMessage(c, "已退出其他设备", gin.H{"revoked": revoked})
+}
+
+// audit 记录会话相关操作
+func (h *SessionHandler) audit(c *gin.Context, action string, userID uint, details models.JSONMap) {
+	h.auditService.Record(services.AuditEntry{
+		UserID:       &userID,
+		Action:       action,
+		ResourceType: "user",
+		ResourceID:   &userID,
+		Details:      details,
+	}.WithRequest(c))
+}
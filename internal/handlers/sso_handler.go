@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+	"lab-recruitment-platform/pkg/validator"
+)
+
+// 保存登录 state 的 Cookie，回调时比对以防止登录CSRF
+const (
+	oidcStateCookie = "oidc_state"
+	casStateCookie  = "cas_state"
+)
+
+// casStateTTL CAS 登录 state 有效期
+const casStateTTL = 10 * time.Minute
+
+// SSOHandler 单点登录处理器
+type SSOHandler struct {
+	auth         *AuthHandler
+	sso          *services.SSOService
+	oidc         *services.OIDCService
+	cas          *services.CASService
+	auditService *services.AuditService
+}
+
+// NewSSOHandler 创建单点登录处理器实例
+func NewSSOHandler() *SSOHandler {
+	return &SSOHandler{
+		auth:         NewAuthHandler(),
+		sso:          services.NewSSOService(),
+		oidc:         services.NewOIDCService(),
+		cas:          services.NewCASService(),
+		auditService: services.NewAuditService(),
+	}
+}
+
+// SSOLoginCodeRequest 兑换单点登录码请求
+type SSOLoginCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// ListProviders 获取已启用的单点登录方式
+// @Summary 单点登录方式
+// @Description 返回已启用的单点登录方式及跳转地址，供登录页展示
+// @Tags 认证
+// @Produce json
+// @Success 200 {object} response.Response{data=[]gin.H}
+// @Router /auth/sso/providers [get]
+func (h *SSOHandler) ListProviders(c *gin.Context) {
+	providers := make([]gin.H, 0, 2)
+	if h.oidc.Enabled() {
+		providers = append(providers, gin.H{
+			"type":         services.SSOProviderOIDC,
+			"display_name": h.oidc.DisplayName(),
+			"login_url":    "/api/v1/auth/oidc/login",
+		})
+	}
+	if h.cas.Enabled() {
+		providers = append(providers, gin.H{
+			"type":         services.SSOProviderCAS,
+			"display_name": h.cas.DisplayName(),
+			"login_url":    "/api/v1/auth/cas/login",
+		})
+	}
+	response.Success(c, providers)
+}
+
+// OIDCLogin 跳转到身份提供方登录
+// @Summary 统一身份认证登录
+// @Description 生成 state、nonce 与 PKCE 校验码后302跳转到身份提供方授权页
+// @Tags 认证
+// @Success 302
+// @Failure 404 {object} response.Response
+// @Router /auth/oidc/login [get]
+func (h *SSOHandler) OIDCLogin(c *gin.Context) {
+	authURL, state, err := h.oidc.AuthorizationURL()
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOIDCDisabled):
+			response.NotFound(c, err.Error())
+		case errors.Is(err, services.ErrOIDCLoginFailed):
+			response.Error(c, http.StatusBadGateway, "统一身份认证服务暂不可用")
+		default:
+			logger.Errorf("生成单点登录地址失败: %v", err)
+			response.InternalServerError(c, "单点登录暂不可用，请稍后重试")
+		}
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, int(h.oidc.StateTTL().Seconds()), "/api/v1/auth/oidc", "", h.oidc.SecureCookie(), true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback 身份提供方回调
+// @Summary 统一身份认证回调
+// @Description 校验 state 后用授权码换取并验证ID令牌，按邮箱匹配或创建账户，再携带一次性登录码跳转回前端（#code=...），失败时携带 #error=...
+// @Tags 认证
+// @Param code query string false "授权码"
+// @Param state query string true "授权状态"
+// @Success 302
+// @Router /auth/oidc/callback [get]
+func (h *SSOHandler) OIDCCallback(c *gin.Context) {
+	state := c.Query("state")
+	cookieState, _ := c.Cookie(oidcStateCookie)
+	c.SetCookie(oidcStateCookie, "", -1, "/api/v1/auth/oidc", "", h.oidc.SecureCookie(), true)
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		logger.Warnf("身份提供方返回错误: %s %s", providerErr, c.Query("error_description"))
+		h.redirectError(c, h.oidc.FrontendURL(), "统一身份认证已取消或被拒绝")
+		return
+	}
+	if state == "" || cookieState != state {
+		h.redirectError(c, h.oidc.FrontendURL(), services.ErrOIDCStateInvalid.Error())
+		return
+	}
+
+	result, err := h.oidc.HandleCallback(c.Query("code"), state)
+	if err != nil {
+		message := "统一身份认证失败，请重试"
+		switch {
+		case errors.Is(err, services.ErrOIDCStateInvalid),
+			errors.Is(err, services.ErrOIDCLoginFailed),
+			errors.Is(err, services.ErrOIDCEmailRequired),
+			errors.Is(err, services.ErrOIDCDomainNotAllowed),
+			errors.Is(err, services.ErrSSOAccountDisabled),
+			errors.Is(err, services.ErrSSOAccountNotFound):
+			message = err.Error()
+		default:
+			logger.Errorf("单点登录失败: %v", err)
+		}
+		h.redirectError(c, h.oidc.FrontendURL(), message)
+		return
+	}
+
+	h.finishLogin(c, h.oidc.FrontendURL(), services.SSOProviderOIDC, result.User, result.Created, models.JSONMap{"subject": result.Subject})
+}
+
+// CASLogin 跳转到CAS统一身份认证登录页
+// @Summary CAS统一身份认证登录
+// @Description 302跳转到学校CAS登录页，登录后CAS携带服务票据回调
+// @Tags 认证
+// @Success 302
+// @Failure 404 {object} response.Response
+// @Router /auth/cas/login [get]
+func (h *SSOHandler) CASLogin(c *gin.Context) {
+	state, err := services.NewLoginState()
+	if err != nil {
+		logger.Errorf("生成CAS登录状态失败: %v", err)
+		response.InternalServerError(c, "单点登录暂不可用，请稍后重试")
+		return
+	}
+
+	loginURL, err := h.cas.LoginURL(state)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(casStateCookie, state, int(casStateTTL.Seconds()), "/api/v1/auth/cas", "", h.cas.SecureCookie(), true)
+	c.Redirect(http.StatusFound, loginURL)
+}
+
+// CASCallback CAS回调
+// @Summary CAS统一身份认证回调
+// @Description 校验服务票据，以CAS用户名作为学号匹配账户（未匹配时按邮箱关联或自动创建学生账户，并由属性补全专业与年级），再携带一次性登录码跳转回前端
+// @Tags 认证
+// @Param ticket query string true "服务票据"
+// @Param state query string true "登录状态"
+// @Success 302
+// @Router /auth/cas/callback [get]
+func (h *SSOHandler) CASCallback(c *gin.Context) {
+	state := c.Query("state")
+	cookieState, _ := c.Cookie(casStateCookie)
+	c.SetCookie(casStateCookie, "", -1, "/api/v1/auth/cas", "", h.cas.SecureCookie(), true)
+
+	if state == "" || cookieState != state {
+		h.redirectError(c, h.cas.FrontendURL(), "登录请求已过期，请重新登录")
+		return
+	}
+
+	result, err := h.cas.HandleCallback(c.Query("ticket"), state)
+	if err != nil {
+		message := "统一身份认证失败，请重试"
+		switch {
+		case errors.Is(err, services.ErrCASDisabled),
+			errors.Is(err, services.ErrCASTicketInvalid),
+			errors.Is(err, services.ErrCASUnavailable),
+			errors.Is(err, services.ErrCASPrincipalInvalid),
+			errors.Is(err, services.ErrCASEmailRequired),
+			errors.Is(err, services.ErrCASAccountConflict),
+			errors.Is(err, services.ErrSSOAccountDisabled),
+			errors.Is(err, services.ErrSSOAccountNotFound):
+			message = err.Error()
+		default:
+			logger.Errorf("CAS登录失败: %v", err)
+		}
+		h.redirectError(c, h.cas.FrontendURL(), message)
+		return
+	}
+
+	h.finishLogin(c, h.cas.FrontendURL(), services.SSOProviderCAS, result.User, result.Created, models.JSONMap{"student_id": result.Principal})
+}
+
+// ExchangeLoginCode 兑换单点登录码
+// @Summary 兑换单点登录码
+// @Description 前端回调页用一次性登录码换取访问令牌与刷新令牌；账户启用了两步验证时与密码登录一样返回预认证令牌
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body SSOLoginCodeRequest true "一次性登录码"
+// @Success 200 {object} response.Response{data=gin.H}
+// @Failure 401 {object} response.Response
+// @Router /auth/sso/exchange [post]
+func (h *SSOHandler) ExchangeLoginCode(c *gin.Context) {
+	var req SSOLoginCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	userID, _, err := h.sso.RedeemLoginCode(req.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrSSOLoginCodeInvalid) {
+			response.Unauthorized(c, err.Error())
+			return
+		}
+		logger.Errorf("兑换单点登录码失败: %v", err)
+		response.InternalServerError(c, "登录失败，请稍后重试")
+		return
+	}
+
+	user, err := h.auth.userService.GetUserByID(userID)
+	if err != nil {
+		response.Unauthorized(c, services.ErrSSOLoginCodeInvalid.Error())
+		return
+	}
+	if !user.IsActive() {
+		response.Forbidden(c, "账户已被禁用")
+		return
+	}
+
+	if h.auth.requireSecondFactor(c, user) {
+		return
+	}
+	completeLogin(c, h.auth.tokenService, user, nil)
+}
+
+// finishLogin 签发一次性登录码并跳转回前端
+func (h *SSOHandler) finishLogin(c *gin.Context, frontendURL, provider string, user *models.User, created bool, details models.JSONMap) {
+	code, err := h.sso.IssueLoginCode(user.ID, provider)
+	if err != nil {
+		logger.Errorf("签发单点登录码失败: %v", err)
+		h.redirectError(c, frontendURL, "登录失败，请稍后重试")
+		return
+	}
+
+	if details == nil {
+		details = models.JSONMap{}
+	}
+	details["provider"] = provider
+	details["created"] = created
+	h.auditService.Record(services.AuditEntry{
+		UserID:       &user.ID,
+		Action:       "auth.sso_login",
+		ResourceType: "user",
+		ResourceID:   &user.ID,
+		Details:      details,
+	}.WithRequest(c))
+
+	c.Redirect(http.StatusFound, services.FrontendRedirect(frontendURL, url.Values{"code": {code}}))
+}
+
+// redirectError 携带错误信息跳转回前端
+func (h *SSOHandler) redirectError(c *gin.Context, frontendURL, message string) {
+	c.Redirect(http.StatusFound, services.FrontendRedirect(frontendURL, url.Values{"error": {message}}))
+}
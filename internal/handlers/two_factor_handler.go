@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+	"lab-recruitment-platform/pkg/validator"
+)
+
+// TwoFactorHandler 两步验证处理器
+type TwoFactorHandler struct {
+	userService  *services.UserService
+	twoFactor    *services.TwoFactorService
+	webauthn     *services.WebAuthnService
+	tokenService *services.TokenService
+	auditService *services.AuditService
+}
+
+// NewTwoFactorHandler 创建两步验证处理器实例
+func NewTwoFactorHandler() *TwoFactorHandler {
+	return &TwoFactorHandler{
+		userService:  services.NewUserService(),
+		twoFactor:    services.NewTwoFactorService(),
+		webauthn:     services.NewWebAuthnService(),
+		tokenService: services.NewTokenService(),
+		auditService: services.NewAuditService(),
+	}
+}
+
+// GetStatus 获取当前用户两步验证状态
+// @Summary 两步验证状态
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=models.TwoFactorStatusResponse}
+// @Router /auth/2fa [get]
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.twoFactor.GetStatus(user)
+	if err != nil {
+		logger.Errorf("获取两步验证状态失败: %v", err)
+		response.InternalServerError(c, "获取两步验证状态失败")
+		return
+	}
+	response.Success(c, status)
+}
+
+// Setup 生成TOTP密钥
+// @Summary 绑定两步验证
+// @Description 生成TOTP密钥与 otpauth URI（可编码为二维码供验证器扫描）；登录时被要求绑定的管理员使用预认证令牌调用
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorSetupRequest false "预认证令牌"
+// @Success 200 {object} response.Response{data=models.TwoFactorSetupResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /auth/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	var req models.TwoFactorSetupRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "请求参数错误")
+			return
+		}
+	}
+
+	user, ok := h.enrollingUser(c, req.MFAToken)
+	if !ok {
+		return
+	}
+
+	setup, err := h.twoFactor.Setup(user)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	response.SuccessWithMessage(c, "请使用验证器应用扫描二维码，并输入验证码完成绑定", setup)
+}
+
+// Enable 确认并启用两步验证
+// @Summary 启用两步验证
+// @Description 使用验证器生成的验证码确认绑定，返回一次性恢复码（仅显示一次）；通过预认证令牌绑定时同时完成登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorEnableRequest true "验证码"
+// @Success 200 {object} response.Response{data=gin.H}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /auth/2fa/enable [post]
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	var req models.TwoFactorEnableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	user, ok := h.enrollingUser(c, req.MFAToken)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactor.Enable(user.ID, req.Code)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	h.audit(c, "auth.2fa_enable", user.ID, nil)
+
+	if req.MFAToken != "" {
+		h.twoFactor.DeletePreAuthToken(req.MFAToken)
+		completeLogin(c, h.tokenService, user, gin.H{"recovery_codes": codes})
+		return
+	}
+	response.SuccessWithMessage(c, "两步验证已启用，请妥善保存恢复码", gin.H{"recovery_codes": codes})
+}
+
+// Verify 登录第二步验证
+// @Summary 两步验证登录
+// @Description 使用预认证令牌与验证码（或恢复码）完成登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorVerifyRequest true "验证信息"
+// @Success 200 {object} response.Response{data=gin.H}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /auth/2fa/verify [post]
+func (h *TwoFactorHandler) Verify(c *gin.Context) {
+	var req models.TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+	if (req.Code == "") == (req.RecoveryCode == "") {
+		response.BadRequest(c, "请提供验证码或恢复码其中之一")
+		return
+	}
+
+	userID, err := h.twoFactor.CheckPreAuthToken(req.MFAToken, services.MFAPurposeVerify)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	if req.Code != "" {
+		err = h.twoFactor.VerifyCode(userID, req.Code)
+	} else {
+		err = h.twoFactor.UseRecoveryCode(userID, req.RecoveryCode)
+		if err == nil {
+			h.audit(c, "auth.2fa_recovery_used", userID, nil)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTwoFactorCode) || errors.Is(err, services.ErrTwoFactorNotEnabled) {
+			response.Unauthorized(c, err.Error())
+			return
+		}
+		logger.Errorf("两步验证失败: %v", err)
+		response.InternalServerError(c, "验证失败，请稍后重试")
+		return
+	}
+
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil || !user.IsActive() {
+		response.Unauthorized(c, "账户不可用")
+		return
+	}
+
+	h.twoFactor.DeletePreAuthToken(req.MFAToken)
+	completeLogin(c, h.tokenService, user, nil)
+}
+
+// Disable 停用两步验证
+// @Summary 停用两步验证
+// @Description 需提供当前验证码；被强制要求两步验证且未注册通行密钥的账户不可停用
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if h.twoFactor.IsRequired(user) {
+		hasPasskey, err := h.webauthn.HasCredentials(user)
+		if err != nil {
+			logger.Errorf("查询通行密钥失败: %v", err)
+			response.InternalServerError(c, "停用两步验证失败")
+			return
+		}
+		if !hasPasskey {
+			response.Forbidden(c, "管理员账户必须启用两步验证")
+			return
+		}
+	}
+
+	if err := h.twoFactor.VerifyCode(user.ID, req.Code); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err := h.twoFactor.Disable(user.ID); err != nil {
+		logger.Errorf("停用两步验证失败: %v", err)
+		response.InternalServerError(c, "停用两步验证失败")
+		return
+	}
+
+	h.audit(c, "auth.2fa_disable", user.ID, nil)
+	response.SuccessWithMessage(c, "两步验证已停用", nil)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// @Summary 重新生成恢复码
+// @Description 需提供当前验证码，原有恢复码全部作废
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} response.Response{data=gin.H}
+// @Failure 400 {object} response.Response
+// @Router /auth/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactor.VerifyCode(user.ID, req.Code); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	codes, err := h.twoFactor.RegenerateRecoveryCodes(user.ID)
+	if err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	h.audit(c, "auth.2fa_recovery_regenerate", user.ID, nil)
+	response.SuccessWithMessage(c, "恢复码已重新生成，请妥善保存", gin.H{"recovery_codes": codes})
+}
+
+// currentUser 获取当前登录用户，失败时已写入响应
+func (h *TwoFactorHandler) currentUser(c *gin.Context) (*models.User, bool) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未登录")
+		return nil, false
+	}
+
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil {
+		response.NotFound(c, "用户不存在")
+		return nil, false
+	}
+	return user, true
+}
+
+// enrollingUser 获取正在绑定两步验证的用户：优先使用预认证令牌，否则要求已登录
+func (h *TwoFactorHandler) enrollingUser(c *gin.Context, mfaToken string) (*models.User, bool) {
+	if mfaToken == "" {
+		return h.currentUser(c)
+	}
+
+	userID, err := h.twoFactor.CheckPreAuthToken(mfaToken, services.MFAPurposeEnroll)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return nil, false
+	}
+
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil || !user.IsActive() {
+		response.Unauthorized(c, "账户不可用")
+		return nil, false
+	}
+	return user, true
+}
+
+// audit 记录两步验证相关操作
+func (h *TwoFactorHandler) audit(c *gin.Context, action string, userID uint, details models.JSONMap) {
+	h.auditService.Record(services.AuditEntry{
+		UserID:       &userID,
+		Action:       action,
+		ResourceType: "user",
+		ResourceID:   &userID,
+		Details:      details,
+	}.WithRequest(c))
+}
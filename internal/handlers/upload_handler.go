@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+	"lab-recruitment-platform/pkg/validator"
+)
+
+// UploadHandler 文件上传处理器
+type UploadHandler struct {
+	uploadService    *services.UploadService
+	interviewService *services.InterviewApplicationService
+	blindReview      *services.BlindReviewService
+}
+
+// NewUploadHandler 创建文件上传处理器实例
+func NewUploadHandler() *UploadHandler {
+	return &UploadHandler{
+		uploadService:    services.NewUploadService(),
+		interviewService: services.NewInterviewApplicationService(),
+		blindReview:      services.NewBlindReviewService(),
+	}
+}
+
+// UploadAttachment 申请人上传简历/作品集
+// @Summary 上传申请附件
+// @Description 申请人提交申请前上传简历或作品集，需携带发送到该邮箱的验证码（不会被消费，提交申请时仍使用同一验证码），
+// @Description 同一邮箱最多保留5个未提交的附件；返回的文件ID在提交申请时通过attachment_ids关联
+// @Tags 申请
+// @Accept multipart/form-data
+// @Produce json
+// @Param email formData string true "申请邮箱"
+// @Param verification_code formData string true "邮箱验证码"
+// @Param category formData string false "附件类别" Enums(resume,portfolio,other)
+// @Param file formData file true "附件文件"
+// @Success 200 {object} response.Response{data=models.FileUploadResponse}
+// @Failure 400 {object} response.Response
+// @Router /upload/attachment [post]
+func (h *UploadHandler) UploadAttachment(c *gin.Context) {
+	email := c.PostForm("email")
+	if !validator.ValidateEmail(email) {
+		response.BadRequest(c, "邮箱格式不正确")
+		return
+	}
+	if !checkCode(email, c.PostForm("verification_code")) {
+		response.BadRequest(c, "验证码错误或已过期")
+		return
+	}
+
+	category, ok := parseCategory(c.DefaultPostForm("category", "resume"))
+	if !ok {
+		response.BadRequest(c, "附件类别必须是以下值之一: resume portfolio other")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.FileUploadError(c, "请选择要上传的文件")
+		return
+	}
+
+	upload, err := h.uploadService.SaveFile(fileHeader, category, nil, email, nil)
+	if err != nil {
+		logger.Warnf("申请附件上传失败: email=%s, err=%v", email, err)
+		response.FileUploadError(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "文件上传成功", upload.ToResponse())
+}
+
+// UploadApplicationAttachment 管理员为面试申请上传附件
+// @Summary 为面试申请上传附件
+// @Description 管理员直接为指定面试申请上传附件
+// @Tags 管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Param category formData string false "附件类别" Enums(resume,portfolio,other)
+// @Param file formData file true "附件文件"
+// @Success 200 {object} response.Response{data=models.FileUploadResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /admin/applications/{id}/attachments [post]
+func (h *UploadHandler) UploadApplicationAttachment(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的申请ID")
+		return
+	}
+
+	application, err := h.interviewService.GetApplicationByID(uint(id))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	category, ok := parseCategory(c.DefaultPostForm("category", "other"))
+	if !ok {
+		response.BadRequest(c, "附件类别必须是以下值之一: resume portfolio other")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.FileUploadError(c, "请选择要上传的文件")
+		return
+	}
+
+	var uploaderID *uint
+	if userID, exists := middleware.GetCurrentUserID(c); exists {
+		uploaderID = &userID
+	}
+
+	upload, err := h.uploadService.SaveFile(fileHeader, category, uploaderID, "", &application.ID)
+	if err != nil {
+		logger.Warnf("管理员上传附件失败: 申请ID=%d, err=%v", application.ID, err)
+		response.FileUploadError(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "文件上传成功", upload.ToResponse())
+}
+
+// ListApplicationAttachments 获取面试申请附件列表
+// @Summary 获取面试申请附件列表
+// @Description 获取指定面试申请关联的所有附件
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "申请ID"
+// @Success 200 {object} response.Response{data=[]models.FileUploadResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /admin/applications/{id}/attachments [get]
+func (h *UploadHandler) ListApplicationAttachments(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的申请ID")
+		return
+	}
+
+	if !h.authorizeApplication(c, uint(id), "attachments") {
+		return
+	}
+
+	uploads, err := h.uploadService.ListApplicationFiles(uint(id))
+	if err != nil {
+		logger.Errorf("获取申请附件失败: %v", err)
+		response.InternalServerError(c, "获取申请附件失败")
+		return
+	}
+
+	list := make([]models.FileUploadResponse, len(uploads))
+	for i, upload := range uploads {
+		list[i] = *upload.ToResponse()
+	}
+
+	response.Success(c, list)
+}
+
+// DownloadAttachment 下载附件
+// @Summary 下载附件
+// @Description 管理员下载面试申请附件
+// @Tags 管理
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path int true "文件ID"
+// @Success 200 {file} file
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /admin/attachments/{id}/download [get]
+func (h *UploadHandler) DownloadAttachment(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的文件ID")
+		return
+	}
+
+	upload, err := h.uploadService.GetFileByID(uint(id))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	// 尚未关联申请的附件只允许上传者本人下载
+	if upload.ApplicationID == nil {
+		userID, _ := middleware.GetCurrentUserID(c)
+		if upload.UploaderID == nil || *upload.UploaderID != userID {
+			response.NotFound(c, "文件不存在")
+			return
+		}
+	} else if !h.authorizeApplication(c, *upload.ApplicationID, "attachment_download") {
+		return
+	}
+
+	c.Header("Content-Type", upload.MimeType)
+	c.FileAttachment(h.uploadService.GetFullPath(upload), upload.OriginalName)
+}
+
+// parseCategory 校验附件类别
+func parseCategory(category string) (string, bool) {
+	switch category {
+	case "resume", "portfolio", "other":
+		return category, true
+	default:
+		return "", false
+	}
+}
+
+// authorizeApplication 盲审阶段查看附件需要身份查看权限，失败时已写入响应
+func (h *UploadHandler) authorizeApplication(c *gin.Context, applicationID uint, purpose string) bool {
+	application, err := h.interviewService.GetApplicationByID(applicationID)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return false
+	}
+
+	if err := h.blindReview.Reveal(c, application, purpose, ""); err != nil {
+		response.Forbidden(c, err.Error())
+		return false
+	}
+	return true
+}
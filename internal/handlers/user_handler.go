@@ -0,0 +1,724 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+	"lab-recruitment-platform/pkg/validator"
+)
+
+// UserHandler 用户管理处理器
+type UserHandler struct {
+	userService  *services.UserService
+	mailService  *services.MailService
+	auditService *services.AuditService
+	tokenService *services.TokenService
+	loginGuard   *services.LoginGuardService
+	twoFactor    *services.TwoFactorService
+	webauthn     *services.WebAuthnService
+	apiTokens    *services.APITokenService
+}
+
+// NewUserHandler 创建用户管理处理器实例
+func NewUserHandler() *UserHandler {
+	return &UserHandler{
+		userService:  services.NewUserService(),
+		mailService:  services.NewMailService(),
+		auditService: services.NewAuditService(),
+		tokenService: services.NewTokenService(),
+		loginGuard:   services.NewLoginGuardService(),
+		twoFactor:    services.NewTwoFactorService(),
+		webauthn:     services.NewWebAuthnService(),
+		apiTokens:    services.NewAPITokenService(),
+	}
+}
+
+// CreateUser 创建用户并发送邀请邮件（管理员接口）
+// @Summary 创建用户
+// @Description 创建账户并向其邮箱发送包含初始密码的邀请邮件；创建管理员账户需要超级管理员权限
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UserCreateRequest true "用户信息"
+// @Success 200 {object} response.Response{data=models.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /users [post]
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req models.UserCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	if req.Role != "student" && !middleware.IsCurrentUserSuperAdmin(c) {
+		response.Forbidden(c, "创建管理员账户需要超级管理员权限")
+		return
+	}
+
+	user, password, err := h.userService.CreateUser(&req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := sendUserInviteEmail(h.mailService, user, password); err != nil {
+		logger.Errorf("发送邀请邮件失败: %v", err)
+	}
+
+	h.audit(c, "user.create", user.ID, models.JSONMap{"role": user.Role})
+	response.SuccessWithMessage(c, "用户创建成功，邀请邮件已发送", user.ToResponse())
+}
+
+// ListUsers 获取用户列表（管理员接口）
+// @Summary 获取用户列表
+// @Description 分页获取用户列表，支持按用户名、邮箱、学号搜索及角色、状态过滤
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param search query string false "搜索关键字"
+// @Param role query string false "角色过滤" Enums(student,admin,super_admin)
+// @Param status query string false "状态过滤" Enums(active,inactive,pending)
+// @Success 200 {object} response.Response{data=models.UserListResponse}
+// @Failure 401 {object} response.Response
+// @Router /users [get]
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	page, size := response.GetPaginationParams(c)
+
+	users, total, err := h.userService.ListUsers(page, size, c.Query("search"), c.Query("role"), c.Query("status"))
+	if err != nil {
+		logger.Errorf("获取用户列表失败: %v", err)
+		response.InternalServerError(c, "获取用户列表失败")
+		return
+	}
+
+	list := make([]models.UserResponse, len(users))
+	for i := range users {
+		list[i] = *users[i].ToResponse()
+	}
+
+	response.Success(c, models.UserListResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		List:  list,
+	})
+}
+
+// GetUser 获取用户详情（管理员接口）
+// @Summary 获取用户详情
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=models.UserResponse}
+// @Failure 404 {object} response.Response
+// @Router /users/{id} [get]
+func (h *UserHandler) GetUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUserByID(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, user.ToResponse())
+}
+
+// UpdateUser 更新用户信息、角色和状态（管理员接口）
+// @Summary 更新用户
+// @Description 更新用户资料、角色或状态；修改管理员账户或授予管理员角色需要超级管理员权限
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param request body models.UserAdminUpdateRequest true "更新信息"
+// @Success 200 {object} response.Response{data=models.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /users/{id} [put]
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UserAdminUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	target, ok := h.authorizeTarget(c, id)
+	if !ok {
+		return
+	}
+	if req.Role != "" && req.Role != target.Role {
+		if !h.authorizeRoleChange(c, id, req.Role) {
+			return
+		}
+	}
+	if req.Status != "" && req.Status != target.Status && !h.authorizeStatusChange(c, id) {
+		return
+	}
+
+	user, err := h.userService.UpdateUser(id, &req.UserUpdateRequest)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	details := models.JSONMap{}
+	if req.Role != "" && req.Role != target.Role {
+		if err := h.userService.UpdateUserRole(id, req.Role); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		user.Role = req.Role
+		details["role"] = req.Role
+	}
+	if req.Status != "" && req.Status != target.Status {
+		if err := h.userService.UpdateUserStatus(id, req.Status); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		user.Status = req.Status
+		details["status"] = req.Status
+	}
+
+	if len(details) > 0 {
+		h.revokeSessions(id)
+	}
+
+	h.audit(c, "user.update", id, details)
+	response.SuccessWithMessage(c, "用户更新成功", user.ToResponse())
+}
+
+// UpdateUserStatus 启用或停用用户（管理员接口）
+// @Summary 更新用户状态
+// @Description 停用后用户无法登录
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param request body models.UserStatusRequest true "状态"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /users/{id}/status [put]
+func (h *UserHandler) UpdateUserStatus(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	if _, ok := h.authorizeTarget(c, id); !ok {
+		return
+	}
+	if !h.authorizeStatusChange(c, id) {
+		return
+	}
+
+	if err := h.userService.UpdateUserStatus(id, req.Status); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if req.Status != "active" {
+		h.revokeSessions(id)
+	}
+
+	h.audit(c, "user.status", id, models.JSONMap{"status": req.Status})
+	response.SuccessWithMessage(c, "用户状态更新成功", nil)
+}
+
+// ResetPassword 重置用户密码（管理员接口）
+// @Summary 重置用户密码
+// @Description 未指定新密码时生成临时密码，新密码通过邮件发送给用户
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param request body models.UserResetPasswordRequest false "新密码"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /users/{id}/reset-password [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UserResetPasswordRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "请求参数错误")
+			return
+		}
+		if !validator.ValidateRequest(c, &req) {
+			return
+		}
+	}
+
+	user, ok := h.authorizeTarget(c, id)
+	if !ok {
+		return
+	}
+
+	password, err := h.userService.ResetPassword(id, req.NewPassword)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	h.revokeSessions(id)
+
+	if err := sendPasswordResetEmail(h.mailService, user, password); err != nil {
+		logger.Errorf("发送重置密码邮件失败: %v", err)
+	}
+
+	h.audit(c, "user.reset_password", id, nil)
+	response.SuccessWithMessage(c, "密码已重置，新密码已发送到用户邮箱", nil)
+}
+
+// UnlockUser 解除因登录失败导致的账户锁定（管理员接口）
+// @Summary 解除账户锁定
+// @Description 解除账户的临时登录锁定并清除失败次数
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /users/{id}/unlock [post]
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	user, ok := h.authorizeTarget(c, id)
+	if !ok {
+		return
+	}
+
+	wasLocked, err := h.loginGuard.Unlock(user.Email)
+	if err != nil {
+		logger.Errorf("解除账户锁定失败: %v", err)
+		response.InternalServerError(c, "解除账户锁定失败")
+		return
+	}
+
+	h.audit(c, "auth.unlock", id, models.JSONMap{"was_locked": wasLocked})
+	if !wasLocked {
+		response.SuccessWithMessage(c, "账户未处于锁定状态，已清除失败记录", nil)
+		return
+	}
+	response.SuccessWithMessage(c, "账户已解锁", nil)
+}
+
+// ListUserSessions 获取用户的登录会话（管理员接口）
+// @Summary 用户登录会话
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=[]models.UserSession}
+// @Failure 403 {object} response.Response
+// @Router /users/{id}/sessions [get]
+func (h *UserHandler) ListUserSessions(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	if _, ok := h.authorizeTarget(c, id); !ok {
+		return
+	}
+
+	sessions, err := h.tokenService.ListSessions(id, "")
+	if err != nil {
+		logger.Errorf("获取会话列表失败: %v", err)
+		response.InternalServerError(c, "获取会话列表失败")
+		return
+	}
+	response.Success(c, sessions)
+}
+
+// RevokeUserSessions 撤销用户的全部登录会话（管理员接口）
+// @Summary 强制下线用户
+// @Description 撤销用户的全部会话，其已签发的访问令牌与刷新令牌立即失效
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /users/{id}/sessions [delete]
+func (h *UserHandler) RevokeUserSessions(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	if _, ok := h.authorizeTarget(c, id); !ok {
+		return
+	}
+
+	if err := h.tokenService.RevokeAllSessions(id); err != nil {
+		logger.Errorf("撤销用户会话失败: user_id=%d, %v", id, err)
+		response.InternalServerError(c, "撤销用户会话失败")
+		return
+	}
+
+	h.audit(c, "auth.sessions_revoke", id, nil)
+	response.SuccessWithMessage(c, "已撤销该用户的全部会话", nil)
+}
+
+// ListUserAPITokens 获取用户的API令牌（管理员接口）
+// @Summary 用户API令牌
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=[]models.APIToken}
+// @Failure 403 {object} response.Response
+// @Router /users/{id}/api-tokens [get]
+func (h *UserHandler) ListUserAPITokens(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	if _, ok := h.authorizeTarget(c, id); !ok {
+		return
+	}
+
+	tokens, err := h.apiTokens.ListTokens(id)
+	if err != nil {
+		logger.Errorf("获取API令牌列表失败: %v", err)
+		response.InternalServerError(c, "获取API令牌列表失败")
+		return
+	}
+	response.Success(c, tokens)
+}
+
+// RevokeUserAPITokens 撤销用户的全部API令牌（管理员接口）
+// @Summary 撤销用户API令牌
+// @Description 令牌泄露或账户交接时撤销用户的全部API令牌
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=gin.H}
+// @Failure 403 {object} response.Response
+// @Router /users/{id}/api-tokens [delete]
+func (h *UserHandler) RevokeUserAPITokens(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	if _, ok := h.authorizeTarget(c, id); !ok {
+		return
+	}
+
+	revoked, err := h.apiTokens.RevokeAllTokens(id)
+	if err != nil {
+		logger.Errorf("撤销用户API令牌失败: user_id=%d, %v", id, err)
+		response.InternalServerError(c, "撤销用户API令牌失败")
+		return
+	}
+
+	h.audit(c, "auth.api_tokens_revoke", id, models.JSONMap{"revoked": revoked})
+	response.SuccessWithMessage(c, "已撤销该用户的全部API令牌", gin.H{"revoked": revoked})
+}
+
+// ResetTwoFactor 重置用户两步验证（管理员接口）
+// @Summary 重置两步验证
+// @Description 用户丢失验证器与恢复码时由管理员停用其两步验证并撤销其全部通行密钥，同时撤销其全部会话
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /users/{id}/2fa/reset [post]
+func (h *UserHandler) ResetTwoFactor(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if currentID, _ := middleware.GetCurrentUserID(c); currentID == id {
+		response.BadRequest(c, "不能重置自己的两步验证")
+		return
+	}
+	if _, ok := h.authorizeTarget(c, id); !ok {
+		return
+	}
+
+	if err := h.twoFactor.Disable(id); err != nil {
+		logger.Errorf("重置两步验证失败: %v", err)
+		response.InternalServerError(c, "重置两步验证失败")
+		return
+	}
+	passkeys, err := h.webauthn.DeleteAllCredentials(id)
+	if err != nil {
+		logger.Errorf("撤销通行密钥失败: %v", err)
+		response.InternalServerError(c, "重置两步验证失败")
+		return
+	}
+	h.revokeSessions(id)
+
+	h.audit(c, "auth.2fa_reset", id, models.JSONMap{"revoked_passkeys": passkeys})
+	response.SuccessWithMessage(c, "两步验证已重置", nil)
+}
+
+// DeleteUser 删除用户（管理员接口）
+// @Summary 删除用户
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /users/{id} [delete]
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if currentID, _ := middleware.GetCurrentUserID(c); currentID == id {
+		response.BadRequest(c, "不能删除自己的账户")
+		return
+	}
+	if _, ok := h.authorizeTarget(c, id); !ok {
+		return
+	}
+
+	if err := h.userService.DeleteUser(id); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	h.revokeSessions(id)
+
+	h.audit(c, "user.delete", id, nil)
+	response.SuccessWithMessage(c, "用户删除成功", nil)
+}
+
+// GetUserStats 获取用户统计（管理员接口）
+// @Summary 获取用户统计
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=map[string]int64}
+// @Failure 401 {object} response.Response
+// @Router /users/stats [get]
+func (h *UserHandler) GetUserStats(c *gin.Context) {
+	stats, err := h.userService.GetUserStats()
+	if err != nil {
+		logger.Errorf("获取用户统计失败: %v", err)
+		response.InternalServerError(c, "获取用户统计失败")
+		return
+	}
+
+	response.Success(c, stats)
+}
+
+// authorizeTarget 加载目标用户并检查当前用户能否管理该账户，失败时已写入响应
+func (h *UserHandler) authorizeTarget(c *gin.Context, id uint) (*models.User, bool) {
+	user, err := h.userService.GetUserByID(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return nil, false
+	}
+	if !canManageUser(c, user) {
+		return nil, false
+	}
+	return user, true
+}
+
+// canManageUser 普通管理员只能管理学生账户，且目标账户的权限不能超出当前用户的权限，失败时已写入响应
+func canManageUser(c *gin.Context, target *models.User) bool {
+	if middleware.IsCurrentUserSuperAdmin(c) {
+		return true
+	}
+	if target.IsAdmin() {
+		response.Forbidden(c, "管理管理员账户需要超级管理员权限")
+		return false
+	}
+
+	// 否则可通过重置密码登录目标账户，获得自己不具备的权限
+	granted := make(map[string]bool)
+	for _, p := range middleware.GetCurrentUserPermissions(c) {
+		granted[p] = true
+	}
+	for _, p := range target.Permissions() {
+		if !granted[p] {
+			response.Forbidden(c, "目标账户拥有您不具备的权限，需要超级管理员处理")
+			return false
+		}
+	}
+	return true
+}
+
+// authorizeRoleChange 检查角色变更权限，失败时已写入响应
+func (h *UserHandler) authorizeRoleChange(c *gin.Context, id uint, role string) bool {
+	if currentID, _ := middleware.GetCurrentUserID(c); currentID == id {
+		response.BadRequest(c, "不能修改自己的角色")
+		return false
+	}
+	if role != "student" && !middleware.IsCurrentUserSuperAdmin(c) {
+		response.Forbidden(c, "授予管理员角色需要超级管理员权限")
+		return false
+	}
+	return true
+}
+
+// authorizeStatusChange 检查状态变更权限，失败时已写入响应
+func (h *UserHandler) authorizeStatusChange(c *gin.Context, id uint) bool {
+	if currentID, _ := middleware.GetCurrentUserID(c); currentID == id {
+		response.BadRequest(c, "不能修改自己的账户状态")
+		return false
+	}
+	return true
+}
+
+// audit 记录用户管理操作
+func (h *UserHandler) audit(c *gin.Context, action string, userID uint, details models.JSONMap) {
+	var operatorID *uint
+	if currentID, exists := middleware.GetCurrentUserID(c); exists {
+		operatorID = &currentID
+	}
+
+	h.auditService.Record(services.AuditEntry{
+		UserID:       operatorID,
+		Action:       action,
+		ResourceType: "user",
+		ResourceID:   &userID,
+		Details:      details,
+	}.WithRequest(c))
+}
+
+// revokeSessions 撤销用户全部会话，使其角色、状态或密码变更立即生效
+func (h *UserHandler) revokeSessions(userID uint) {
+	if err := h.tokenService.RevokeAllSessions(userID); err != nil {
+		logger.Errorf("撤销用户会话失败: user_id=%d, %v", userID, err)
+	}
+}
+
+// parseUserID 解析路径中的用户ID，失败时已写入响应
+func parseUserID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的用户ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// sendUserInviteEmail 发送账户邀请邮件
+func sendUserInviteEmail(mailService *services.MailService, user *models.User, password string) error {
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>账户邀请</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 20px; text-align: center; border-radius: 8px 8px 0 0; }
+        .content { background: #f9f9f9; padding: 20px; border-radius: 0 0 8px 8px; }
+        .code { background: #1890ff; color: white; padding: 10px 20px; font-size: 20px; font-weight: bold; text-align: center; border-radius: 4px; margin: 20px 0; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>🧪 EPI实验室</h1>
+        </div>
+        <div class="content">
+            <p>%s 您好：</p>
+            <p>管理员已为您创建EPI实验室招新平台账户，请访问 <a href="%s">%s</a> 使用以下信息登录：</p>
+            <p>登录邮箱：%s</p>
+            <div class="code">%s</div>
+            <p><strong>首次登录后请立即修改密码。</strong></p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(user.Username), config.GlobalConfig.Server.BaseURL, config.GlobalConfig.Server.BaseURL,
+		html.EscapeString(user.Email), html.EscapeString(password))
+
+	return mailService.Send(user.Email, "EPI实验室账户邀请", htmlBody)
+}
+
+// sendPasswordResetEmail 发送管理员重置密码通知邮件
+func sendPasswordResetEmail(mailService *services.MailService, user *models.User, password string) error {
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>密码已重置</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 20px; text-align: center; border-radius: 8px 8px 0 0; }
+        .content { background: #f9f9f9; padding: 20px; border-radius: 0 0 8px 8px; }
+        .code { background: #1890ff; color: white; padding: 10px 20px; font-size: 20px; font-weight: bold; text-align: center; border-radius: 4px; margin: 20px 0; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>🧪 EPI实验室</h1>
+        </div>
+        <div class="content">
+            <p>%s 您好：</p>
+            <p>管理员已重置您的登录密码，新密码为：</p>
+            <div class="code">%s</div>
+            <p><strong>请登录后立即修改密码。</strong>如非本人申请，请联系管理员。</p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(user.Username), html.EscapeString(password))
+
+	return mailService.Send(user.Email, "EPI实验室账户密码已重置", htmlBody)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCanManageUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	roleManager := models.Role{Name: "role-manager", Permissions: models.StringList{models.PermRoleManage}}
+	revealer := models.Role{Name: "revealer", Permissions: models.StringList{models.PermApplicationReveal}}
+	userManager := models.Role{Name: "user-manager", Permissions: models.StringList{models.PermUserManage}}
+
+	admin := &models.User{Role: middleware.RoleAdmin}
+	adminWithReveal := &models.User{Role: middleware.RoleAdmin, Roles: []models.Role{revealer}}
+	superAdmin := &models.User{Role: middleware.RoleSuperAdmin}
+	studentManager := &models.User{Role: "student", Roles: []models.Role{userManager}}
+
+	tests := []struct {
+		name   string
+		caller *models.User
+		target *models.User
+		want   bool
+	}{
+		{"超级管理员管理管理员", superAdmin, &models.User{Role: middleware.RoleAdmin}, true},
+		{"超级管理员管理拥有角色管理权限的学生", superAdmin, &models.User{Role: "student", Roles: []models.Role{roleManager}}, true},
+		{"管理员管理普通学生", admin, &models.User{Role: "student"}, true},
+		{"管理员不能管理管理员", admin, &models.User{Role: middleware.RoleAdmin}, false},
+		{"管理员不能管理拥有角色管理权限的学生", admin, &models.User{Role: "student", Roles: []models.Role{roleManager}}, false},
+		{"管理员不能管理拥有查看身份权限的学生", admin, &models.User{Role: "student", Roles: []models.Role{revealer}}, false},
+		{"管理员自身拥有相同权限时允许", adminWithReveal, &models.User{Role: "student", Roles: []models.Role{revealer}}, true},
+		{"通过自定义角色管理用户的学生管理普通学生", studentManager, &models.User{Role: "student"}, true},
+		{"通过自定义角色管理用户的学生不能管理权限更多的学生", studentManager, &models.User{Role: "student", Roles: []models.Role{userManager, revealer}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/users/2/reset-password", nil)
+			c.Set("user_role", tt.caller.Role)
+			c.Set("user_permissions", tt.caller.Permissions())
+
+			if got := canManageUser(c, tt.target); got != tt.want {
+				t.Fatalf("canManageUser() = %v, want %v", got, tt.want)
+			}
+			if !tt.want && w.Code != http.StatusForbidden {
+				t.Errorf("状态码 = %d, want %d", w.Code, http.StatusForbidden)
+			}
+			if tt.want && c.Writer.Written() {
+				t.Error("允许时不应写入响应")
+			}
+		})
+	}
+}
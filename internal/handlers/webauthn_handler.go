@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/middleware"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+	"lab-recruitment-platform/pkg/validator"
+)
+
+// WebAuthnHandler 通行密钥处理器
+type WebAuthnHandler struct {
+	userService  *services.UserService
+	webauthn     *services.WebAuthnService
+	twoFactor    *services.TwoFactorService
+	tokenService *services.TokenService
+	auditService *services.AuditService
+}
+
+// NewWebAuthnHandler 创建通行密钥处理器实例
+func NewWebAuthnHandler() *WebAuthnHandler {
+	return &WebAuthnHandler{
+		userService:  services.NewUserService(),
+		webauthn:     services.NewWebAuthnService(),
+		twoFactor:    services.NewTwoFactorService(),
+		tokenService: services.NewTokenService(),
+		auditService: services.NewAuditService(),
+	}
+}
+
+// BeginRegistration 开始注册通行密钥
+// @Summary 开始注册通行密钥
+// @Description 返回 navigator.credentials.create 所需的选项；登录时被要求绑定两步验证的管理员使用预认证令牌调用
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.WebAuthnRegisterBeginRequest false "预认证令牌"
+// @Success 200 {object} response.Response{data=models.WebAuthnCeremonyResponse}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /auth/webauthn/register/begin [post]
+func (h *WebAuthnHandler) BeginRegistration(c *gin.Context) {
+	var req models.WebAuthnRegisterBeginRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "请求参数错误")
+			return
+		}
+	}
+
+	user, ok := h.enrollingUser(c, req.MFAToken)
+	if !ok {
+		return
+	}
+
+	ceremony, err := h.webauthn.BeginRegistration(user)
+	if err != nil {
+		h.respondError(c, err, "开始注册通行密钥失败")
+		return
+	}
+	response.Success(c, ceremony)
+}
+
+// FinishRegistration 完成注册通行密钥
+// @Summary 完成注册通行密钥
+// @Description 提交认证器创建的凭据；通过预认证令牌注册时同时完成登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.WebAuthnRegisterFinishRequest true "注册结果"
+// @Success 200 {object} response.Response{data=models.WebAuthnCredential}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /auth/webauthn/register/finish [post]
+func (h *WebAuthnHandler) FinishRegistration(c *gin.Context) {
+	var req models.WebAuthnRegisterFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	user, ok := h.enrollingUser(c, req.MFAToken)
+	if !ok {
+		return
+	}
+
+	credential, err := h.webauthn.FinishRegistration(user, req.CeremonyID, req.Name, req.Credential)
+	if err != nil {
+		h.respondError(c, err, "注册通行密钥失败")
+		return
+	}
+	h.audit(c, "auth.passkey_register", user.ID, models.JSONMap{
+		"credential_id": credential.ID,
+		"name":          credential.Name,
+	})
+
+	if req.MFAToken != "" {
+		h.twoFactor.DeletePreAuthToken(req.MFAToken)
+		completeLogin(c, h.tokenService, user, gin.H{"credential": credential})
+		return
+	}
+	response.SuccessWithMessage(c, "通行密钥注册成功", credential)
+}
+
+// BeginLogin 开始通行密钥登录
+// @Summary 开始通行密钥登录
+// @Description 返回 navigator.credentials.get 所需的选项；携带预认证令牌时作为第二步验证，否则为无密码登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body models.WebAuthnLoginBeginRequest false "预认证令牌"
+// @Success 200 {object} response.Response{data=models.WebAuthnCeremonyResponse}
+// @Failure 401 {object} response.Response
+// @Router /auth/webauthn/login/begin [post]
+func (h *WebAuthnHandler) BeginLogin(c *gin.Context) {
+	var req models.WebAuthnLoginBeginRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "请求参数错误")
+			return
+		}
+	}
+
+	var user *models.User
+	if req.MFAToken != "" {
+		var ok bool
+		if user, ok = h.verifyingUser(c, req.MFAToken); !ok {
+			return
+		}
+	}
+
+	ceremony, err := h.webauthn.BeginLogin(user)
+	if err != nil {
+		h.respondError(c, err, "开始通行密钥登录失败")
+		return
+	}
+	response.Success(c, ceremony)
+}
+
+// FinishLogin 完成通行密钥登录
+// @Summary 完成通行密钥登录
+// @Description 提交认证器签名的断言，验证通过后返回访问令牌与刷新令牌
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body models.WebAuthnLoginFinishRequest true "登录断言"
+// @Success 200 {object} response.Response{data=gin.H}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /auth/webauthn/login/finish [post]
+func (h *WebAuthnHandler) FinishLogin(c *gin.Context) {
+	var req models.WebAuthnLoginFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	var expectUserID uint
+	if req.MFAToken != "" {
+		user, ok := h.verifyingUser(c, req.MFAToken)
+		if !ok {
+			return
+		}
+		expectUserID = user.ID
+	}
+
+	userID, err := h.webauthn.FinishLogin(req.CeremonyID, expectUserID, req.Credential)
+	if err != nil {
+		h.respondError(c, err, "通行密钥登录失败")
+		return
+	}
+
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil || !user.IsActive() {
+		response.Unauthorized(c, "账户不可用")
+		return
+	}
+
+	method := "passwordless"
+	if req.MFAToken != "" {
+		method = "second_factor"
+		h.twoFactor.DeletePreAuthToken(req.MFAToken)
+	}
+	h.audit(c, "auth.passkey_login", user.ID, models.JSONMap{"method": method})
+	completeLogin(c, h.tokenService, user, nil)
+}
+
+// ListCredentials 获取当前用户的通行密钥
+// @Summary 通行密钥列表
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.WebAuthnCredential}
+// @Router /auth/webauthn/credentials [get]
+func (h *WebAuthnHandler) ListCredentials(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未登录")
+		return
+	}
+
+	credentials, err := h.webauthn.ListCredentials(userID)
+	if err != nil {
+		logger.Errorf("获取通行密钥列表失败: %v", err)
+		response.InternalServerError(c, "获取通行密钥列表失败")
+		return
+	}
+	response.Success(c, credentials)
+}
+
+// RenameCredential 重命名通行密钥
+// @Summary 重命名通行密钥
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "通行密钥ID"
+// @Param request body models.WebAuthnCredentialUpdateRequest true "名称"
+// @Success 200 {object} response.Response{data=models.WebAuthnCredential}
+// @Failure 404 {object} response.Response
+// @Router /auth/webauthn/credentials/{id} [put]
+func (h *WebAuthnHandler) RenameCredential(c *gin.Context) {
+	id, ok := parseCredentialID(c)
+	if !ok {
+		return
+	}
+
+	var req models.WebAuthnCredentialUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if !validator.ValidateRequest(c, &req) {
+		return
+	}
+
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未登录")
+		return
+	}
+
+	credential, err := h.webauthn.RenameCredential(userID, id, req.Name)
+	if err != nil {
+		h.respondError(c, err, "重命名通行密钥失败")
+		return
+	}
+	response.SuccessWithMessage(c, "通行密钥已重命名", credential)
+}
+
+// DeleteCredential 撤销通行密钥
+// @Summary 撤销通行密钥
+// @Description 删除已注册的通行密钥；被强制要求两步验证的账户不能撤销最后一个验证方式
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "通行密钥ID"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /auth/webauthn/credentials/{id} [delete]
+func (h *WebAuthnHandler) DeleteCredential(c *gin.Context) {
+	id, ok := parseCredentialID(c)
+	if !ok {
+		return
+	}
+
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未登录")
+		return
+	}
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil {
+		response.NotFound(c, "用户不存在")
+		return
+	}
+
+	if h.twoFactor.IsRequired(user) {
+		if !h.hasOtherFactor(c, user, id) {
+			return
+		}
+	}
+
+	if err := h.webauthn.DeleteCredential(user.ID, id); err != nil {
+		h.respondError(c, err, "撤销通行密钥失败")
+		return
+	}
+
+	h.audit(c, "auth.passkey_revoke", user.ID, models.JSONMap{"credential_id": id})
+	response.SuccessWithMessage(c, "通行密钥已撤销", nil)
+}
+
+// hasOtherFactor 判断撤销指定通行密钥后用户是否仍有可用的两步验证方式，否则已写入响应
+func (h *WebAuthnHandler) hasOtherFactor(c *gin.Context, user *models.User, credentialID uint) bool {
+	totpEnabled, err := h.twoFactor.IsEnabled(user.ID)
+	if err != nil {
+		logger.Errorf("查询两步验证状态失败: %v", err)
+		response.InternalServerError(c, "撤销通行密钥失败")
+		return false
+	}
+	if totpEnabled {
+		return true
+	}
+
+	credentials, err := h.webauthn.ListCredentials(user.ID)
+	if err != nil {
+		logger.Errorf("获取通行密钥列表失败: %v", err)
+		response.InternalServerError(c, "撤销通行密钥失败")
+		return false
+	}
+	for _, credential := range credentials {
+		if credential.ID != credentialID {
+			return true
+		}
+	}
+
+	response.Forbidden(c, "管理员账户必须保留至少一种两步验证方式")
+	return false
+}
+
+// enrollingUser 获取正在注册通行密钥的用户：优先使用预认证令牌，否则要求已登录
+func (h *WebAuthnHandler) enrollingUser(c *gin.Context, mfaToken string) (*models.User, bool) {
+	if mfaToken != "" {
+		return h.preAuthUser(c, mfaToken, services.MFAPurposeEnroll)
+	}
+
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未登录")
+		return nil, false
+	}
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil {
+		response.NotFound(c, "用户不存在")
+		return nil, false
+	}
+	return user, true
+}
+
+// verifyingUser 获取密码登录后正在进行第二步验证的用户
+func (h *WebAuthnHandler) verifyingUser(c *gin.Context, mfaToken string) (*models.User, bool) {
+	return h.preAuthUser(c, mfaToken, services.MFAPurposeVerify)
+}
+
+// preAuthUser 校验预认证令牌并获取对应用户，失败时已写入响应
+func (h *WebAuthnHandler) preAuthUser(c *gin.Context, mfaToken, purpose string) (*models.User, bool) {
+	userID, err := h.twoFactor.CheckPreAuthToken(mfaToken, purpose)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return nil, false
+	}
+
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil || !user.IsActive() {
+		response.Unauthorized(c, "账户不可用")
+		return nil, false
+	}
+	return user, true
+}
+
+// respondError 将通行密钥服务错误转换为响应
+func (h *WebAuthnHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrWebAuthnDisabled), errors.Is(err, services.ErrWebAuthnNotAllowed):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrWebAuthnCeremonyExpired), errors.Is(err, services.ErrWebAuthnVerifyFailed):
+		response.Unauthorized(c, err.Error())
+	case errors.Is(err, services.ErrWebAuthnCredentialNotFound):
+		response.NotFound(c, err.Error())
+	default:
+		logger.Errorf("%s: %v", message, err)
+		response.InternalServerError(c, message)
+	}
+}
+
+// audit 记录通行密钥相关操作
+func (h *WebAuthnHandler) audit(c *gin.Context, action string, userID uint, details models.JSONMap) {
+	h.auditService.Record(services.AuditEntry{
+		UserID:       &userID,
+		Action:       action,
+		ResourceType: "user",
+		ResourceID:   &userID,
+		Details:      details,
+	}.WithRequest(c))
+}
+
+// parseCredentialID 解析路径中的通行密钥ID
+func parseCredentialID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的通行密钥ID")
+		return 0, false
+	}
+	return uint(id), true
+}
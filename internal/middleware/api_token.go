@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/services"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+)
+
+// 认证方式
+const (
+	AuthMethodSession  = "session"
+	AuthMethodAPIToken = "api_token"
+)
+
+// SessionAuthMiddleware 仅接受登录会话签发的JWT，用于账户安全相关接口，API令牌不能管理账户
+func SessionAuthMiddleware() gin.HandlerFunc {
+	auth := AuthMiddleware()
+	return func(c *gin.Context) {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if services.IsAPIToken(token) {
+			response.Unauthorized(c, "该接口不支持API令牌，请登录后操作")
+			c.Abort()
+			return
+		}
+		auth(c)
+	}
+}
+
+// authenticateAPIToken 校验个人API令牌并将用户信息存储到上下文中
+func authenticateAPIToken(c *gin.Context, token string) {
+	record, user, permissions, err := services.NewAPITokenService().Authenticate(token, c.ClientIP())
+	if err != nil {
+		if !errors.Is(err, services.ErrInvalidAPIToken) {
+			logger.Errorf("校验API令牌失败: %v", err)
+		}
+		response.Unauthorized(c, "无效的API令牌")
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", user.ID)
+	c.Set("user_email", user.Email)
+	c.Set("user_role", user.Role)
+	c.Set("user_permissions", permissions)
+	c.Set("auth_method", AuthMethodAPIToken)
+	c.Set("api_token_id", record.ID)
+
+	c.Next()
+}
+
+// IsAPITokenRequest 判断当前请求是否通过API令牌认证
+func IsAPITokenRequest(c *gin.Context) bool {
+	return c.GetString("auth_method") == AuthMethodAPIToken
+}
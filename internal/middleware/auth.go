@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"strings"
 	"time"
@@ -9,6 +11,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"lab-recruitment-platform/internal/config"
 	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/internal/services"
 	"lab-recruitment-platform/pkg/logger"
 	"lab-recruitment-platform/pkg/response"
 )
@@ -18,16 +21,28 @@ type Claims struct {
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// Permissions 签发时解析的权限列表，角色变更时会撤销用户会话
+	Permissions []string `json:"permissions,omitempty"`
+	// SessionID 所属会话，会话撤销后该会话签发的访问令牌一并失效
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成JWT令牌
-func GenerateToken(user *models.User) (string, error) {
+// GenerateToken 生成JWT访问令牌，sessionID 为令牌所属会话
+func GenerateToken(user *models.User, sessionID string) (string, error) {
+	jti, err := generateTokenID()
+	if err != nil {
+		return "", err
+	}
+
 	claims := Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:      user.ID,
+		Email:       user.Email,
+		Role:        user.Role,
+		Permissions: user.Permissions(),
+		SessionID:   sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.GlobalConfig.JWT.ExpireTime)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -36,15 +51,13 @@ func GenerateToken(user *models.User) (string, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.GlobalConfig.JWT.Secret))
+	return signToken(claims)
 }
 
 // ParseToken 解析JWT令牌
 func ParseToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(config.GlobalConfig.JWT.Secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, verificationKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}))
 
 	if err != nil {
 		return nil, err
@@ -78,6 +91,12 @@ func AuthMiddleware() gin.HandlerFunc {
 		// 提取令牌
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
+		// 个人API令牌
+		if services.IsAPIToken(tokenString) {
+			authenticateAPIToken(c, tokenString)
+			return
+		}
+
 		// 解析令牌
 		claims, err := ParseToken(tokenString)
 		if err != nil {
@@ -94,17 +113,24 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 检查令牌是否已被撤销
+		if isRevoked(claims) {
+			response.Unauthorized(c, "令牌已失效，请重新登录")
+			c.Abort()
+			return
+		}
+
 		// 将用户信息存储到上下文中
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_role", claims.Role)
+		setClaimsContext(c, claims)
+		services.NewTokenService().TouchSession(claims.SessionID, c.ClientIP())
 
 		c.Next()
 	}
 }
 
-// AdminMiddleware 管理员权限中间件
+// AdminMiddleware 管理员权限中间件（包含JWT认证）
 func AdminMiddleware() gin.HandlerFunc {
+	requireAdmin := RequireAdmin()
 	return func(c *gin.Context) {
 		// 先进行JWT认证
 		AuthMiddleware()(c)
@@ -112,24 +138,15 @@ func AdminMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 检查用户角色
-		role, exists := c.Get("user_role")
-		if !exists {
-			response.Unauthorized(c, "用户信息不完整")
-			c.Abort()
-			return
-		}
-
-		if role != "admin" {
-			response.Forbidden(c, "需要管理员权限")
-			c.Abort()
-			return
-		}
-
-		c.Next()
+		requireAdmin(c)
 	}
 }
 
+// SuperAdminMiddleware 超级管理员权限中间件（需在认证中间件之后使用）
+func SuperAdminMiddleware() gin.HandlerFunc {
+	return RequireRole(RoleSuperAdmin)
+}
+
 // OptionalAuthMiddleware 可选认证中间件（不强制要求认证）
 func OptionalAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -167,15 +184,62 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 令牌已撤销，继续处理（不强制要求）
+		if isRevoked(claims) {
+			c.Next()
+			return
+		}
+
 		// 将用户信息存储到上下文中
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_role", claims.Role)
+		setClaimsContext(c, claims)
 
 		c.Next()
 	}
 }
 
+// setClaimsContext 将令牌中的用户信息存储到上下文中
+func setClaimsContext(c *gin.Context, claims *Claims) {
+	permissions := claims.Permissions
+	if permissions == nil {
+		// 兼容未携带权限的旧令牌，按基础角色推导
+		permissions = (&models.User{Role: claims.Role}).Permissions()
+	}
+
+	c.Set("user_id", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("user_role", claims.Role)
+	c.Set("user_permissions", permissions)
+	c.Set("claims", claims)
+	c.Set("auth_method", AuthMethodSession)
+}
+
+// isRevoked 判断令牌是否已被注销或所属会话已撤销
+func isRevoked(claims *Claims) bool {
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	return services.NewTokenService().IsAccessTokenRevoked(claims.UserID, claims.ID, claims.SessionID, issuedAt)
+}
+
+// generateTokenID 生成令牌唯一标识（jti）
+func generateTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GetCurrentClaims 获取当前请求的令牌声明
+func GetCurrentClaims(c *gin.Context) (*Claims, bool) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		return nil, false
+	}
+	return claims.(*Claims), true
+}
+
 // GetCurrentUserID 获取当前用户ID
 func GetCurrentUserID(c *gin.Context) (uint, bool) {
 	userID, exists := c.Get("user_id")
@@ -203,14 +267,39 @@ func GetCurrentUserRole(c *gin.Context) (string, bool) {
 	return role.(string), true
 }
 
+// GetCurrentUserPermissions 获取当前用户权限
+func GetCurrentUserPermissions(c *gin.Context) []string {
+	permissions, exists := c.Get("user_permissions")
+	if !exists {
+		return nil
+	}
+	return permissions.([]string)
+}
+
+// HasPermission 判断当前用户是否具有指定权限
+func HasPermission(c *gin.Context, permission string) bool {
+	for _, p := range GetCurrentUserPermissions(c) {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
 // IsCurrentUserAdmin 判断当前用户是否为管理员
 func IsCurrentUserAdmin(c *gin.Context) bool {
 	role, exists := GetCurrentUserRole(c)
-	return exists && role == "admin"
+	return exists && (role == RoleAdmin || role == RoleSuperAdmin)
+}
+
+// IsCurrentUserSuperAdmin 判断当前用户是否为超级管理员
+func IsCurrentUserSuperAdmin(c *gin.Context) bool {
+	role, exists := GetCurrentUserRole(c)
+	return exists && role == RoleSuperAdmin
 }
 
 // IsCurrentUserStudent 判断当前用户是否为学生
 func IsCurrentUserStudent(c *gin.Context) bool {
 	role, exists := GetCurrentUserRole(c)
-	return exists && role == "student"
+	return exists && role == RoleStudent
 } 
\ No newline at end of file
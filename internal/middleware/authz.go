@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"lab-recruitment-platform/pkg/logger"
+	"lab-recruitment-platform/pkg/response"
+)
+
+// 用户角色
+const (
+	RoleStudent    = "student"
+	RoleAdmin      = "admin"
+	RoleSuperAdmin = "super_admin"
+)
+
+// AdminRoles 具有管理员权限的角色
+var AdminRoles = []string{RoleAdmin, RoleSuperAdmin}
+
+// RequireRole 要求当前用户为指定角色（需在认证中间件之后使用）
+func RequireRole(role string) gin.HandlerFunc {
+	return RequireAnyRole(role)
+}
+
+// RequireAnyRole 要求当前用户为任一指定角色（需在认证中间件之后使用）
+func RequireAnyRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, exists := GetCurrentUserRole(c)
+		if !exists {
+			denyUnauthenticated(c, "required_roles", roles)
+			return
+		}
+
+		// 按角色授权的接口不接受API令牌，API令牌只能访问按权限授权的接口
+		if IsAPITokenRequest(c) {
+			logAuthzDenied(c, "required_roles", roles, "API令牌")
+			response.Forbidden(c, "API令牌无权访问该接口")
+			c.Abort()
+			return
+		}
+
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		logAuthzDenied(c, "required_roles", roles, "角色不符")
+		response.Forbidden(c, forbiddenMessage(roles))
+		c.Abort()
+	}
+}
+
+// RequirePermission 要求当前用户具有指定权限（需在认证中间件之后使用）
+func RequirePermission(permission string) gin.HandlerFunc {
+	return RequireAnyPermission(permission)
+}
+
+// RequireAnyPermission 要求当前用户具有任一指定权限（需在认证中间件之后使用）
+func RequireAnyPermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := GetCurrentUserRole(c); !exists {
+			denyUnauthenticated(c, "required_permissions", permissions)
+			return
+		}
+
+		for _, permission := range permissions {
+			if HasPermission(c, permission) {
+				c.Next()
+				return
+			}
+		}
+
+		logAuthzDenied(c, "required_permissions", permissions, "权限不足")
+		response.Forbidden(c, "权限不足")
+		c.Abort()
+	}
+}
+
+// RequireStaff 要求当前用户为管理员或被分配了至少一项管理权限（需在认证中间件之后使用）
+func RequireStaff() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := GetCurrentUserRole(c); !exists {
+			denyUnauthenticated(c, "required_roles", AdminRoles)
+			return
+		}
+
+		if IsCurrentUserAdmin(c) || len(GetCurrentUserPermissions(c)) > 0 {
+			c.Next()
+			return
+		}
+
+		logAuthzDenied(c, "required_roles", AdminRoles, "无管理权限")
+		response.Forbidden(c, "需要管理员权限")
+		c.Abort()
+	}
+}
+
+// RequireAdmin 要求当前用户具有管理员权限（需在认证中间件之后使用）
+func RequireAdmin() gin.HandlerFunc {
+	return RequireAnyRole(AdminRoles...)
+}
+
+// denyUnauthenticated 拒绝未认证的请求
+func denyUnauthenticated(c *gin.Context, requirement string, required []string) {
+	logAuthzDenied(c, requirement, required, "未认证")
+	response.Unauthorized(c, "用户未登录")
+	c.Abort()
+}
+
+// logAuthzDenied 记录被拒绝的授权决策，requirement 为所需条件的字段名
+func logAuthzDenied(c *gin.Context, requirement string, required []string, reason string) {
+	userID, _ := GetCurrentUserID(c)
+	role, _ := GetCurrentUserRole(c)
+
+	logger.WithFields(logrus.Fields{
+		"user_id":   userID,
+		"role":      role,
+		requirement: strings.Join(required, ","),
+		"method":    c.Request.Method,
+		"path":      c.FullPath(),
+		"client_ip": c.ClientIP(),
+		"reason":    reason,
+	}).Warn("授权拒绝")
+}
+
+// forbiddenMessage 根据所需角色生成拒绝提示
+func forbiddenMessage(roles []string) string {
+	if len(roles) == 1 && roles[0] == RoleSuperAdmin {
+		return "需要超级管理员权限"
+	}
+	for _, role := range roles {
+		if role == RoleStudent {
+			return "无权访问该资源"
+		}
+	}
+	return "需要管理员权限"
+}
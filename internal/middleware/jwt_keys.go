@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/pkg/jwks"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// jwtKey 已加载的签名/验证密钥
+type jwtKey struct {
+	id          string
+	method      jwt.SigningMethod
+	private     crypto.Signer // 仅签发密钥需要
+	public      crypto.PublicKey
+	verifyUntil time.Time // 零值表示一直有效
+}
+
+// usable 判断密钥当前是否仍可用于验证
+func (k *jwtKey) usable(now time.Time) bool {
+	return k.verifyUntil.IsZero() || now.Before(k.verifyUntil)
+}
+
+// jwtKeyring JWT密钥环，active 为空时使用共享密钥以 HS256 签发与验证
+type jwtKeyring struct {
+	active *jwtKey
+	keys   map[string]*jwtKey
+}
+
+var (
+	keyringMu  sync.Mutex
+	jwtKeyRing *jwtKeyring
+)
+
+// InitJWTKeys 按配置加载JWT签名密钥，应在服务启动时调用以尽早发现配置错误
+func InitJWTKeys(cfg *config.JWTConfig) error {
+	ring, err := loadJWTKeyring(cfg)
+	if err != nil {
+		return err
+	}
+
+	keyringMu.Lock()
+	jwtKeyRing = ring
+	keyringMu.Unlock()
+
+	if ring.active == nil {
+		logger.Warn("未配置JWT非对称签名密钥，使用共享密钥以HS256签发令牌")
+	} else {
+		logger.Infof("JWT签名密钥已加载: active=%s, alg=%s, keys=%d", ring.active.id, ring.active.method.Alg(), len(ring.keys))
+	}
+	return nil
+}
+
+// JWKS 返回当前可用于验证的公钥集合
+func JWKS() jwks.Set {
+	set := jwks.Set{Keys: []jwks.JWK{}}
+	ring, err := currentKeyring()
+	if err != nil {
+		logger.Errorf("加载JWT密钥失败: %v", err)
+		return set
+	}
+
+	now := time.Now()
+	for _, key := range ring.keys {
+		if !key.usable(now) {
+			continue
+		}
+		jwk, err := jwks.NewJWK(key.id, key.public)
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// signToken 使用当前签发密钥签名令牌
+func signToken(claims jwt.Claims) (string, error) {
+	ring, err := currentKeyring()
+	if err != nil {
+		return "", err
+	}
+
+	if ring.active == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(config.GlobalConfig.JWT.Secret))
+	}
+
+	token := jwt.NewWithClaims(ring.active.method, claims)
+	token.Header["kid"] = ring.active.id
+	return token.SignedString(ring.active.private)
+}
+
+// verificationKey 根据令牌头部的 kid 与 alg 选择验证密钥
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	ring, err := currentKeyring()
+	if err != nil {
+		return nil, err
+	}
+
+	if ring.active == nil {
+		if token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, fmt.Errorf("不支持的签名算法: %s", token.Method.Alg())
+		}
+		return []byte(config.GlobalConfig.JWT.Secret), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ring.keys[kid]
+	if !ok || !key.usable(time.Now()) {
+		return nil, fmt.Errorf("未知或已停用的签名密钥: %q", kid)
+	}
+	// 算法必须与密钥匹配，防止算法混淆攻击
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("签名算法与密钥不匹配: %s", token.Method.Alg())
+	}
+	return key.public, nil
+}
+
+// currentKeyring 获取密钥环，未初始化时按全局配置加载
+func currentKeyring() (*jwtKeyring, error) {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+
+	if jwtKeyRing == nil {
+		ring, err := loadJWTKeyring(&config.GlobalConfig.JWT)
+		if err != nil {
+			return nil, err
+		}
+		jwtKeyRing = ring
+	}
+	return jwtKeyRing, nil
+}
+
+// loadJWTKeyring 读取配置中的密钥文件
+func loadJWTKeyring(cfg *config.JWTConfig) (*jwtKeyring, error) {
+	ring := &jwtKeyring{keys: make(map[string]*jwtKey, len(cfg.Keys))}
+	for i := range cfg.Keys {
+		key, err := loadJWTKey(&cfg.Keys[i])
+		if err != nil {
+			return nil, fmt.Errorf("加载JWT密钥 %s 失败: %w", cfg.Keys[i].ID, err)
+		}
+		ring.keys[key.id] = key
+	}
+
+	if cfg.ActiveKey != "" {
+		active, ok := ring.keys[cfg.ActiveKey]
+		if !ok || active.private == nil {
+			return nil, fmt.Errorf("当前签发密钥 %s 未配置私钥", cfg.ActiveKey)
+		}
+		if !active.usable(time.Now()) {
+			return nil, fmt.Errorf("当前签发密钥 %s 已过验证截止时间", cfg.ActiveKey)
+		}
+		ring.active = active
+	}
+	return ring, nil
+}
+
+// loadJWTKey 读取单个密钥，配置了私钥时公钥由私钥导出
+func loadJWTKey(cfg *config.JWTKeyConfig) (*jwtKey, error) {
+	key := &jwtKey{id: cfg.ID, verifyUntil: cfg.VerifyUntilTime()}
+
+	if cfg.PrivateKeyFile != "" {
+		data, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if key.private, err = jwks.ParsePrivateKeyPEM(data); err != nil {
+			return nil, err
+		}
+		key.public = key.private.Public()
+	} else {
+		data, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if key.public, err = jwks.ParsePublicKeyPEM(data); err != nil {
+			return nil, err
+		}
+	}
+
+	alg, err := jwks.Algorithm(key.public)
+	if err != nil {
+		return nil, err
+	}
+	switch alg {
+	case jwks.AlgRS256:
+		key.method = jwt.SigningMethodRS256
+	case jwks.AlgEdDSA:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New("不支持的签名算法")
+	}
+	return key, nil
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ApplicationStatusLog 面试申请状态变更记录
+type ApplicationStatusLog struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	ApplicationID uint      `json:"application_id" gorm:"not null;index"`
+	FromStatus    string    `json:"from_status" gorm:"size:20"`
+	ToStatus      string    `json:"to_status" gorm:"size:20;not null;index"`
+	ChangedBy     *uint     `json:"changed_by" gorm:"index"`
+	CreatedAt     time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName 指定表名
+func (ApplicationStatusLog) TableName() string {
+	return "application_status_logs"
+}
+
+// BeforeCreate 创建前的钩子
+func (l *ApplicationStatusLog) BeforeCreate(tx *gorm.DB) error {
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = time.Now()
+	}
+	return nil
+}
+
+// 验证码用途
+const (
+	CodePurposeApplication = "application"
+	CodePurposePrivacy     = "privacy"
+)
+
+// VerificationCodeLog 验证码发送记录（仅保存邮箱的 HMAC，用于转化漏斗统计）
+type VerificationCodeLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	EmailHash string    `json:"-" gorm:"size:64;not null;index"`
+	Purpose   string    `json:"purpose" gorm:"size:20;not null;default:'application';index"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName 指定表名
+func (VerificationCodeLog) TableName() string {
+	return "verification_code_logs"
+}
+
+// BeforeCreate 创建前的钩子
+func (l *VerificationCodeLog) BeforeCreate(tx *gorm.DB) error {
+	l.CreatedAt = time.Now()
+	return nil
+}
+
+// DailyCount 每日数量
+type DailyCount struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+// BreakdownItem 分组统计项
+type BreakdownItem struct {
+	Key         string `json:"key"`
+	Total       int64  `json:"total"`
+	Pending     int64  `json:"pending"`
+	Interviewed int64  `json:"interviewed"`
+	Passed      int64  `json:"passed"`
+	Rejected    int64  `json:"rejected"`
+}
+
+// ConversionFunnel 转化漏斗
+type ConversionFunnel struct {
+	CodesSent    int64 `json:"codes_sent"`
+	Applications int64 `json:"applications"`
+	Interviewed  int64 `json:"interviewed"`
+	Passed       int64 `json:"passed"`
+}
+
+// StatusDuration 状态停留时长
+type StatusDuration struct {
+	Status      string  `json:"status"`
+	MedianHours float64 `json:"median_hours"`
+	Samples     int     `json:"samples"`
+}
+
+// RecruitmentAnalytics 招新数据分析
+type RecruitmentAnalytics struct {
+	From           string                    `json:"from"`
+	To             string                    `json:"to"`
+	Totals         InterviewApplicationStats `json:"totals"`
+	DailySubmitted []DailyCount              `json:"daily_submitted"`
+	ByMajor        []BreakdownItem           `json:"by_major"`
+	ByGrade        []BreakdownItem           `json:"by_grade"`
+	Funnel         ConversionFunnel          `json:"funnel"`
+	TimeInStatus   []StatusDuration          `json:"time_in_status"`
+	GeneratedAt    time.Time                 `json:"generated_at"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIToken 个人API令牌，供脚本与第三方集成调用，权限范围不超过所属用户的权限
+type APIToken struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"index;not null"`
+	Name        string     `json:"name" gorm:"size:100;not null"`
+	TokenPrefix string     `json:"token_prefix" gorm:"size:16;not null"` // 令牌前几位，便于用户辨认
+	TokenHash   string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	Scopes      StringList `json:"scopes" gorm:"type:json"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	LastUsedIP  string     `json:"last_used_ip" gorm:"size:45"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// BeforeCreate 创建前的钩子
+func (t *APIToken) BeforeCreate(tx *gorm.DB) error {
+	t.CreatedAt = time.Now()
+	return nil
+}
+
+// IsUsable 判断令牌是否未撤销且未过期
+func (t *APIToken) IsUsable() bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || time.Now().Before(*t.ExpiresAt))
+}
+
+// APITokenScopes 可授予API令牌的权限，账号与角色管理仅限交互式登录
+var APITokenScopes = []string{
+	PermApplicationRead,
+	PermApplicationDecide,
+	PermApplicationSchedule,
+	PermApplicationDelete,
+	PermOfferManage,
+	PermStatsRead,
+	PermPrivacyManage,
+}
+
+// IsAPITokenScope 判断权限是否可授予API令牌
+func IsAPITokenScope(key string) bool {
+	for _, scope := range APITokenScopes {
+		if scope == key {
+			return true
+		}
+	}
+	return false
+}
+
+// APITokenCreateRequest 创建API令牌请求，expires_in_days 为空表示永不过期
+type APITokenCreateRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=3650"`
+}
+
+// APITokenCreateResponse 创建API令牌响应，明文令牌仅在此返回一次
+type APITokenCreateResponse struct {
+	Token    string    `json:"token"`
+	APIToken *APIToken `json:"api_token"`
+}
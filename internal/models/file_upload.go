@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FileUpload 文件上传模型
+type FileUpload struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	Filename      string         `json:"filename" gorm:"size:255;not null"`
+	OriginalName  string         `json:"original_name" gorm:"size:255;not null"`
+	FilePath      string         `json:"-" gorm:"size:500;not null"`
+	FileSize      int64          `json:"file_size" gorm:"not null"`
+	MimeType      string         `json:"mime_type" gorm:"size:100;not null"`
+	SHA256        string         `json:"sha256" gorm:"column:sha256;size:64;not null;index"`
+	Category      string         `json:"category" gorm:"type:enum('resume','portfolio','other');default:'other';not null"`
+	UploaderID    *uint          `json:"uploader_id" gorm:"index"`
+	UploaderEmail string         `json:"uploader_email" gorm:"size:100;index"`
+	ApplicationID *uint          `json:"application_id" gorm:"index"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName 指定表名
+func (FileUpload) TableName() string {
+	return "file_uploads"
+}
+
+// BeforeCreate 创建前的钩子
+func (f *FileUpload) BeforeCreate(tx *gorm.DB) error {
+	f.CreatedAt = time.Now()
+	f.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate 更新前的钩子
+func (f *FileUpload) BeforeUpdate(tx *gorm.DB) error {
+	f.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsLinked 判断是否已关联到面试申请
+func (f *FileUpload) IsLinked() bool {
+	return f.ApplicationID != nil
+}
+
+// FileUploadResponse 文件上传响应
+type FileUploadResponse struct {
+	ID            uint      `json:"id"`
+	OriginalName  string    `json:"original_name"`
+	FileSize      int64     `json:"file_size"`
+	MimeType      string    `json:"mime_type"`
+	SHA256        string    `json:"sha256"`
+	Category      string    `json:"category"`
+	ApplicationID *uint     `json:"application_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ToResponse 转换为响应格式
+func (f *FileUpload) ToResponse() *FileUploadResponse {
+	return &FileUploadResponse{
+		ID:            f.ID,
+		OriginalName:  f.OriginalName,
+		FileSize:      f.FileSize,
+		MimeType:      f.MimeType,
+		SHA256:        f.SHA256,
+		Category:      f.Category,
+		ApplicationID: f.ApplicationID,
+		CreatedAt:     f.CreatedAt,
+	}
+}
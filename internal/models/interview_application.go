@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -18,9 +19,21 @@ type InterviewApplication struct {
 	InterviewTime    string         `json:"interview_time" gorm:"size:100;not null"`
 	Status           string         `json:"status" gorm:"type:enum('pending','interviewed','passed','rejected');default:'pending';not null;index"`
 	AdminRemarks     string         `json:"admin_remarks" gorm:"type:text"`
+	InterviewAt      *time.Time     `json:"interview_at" gorm:"index"`
+	DurationMinutes  int            `json:"duration_minutes" gorm:"default:30;not null"`
+	Location         string         `json:"location" gorm:"size:200"`
+	InterviewerID    *uint          `json:"interviewer_id" gorm:"index"`
+	ScheduleSequence int            `json:"-" gorm:"default:0;not null"`
+	AnonymizedAt     *time.Time     `json:"anonymized_at" gorm:"index"`
+	WaitlistRank     *int           `json:"waitlist_rank" gorm:"index"`
+	Version          uint           `json:"version" gorm:"default:1;not null"`
 	CreatedAt        time.Time      `json:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at"`
 	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联关系
+	Attachments []FileUpload `json:"attachments,omitempty" gorm:"foreignKey:ApplicationID"`
+	Interviewer *User        `json:"interviewer,omitempty" gorm:"foreignKey:InterviewerID"`
 }
 
 // TableName 指定表名
@@ -61,52 +74,122 @@ func (ia *InterviewApplication) IsRejected() bool {
 	return ia.Status == "rejected"
 }
 
+// ETag 获取用于乐观并发控制的实体标签
+func (ia *InterviewApplication) ETag() string {
+	return fmt.Sprintf(`"%d"`, ia.Version)
+}
+
+// IsWaitlisted 判断是否在候补名单中
+func (ia *InterviewApplication) IsWaitlisted() bool {
+	return ia.IsInterviewed() && ia.WaitlistRank != nil
+}
+
+// IsAnonymized 判断个人信息是否已匿名化
+func (ia *InterviewApplication) IsAnonymized() bool {
+	return ia.AnonymizedAt != nil
+}
+
+// IsScheduled 判断是否已安排面试时间
+func (ia *InterviewApplication) IsScheduled() bool {
+	return ia.InterviewAt != nil
+}
+
+// InterviewEndAt 获取面试结束时间
+func (ia *InterviewApplication) InterviewEndAt() time.Time {
+	if ia.InterviewAt == nil {
+		return time.Time{}
+	}
+	return ia.InterviewAt.Add(time.Duration(ia.DurationMinutes) * time.Minute)
+}
+
 // InterviewApplicationResponse 面试申请响应
 type InterviewApplicationResponse struct {
-	ID            uint      `json:"id"`
-	Name          string    `json:"name"`
-	Email         string    `json:"email"`
-	Phone         string    `json:"phone"`
-	StudentID     string    `json:"student_id"`
-	Major         string    `json:"major"`
-	Grade         string    `json:"grade"`
-	InterviewTime string    `json:"interview_time"`
-	Status        string    `json:"status"`
-	AdminRemarks  string    `json:"admin_remarks"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID              uint       `json:"id"`
+	CandidateCode   string     `json:"candidate_code,omitempty"`
+	Blind           bool       `json:"blind,omitempty"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Phone           string     `json:"phone"`
+	StudentID       string     `json:"student_id"`
+	Major           string     `json:"major"`
+	Grade           string     `json:"grade"`
+	InterviewTime   string     `json:"interview_time"`
+	Status          string     `json:"status"`
+	AdminRemarks    string     `json:"admin_remarks"`
+	InterviewAt     *time.Time `json:"interview_at"`
+	DurationMinutes int        `json:"duration_minutes"`
+	Location        string     `json:"location"`
+	InterviewerID   *uint      `json:"interviewer_id"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	AnonymizedAt    *time.Time `json:"anonymized_at,omitempty"`
+	WaitlistRank    *int       `json:"waitlist_rank,omitempty"`
+	Version         uint       `json:"version"`
+
+	// 关联数据
+	Attachments []FileUploadResponse `json:"attachments,omitempty"`
 }
 
 // ToResponse 转换为响应格式
 func (ia *InterviewApplication) ToResponse() *InterviewApplicationResponse {
-	return &InterviewApplicationResponse{
-		ID:            ia.ID,
-		Name:          ia.Name,
-		Email:         ia.Email,
-		Phone:         ia.Phone,
-		StudentID:     ia.StudentID,
-		Major:         ia.Major,
-		Grade:         ia.Grade,
-		InterviewTime: ia.InterviewTime,
-		Status:        ia.Status,
-		AdminRemarks:  ia.AdminRemarks,
-		CreatedAt:     ia.CreatedAt,
-		UpdatedAt:     ia.UpdatedAt,
+	response := &InterviewApplicationResponse{
+		ID:              ia.ID,
+		Name:            ia.Name,
+		Email:           ia.Email,
+		Phone:           ia.Phone,
+		StudentID:       ia.StudentID,
+		Major:           ia.Major,
+		Grade:           ia.Grade,
+		InterviewTime:   ia.InterviewTime,
+		Status:          ia.Status,
+		AdminRemarks:    ia.AdminRemarks,
+		InterviewAt:     ia.InterviewAt,
+		DurationMinutes: ia.DurationMinutes,
+		Location:        ia.Location,
+		InterviewerID:   ia.InterviewerID,
+		AnonymizedAt:    ia.AnonymizedAt,
+		WaitlistRank:    ia.WaitlistRank,
+		Version:         ia.Version,
+		CreatedAt:       ia.CreatedAt,
+		UpdatedAt:       ia.UpdatedAt,
+	}
+
+	// 回收站中的申请返回删除时间
+	if ia.DeletedAt.Valid {
+		deletedAt := ia.DeletedAt.Time
+		response.DeletedAt = &deletedAt
+	}
+
+	// 如果已加载附件，转换为响应格式
+	for i := range ia.Attachments {
+		response.Attachments = append(response.Attachments, *ia.Attachments[i].ToResponse())
 	}
+
+	return response
 }
 
 // InterviewApplicationUpdateRequest 面试申请更新请求
 type InterviewApplicationUpdateRequest struct {
 	Status       string `json:"status" validate:"required,oneof=pending interviewed passed rejected"`
 	AdminRemarks string `json:"admin_remarks" validate:"omitempty"`
+	Version      *uint  `json:"version" validate:"omitempty"`
+}
+
+// InterviewScheduleRequest 面试安排请求
+type InterviewScheduleRequest struct {
+	InterviewAt     time.Time `json:"interview_at" validate:"required"`
+	DurationMinutes int       `json:"duration_minutes" validate:"omitempty,min=5,max=480"`
+	Location        string    `json:"location" validate:"required,max=200"`
+	InterviewerID   *uint     `json:"interviewer_id" validate:"omitempty"`
 }
 
 // InterviewApplicationListResponse 面试申请列表响应
 type InterviewApplicationListResponse struct {
-	Total int64                           `json:"total"`
-	Page  int                             `json:"page"`
-	Size  int                             `json:"size"`
-	List  []InterviewApplicationResponse  `json:"list"`
+	Total int64                          `json:"total"`
+	Page  int                            `json:"page"`
+	Size  int                            `json:"size"`
+	List  []InterviewApplicationResponse `json:"list"`
 }
 
 // InterviewApplicationStats 面试申请统计
@@ -116,4 +199,4 @@ type InterviewApplicationStats struct {
 	Interviewed int64 `json:"interviewed"`
 	Passed      int64 `json:"passed"`
 	Rejected    int64 `json:"rejected"`
-} 
\ No newline at end of file
+}
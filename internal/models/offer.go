@@ -0,0 +1,134 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Offer 录取通知
+type Offer struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	ApplicationID uint       `json:"application_id" gorm:"not null;index"`
+	Token         string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	Status        string     `json:"status" gorm:"type:enum('pending','accepted','declined','expired');default:'pending';not null;index"`
+	ExpiresAt     time.Time  `json:"expires_at" gorm:"not null;index"`
+	RespondedAt   *time.Time `json:"responded_at"`
+	CreatedBy     *uint      `json:"created_by"`
+	AutoPromoted  bool       `json:"auto_promoted" gorm:"default:false;not null"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// 关联关系
+	Application *InterviewApplication `json:"application,omitempty" gorm:"foreignKey:ApplicationID"`
+}
+
+// TableName 指定表名
+func (Offer) TableName() string {
+	return "offers"
+}
+
+// BeforeCreate 创建前的钩子
+func (o *Offer) BeforeCreate(tx *gorm.DB) error {
+	o.CreatedAt = time.Now()
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate 更新前的钩子
+func (o *Offer) BeforeUpdate(tx *gorm.DB) error {
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsPending 判断是否待答复
+func (o *Offer) IsPending() bool {
+	return o.Status == "pending"
+}
+
+// IsExpired 判断是否已超过答复期限
+func (o *Offer) IsExpired() bool {
+	return time.Now().After(o.ExpiresAt)
+}
+
+// OfferCreateRequest 发放录取通知请求
+type OfferCreateRequest struct {
+	ResponseDays int `json:"response_days" validate:"omitempty,min=1,max=60"`
+}
+
+// WaitlistRequest 设置候补排名请求
+type WaitlistRequest struct {
+	Rank int `json:"rank" validate:"required,min=1"`
+}
+
+// OfferPublicResponse 申请人查看的录取通知
+type OfferPublicResponse struct {
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at"`
+}
+
+// ToPublicResponse 转换为申请人可见的响应格式
+func (o *Offer) ToPublicResponse() *OfferPublicResponse {
+	response := &OfferPublicResponse{
+		Status:      o.Status,
+		ExpiresAt:   o.ExpiresAt,
+		RespondedAt: o.RespondedAt,
+	}
+	if o.Application != nil {
+		response.Name = o.Application.Name
+	}
+	return response
+}
+
+// OfferResponse 管理端查看的录取通知，申请信息使用响应格式以便按盲审规则脱敏
+type OfferResponse struct {
+	ID            uint                          `json:"id"`
+	ApplicationID uint                          `json:"application_id"`
+	Status        string                        `json:"status"`
+	ExpiresAt     time.Time                     `json:"expires_at"`
+	RespondedAt   *time.Time                    `json:"responded_at"`
+	CreatedBy     *uint                         `json:"created_by"`
+	AutoPromoted  bool                          `json:"auto_promoted"`
+	CreatedAt     time.Time                     `json:"created_at"`
+	UpdatedAt     time.Time                     `json:"updated_at"`
+	Application   *InterviewApplicationResponse `json:"application,omitempty"`
+}
+
+// ToResponse 转换为管理端响应格式
+func (o *Offer) ToResponse() *OfferResponse {
+	response := &OfferResponse{
+		ID:            o.ID,
+		ApplicationID: o.ApplicationID,
+		Status:        o.Status,
+		ExpiresAt:     o.ExpiresAt,
+		RespondedAt:   o.RespondedAt,
+		CreatedBy:     o.CreatedBy,
+		AutoPromoted:  o.AutoPromoted,
+		CreatedAt:     o.CreatedAt,
+		UpdatedAt:     o.UpdatedAt,
+	}
+	if o.Application != nil {
+		response.Application = o.Application.ToResponse()
+	}
+	return response
+}
+
+// OfferListResponse 录取通知列表响应
+type OfferListResponse struct {
+	Total int64           `json:"total"`
+	Page  int             `json:"page"`
+	Size  int             `json:"size"`
+	List  []OfferResponse `json:"list"`
+}
+
+// OfferCapacity 录取名额使用情况
+type OfferCapacity struct {
+	Bounded        bool `json:"bounded"`
+	MaxMembers     int  `json:"max_members"`
+	CurrentMembers int  `json:"current_members"`
+	PendingOffers  int  `json:"pending_offers"`
+	AwaitingOffer  int  `json:"awaiting_offer"`
+	Remaining      int  `json:"remaining"`
+}
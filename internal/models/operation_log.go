@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JSONMap 键值对类型，用于JSON存储
+type JSONMap map[string]interface{}
+
+// Value 实现 driver.Valuer 接口
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (m *JSONMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, m)
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	default:
+		return errors.New("cannot scan non-string value into JSONMap")
+	}
+}
+
+// OperationLog 操作日志（审计记录）
+type OperationLog struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       *uint     `json:"user_id" gorm:"index"`
+	Action       string    `json:"action" gorm:"size:100;not null;index"`
+	ResourceType string    `json:"resource_type" gorm:"size:50"`
+	ResourceID   *uint     `json:"resource_id"`
+	Details      JSONMap   `json:"details" gorm:"type:json"`
+	IPAddress    string    `json:"ip_address" gorm:"size:45"`
+	UserAgent    string    `json:"user_agent" gorm:"type:text"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName 指定表名
+func (OperationLog) TableName() string {
+	return "operation_logs"
+}
+
+// BeforeCreate 创建前的钩子
+func (l *OperationLog) BeforeCreate(tx *gorm.DB) error {
+	l.CreatedAt = time.Now()
+	return nil
+}
+
+// OperationLogListResponse 操作日志列表响应
+type OperationLogListResponse struct {
+	Total int64          `json:"total"`
+	Page  int            `json:"page"`
+	Size  int            `json:"size"`
+	List  []OperationLog `json:"list"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PasswordHistory 密码历史，保存用户曾经使用过的密码哈希，用于禁止重复使用
+type PasswordHistory struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"index;not null"`
+	PasswordHash string    `json:"-" gorm:"size:255;not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (PasswordHistory) TableName() string {
+	return "password_histories"
+}
+
+// BeforeCreate 创建前的钩子
+func (h *PasswordHistory) BeforeCreate(tx *gorm.DB) error {
+	h.CreatedAt = time.Now()
+	return nil
+}
+
+// PasswordPolicyResponse 密码策略说明，供前端在设置密码时展示
+type PasswordPolicyResponse struct {
+	MinLength      int      `json:"min_length"`
+	MaxLength      int      `json:"max_length"`
+	RequireUpper   bool     `json:"require_upper"`
+	RequireLower   bool     `json:"require_lower"`
+	RequireDigit   bool     `json:"require_digit"`
+	RequireSymbol  bool     `json:"require_symbol"`
+	MinCharClasses int      `json:"min_char_classes"`
+	ForbidUserInfo bool     `json:"forbid_user_info"`
+	HistoryCount   int      `json:"history_count"`
+	Rules          []string `json:"rules"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EmailLog 系统发出的邮件记录（不保存正文）
+type EmailLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Recipient string    `json:"recipient" gorm:"size:100;not null;index"`
+	Subject   string    `json:"subject" gorm:"size:200;not null"`
+	Status    string    `json:"status" gorm:"type:enum('sent','failed');not null"`
+	Error     string    `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (EmailLog) TableName() string {
+	return "email_logs"
+}
+
+// ErasureRequest 申请人个人数据删除请求
+type ErasureRequest struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Email         string     `json:"email" gorm:"size:100;not null;index"`
+	Reason        string     `json:"reason" gorm:"type:text"`
+	Status        string     `json:"status" gorm:"type:enum('pending','approved','rejected');default:'pending';not null;index"`
+	Mode          string     `json:"mode" gorm:"type:enum('','anonymize','delete');default:'';not null"`
+	ReviewedBy    *uint      `json:"reviewed_by"`
+	ReviewRemarks string     `json:"review_remarks" gorm:"type:text"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ErasureRequest) TableName() string {
+	return "erasure_requests"
+}
+
+// BeforeCreate 创建前的钩子
+func (r *ErasureRequest) BeforeCreate(tx *gorm.DB) error {
+	r.CreatedAt = time.Now()
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsPending 判断是否待审核
+func (r *ErasureRequest) IsPending() bool {
+	return r.Status == "pending"
+}
+
+// ErasureReviewRequest 审核删除请求
+type ErasureReviewRequest struct {
+	Approve bool   `json:"approve"`
+	Mode    string `json:"mode" validate:"omitempty,oneof=anonymize delete"`
+	Remarks string `json:"remarks" validate:"max=500"`
+}
+
+// ErasureRequestListResponse 删除请求列表响应
+type ErasureRequestListResponse struct {
+	Total int64            `json:"total"`
+	Page  int              `json:"page"`
+	Size  int              `json:"size"`
+	List  []ErasureRequest `json:"list"`
+}
+
+// PersonalDataExport 申请人个人数据导出内容
+type PersonalDataExport struct {
+	Email           string                         `json:"email"`
+	ExportedAt      time.Time                      `json:"exported_at"`
+	Applications    []InterviewApplicationResponse `json:"applications"`
+	StatusHistory   []ApplicationStatusLog         `json:"status_history"`
+	Offers          []Offer                        `json:"offers"`
+	PendingUploads  []FileUploadResponse           `json:"pending_uploads"`
+	EmailsSent      []EmailLog                     `json:"emails_sent"`
+	ErasureRequests []ErasureRequest               `json:"erasure_requests"`
+}
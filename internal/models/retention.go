@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// RetentionCandidate 待匿名化的申请（不包含个人信息）
+type RetentionCandidate struct {
+	ApplicationID uint      `json:"application_id"`
+	Status        string    `json:"status"`
+	DecidedAt     time.Time `json:"decided_at"`
+	Deleted       bool      `json:"deleted"`
+}
+
+// RetentionPolicyReport 单条保留策略的执行结果
+type RetentionPolicyReport struct {
+	Status     string               `json:"status"`
+	AfterDays  int                  `json:"after_days"`
+	Cutoff     time.Time            `json:"cutoff"`
+	Count      int                  `json:"count"`
+	Candidates []RetentionCandidate `json:"candidates"`
+}
+
+// RetentionReport 个人信息匿名化报告
+type RetentionReport struct {
+	DryRun      bool                    `json:"dry_run"`
+	Total       int                     `json:"total"`
+	Policies    []RetentionPolicyReport `json:"policies"`
+	GeneratedAt time.Time               `json:"generated_at"`
+}
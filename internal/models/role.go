@@ -0,0 +1,237 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 权限标识
+const (
+	PermApplicationRead     = "application:read"
+	PermApplicationDecide   = "application:decide"
+	PermApplicationSchedule = "application:schedule"
+	PermApplicationDelete   = "application:delete"
+	PermApplicationReveal   = "application:reveal"
+	PermOfferManage         = "offer:manage"
+	PermStatsRead           = "stats:read"
+	PermPrivacyManage       = "privacy:manage"
+	PermUserManage          = "user:manage"
+	PermRoleManage          = "role:manage"
+)
+
+// PermissionInfo 权限说明
+type PermissionInfo struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+}
+
+// AllPermissions 系统支持的全部权限
+var AllPermissions = []PermissionInfo{
+	{Key: PermApplicationRead, Description: "查看申请及附件"},
+	{Key: PermApplicationDecide, Description: "更新申请状态、评语与附件"},
+	{Key: PermApplicationSchedule, Description: "安排面试时间"},
+	{Key: PermApplicationDelete, Description: "删除与恢复申请"},
+	{Key: PermApplicationReveal, Description: "在盲审阶段查看申请人身份"},
+	{Key: PermOfferManage, Description: "发放录取通知与管理候补名单"},
+	{Key: PermStatsRead, Description: "查看统计与招新分析"},
+	{Key: PermPrivacyManage, Description: "处理数据删除请求与数据保留"},
+	{Key: PermUserManage, Description: "管理用户账号"},
+	{Key: PermRoleManage, Description: "管理角色与角色分配"},
+}
+
+// AdminPermissions 管理员账号默认具备的权限（角色管理仅限超级管理员）
+var AdminPermissions = []string{
+	PermApplicationRead,
+	PermApplicationDecide,
+	PermApplicationSchedule,
+	PermApplicationDelete,
+	PermOfferManage,
+	PermStatsRead,
+	PermPrivacyManage,
+	PermUserManage,
+}
+
+// IsValidPermission 判断权限标识是否存在
+func IsValidPermission(key string) bool {
+	for _, p := range AllPermissions {
+		if p.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// StringList 字符串列表类型，用于JSON存储
+type StringList []string
+
+// Value 实现 driver.Valuer 接口
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (l *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return errors.New("cannot scan non-string value into StringList")
+	}
+}
+
+// Role 角色模型，一个角色包含一组权限，用户可被分配多个角色
+type Role struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Name        string     `json:"name" gorm:"uniqueIndex;size:50;not null"`
+	DisplayName string     `json:"display_name" gorm:"size:100"`
+	Description string     `json:"description" gorm:"size:255"`
+	Permissions StringList `json:"permissions" gorm:"type:json"`
+	IsSystem    bool       `json:"is_system" gorm:"default:false;not null"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Role) TableName() string {
+	return "roles"
+}
+
+// BeforeCreate 创建前的钩子
+func (r *Role) BeforeCreate(tx *gorm.DB) error {
+	r.CreatedAt = time.Now()
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate 更新前的钩子
+func (r *Role) BeforeUpdate(tx *gorm.DB) error {
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// DefaultRoles 系统预置角色
+var DefaultRoles = []Role{
+	{
+		Name:        "lab_head",
+		DisplayName: "实验室负责人",
+		Description: "负责录取决策与录取通知",
+		Permissions: StringList{PermApplicationRead, PermApplicationDecide, PermApplicationSchedule, PermOfferManage, PermStatsRead},
+		IsSystem:    true,
+	},
+	{
+		Name:        "interviewer",
+		DisplayName: "面试官",
+		Description: "查看申请并填写面试评语",
+		Permissions: StringList{PermApplicationRead, PermApplicationDecide},
+		IsSystem:    true,
+	},
+	{
+		Name:        "observer",
+		DisplayName: "观察员",
+		Description: "仅查看招新统计",
+		Permissions: StringList{PermStatsRead},
+		IsSystem:    true,
+	},
+}
+
+// RoleCreateRequest 创建角色请求
+type RoleCreateRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=50"`
+	DisplayName string   `json:"display_name" validate:"max=100"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"required,min=1"`
+}
+
+// RoleUpdateRequest 更新角色请求
+type RoleUpdateRequest struct {
+	DisplayName *string  `json:"display_name" validate:"omitempty,max=100"`
+	Description *string  `json:"description" validate:"omitempty,max=255"`
+	Permissions []string `json:"permissions" validate:"omitempty,min=1"`
+}
+
+// UserRolesRequest 分配用户角色请求
+type UserRolesRequest struct {
+	RoleIDs []uint `json:"role_ids"`
+}
+
+// RoleResponse 角色响应
+type RoleResponse struct {
+	ID          uint     `json:"id"`
+	Name        string   `json:"name"`
+	DisplayName string   `json:"display_name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	IsSystem    bool     `json:"is_system"`
+}
+
+// ToResponse 转换为响应格式
+func (r *Role) ToResponse() RoleResponse {
+	permissions := []string(r.Permissions)
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return RoleResponse{
+		ID:          r.ID,
+		Name:        r.Name,
+		DisplayName: r.DisplayName,
+		Description: r.Description,
+		Permissions: permissions,
+		IsSystem:    r.IsSystem,
+	}
+}
+
+// Permissions 汇总用户的全部权限：超级管理员拥有全部权限，管理员拥有默认管理权限，其余来自分配的角色
+func (u *User) Permissions() []string {
+	set := make(map[string]struct{})
+	switch {
+	case u.IsSuperAdmin():
+		for _, p := range AllPermissions {
+			set[p.Key] = struct{}{}
+		}
+	case u.IsAdmin():
+		for _, p := range AdminPermissions {
+			set[p] = struct{}{}
+		}
+	}
+	for _, role := range u.Roles {
+		for _, p := range role.Permissions {
+			set[p] = struct{}{}
+		}
+	}
+
+	permissions := make([]string, 0, len(set))
+	for p := range set {
+		permissions = append(permissions, p)
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+// HasPermission 判断用户是否具有指定权限
+func (u *User) HasPermission(permission string) bool {
+	for _, p := range u.Permissions() {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 会话结束原因
+const (
+	SessionEndLogout       = "logout"        // 用户登出
+	SessionEndRevoked      = "revoked"       // 用户或管理员撤销
+	SessionEndRevokedAll   = "revoked_all"   // 撤销全部会话（修改密码、角色变更等）
+	SessionEndRefreshReuse = "refresh_reuse" // 刷新令牌被重复使用
+	SessionEndDisabled     = "disabled"      // 账户不可用
+)
+
+// UserSession 登录会话记录，会话是否有效以Redis中的会话为准，此表用于展示与审计
+type UserSession struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	SessionID  string     `json:"-" gorm:"size:32;uniqueIndex;not null"`
+	UserID     uint       `json:"user_id" gorm:"index;not null"`
+	UserAgent  string     `json:"user_agent" gorm:"size:500"`
+	Device     string     `json:"device" gorm:"size:100"`
+	IP         string     `json:"ip" gorm:"size:45"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	LastSeenIP string     `json:"last_seen_ip" gorm:"size:45"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"index"`
+	EndedAt    *time.Time `json:"ended_at"`
+	EndReason  string     `json:"end_reason,omitempty" gorm:"size:20"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Current 是否为发起请求的当前会话
+	Current bool `json:"current" gorm:"-"`
+}
+
+// TableName 指定表名
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// BeforeCreate 创建前的钩子
+func (s *UserSession) BeforeCreate(tx *gorm.DB) error {
+	s.CreatedAt = time.Now()
+	return nil
+}
+
+// SessionMeta 创建会话时记录的客户端信息
+type SessionMeta struct {
+	UserAgent string
+	IP        string
+}
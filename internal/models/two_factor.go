@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserTwoFactor 用户两步验证（TOTP）配置
+type UserTwoFactor struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"uniqueIndex;not null"`
+	Secret       string     `json:"-" gorm:"size:64;not null"`
+	Enabled      bool       `json:"enabled" gorm:"default:false;not null"`
+	LastUsedStep int64      `json:"-" gorm:"default:0;not null"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UserTwoFactor) TableName() string {
+	return "user_two_factors"
+}
+
+// BeforeCreate 创建前的钩子
+func (t *UserTwoFactor) BeforeCreate(tx *gorm.DB) error {
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate 更新前的钩子
+func (t *UserTwoFactor) BeforeUpdate(tx *gorm.DB) error {
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+// RecoveryCode 两步验证恢复码，仅保存哈希，每个恢复码只能使用一次
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	CodeHash  string     `json:"-" gorm:"size:64;not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
+
+// BeforeCreate 创建前的钩子
+func (r *RecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	r.CreatedAt = time.Now()
+	return nil
+}
+
+// TwoFactorSetupRequest 开始绑定两步验证请求，登录时被要求绑定的用户需携带预认证令牌
+type TwoFactorSetupRequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+// TwoFactorEnableRequest 确认启用两步验证请求
+type TwoFactorEnableRequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// TwoFactorVerifyRequest 登录第二步验证请求，验证码与恢复码二选一
+type TwoFactorVerifyRequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code" validate:"omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" validate:"omitempty"`
+}
+
+// TwoFactorCodeRequest 需要当前验证码确认的操作请求
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// TwoFactorSetupResponse 绑定两步验证响应
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// TwoFactorStatusResponse 两步验证状态响应
+type TwoFactorStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"`
+	ConfirmedAt            *time.Time `json:"confirmed_at"`
+	RemainingRecoveryCodes int64      `json:"remaining_recovery_codes"`
+}
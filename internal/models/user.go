@@ -8,25 +8,30 @@ import (
 
 // User 用户模型
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Username  string         `json:"username" gorm:"uniqueIndex;size:50;not null" validate:"required,min=3,max=20"`
-	Email     string         `json:"email" gorm:"uniqueIndex;size:100;not null" validate:"required,email"`
-	Password  string         `json:"-" gorm:"size:255;not null" validate:"required,min=6"`
-	Role      string         `json:"role" gorm:"type:enum('student','admin');default:'student';not null"`
-	Avatar    string         `json:"avatar" gorm:"size:255"`
-	Phone     string         `json:"phone" gorm:"size:20"`
-	StudentID string         `json:"student_id" gorm:"size:20"`
-	Major     string         `json:"major" gorm:"size:100"`
-	Grade     string         `json:"grade" gorm:"size:20"`
-	Status    string         `json:"status" gorm:"type:enum('active','inactive');default:'active';not null"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	Username           string         `json:"username" gorm:"uniqueIndex;size:50;not null" validate:"required,min=3,max=20"`
+	Email              string         `json:"email" gorm:"uniqueIndex;size:100;not null" validate:"required,email"`
+	Password           string         `json:"-" gorm:"size:255;not null" validate:"required,min=6"`
+	Role               string         `json:"role" gorm:"type:enum('student','admin','super_admin');default:'student';not null"`
+	Avatar             string         `json:"avatar" gorm:"size:255"`
+	Phone              string         `json:"phone" gorm:"size:20"`
+	StudentID          string         `json:"student_id" gorm:"size:20"`
+	CASPrincipal       *string        `json:"-" gorm:"column:cas_principal;size:64;uniqueIndex"`
+	Major              string         `json:"major" gorm:"size:100"`
+	Grade              string         `json:"grade" gorm:"size:20"`
+	Status             string         `json:"status" gorm:"type:enum('active','inactive','pending');default:'active';not null"`
+	CalendarToken      string         `json:"-" gorm:"size:64;index"`
+	EmailVerifiedAt    *time.Time     `json:"email_verified_at"`
+	VerificationSentAt *time.Time     `json:"-"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// 关联关系
-	Applications []Application `json:"applications,omitempty" gorm:"foreignKey:UserID"`
-	Labs         []Lab         `json:"labs,omitempty" gorm:"foreignKey:CreatedBy"`
+	Applications  []Application  `json:"applications,omitempty" gorm:"foreignKey:UserID"`
+	Labs          []Lab          `json:"labs,omitempty" gorm:"foreignKey:CreatedBy"`
 	Notifications []Notification `json:"notifications,omitempty" gorm:"foreignKey:UserID"`
+	Roles         []Role         `json:"roles,omitempty" gorm:"many2many:user_roles"`
 }
 
 // TableName 指定表名
@@ -47,9 +52,14 @@ func (u *User) BeforeUpdate(tx *gorm.DB) error {
 	return nil
 }
 
-// IsAdmin 判断是否为管理员
+// IsAdmin 判断是否为管理员（超级管理员同样具有管理员权限）
 func (u *User) IsAdmin() bool {
-	return u.Role == "admin"
+	return u.Role == "admin" || u.Role == "super_admin"
+}
+
+// IsSuperAdmin 判断是否为超级管理员
+func (u *User) IsSuperAdmin() bool {
+	return u.Role == "super_admin"
 }
 
 // IsStudent 判断是否为学生
@@ -62,7 +72,15 @@ func (u *User) IsActive() bool {
 	return u.Status == "active"
 }
 
+// IsCASLinked 判断账户是否已关联CAS统一身份认证，关联后学号以CAS为准
+func (u *User) IsCASLinked() bool {
+	return u.CASPrincipal != nil && *u.CASPrincipal != ""
+}
 
+// IsPendingVerification 判断是否为等待邮箱验证的注册账户（验证通过后转为 active）
+func (u *User) IsPendingVerification() bool {
+	return u.Status == "pending"
+}
 
 // UserLoginRequest 用户登录请求
 type UserLoginRequest struct {
@@ -70,6 +88,23 @@ type UserLoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// UserRegisterRequest 学生注册请求
+type UserRegisterRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=20"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// VerifyEmailRequest 邮箱验证请求
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ResendVerificationRequest 重新发送验证邮件请求
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
 // UserUpdateRequest 用户更新请求
 type UserUpdateRequest struct {
 	Username  string `json:"username" validate:"omitempty,min=3,max=20"`
@@ -80,34 +115,82 @@ type UserUpdateRequest struct {
 	Avatar    string `json:"avatar" validate:"omitempty"`
 }
 
+// UserCreateRequest 管理员创建用户请求，未填写密码时生成临时密码
+type UserCreateRequest struct {
+	Username  string `json:"username" validate:"required,min=3,max=20"`
+	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"omitempty"`
+	Role      string `json:"role" validate:"required,oneof=student admin super_admin"`
+	Phone     string `json:"phone" validate:"omitempty,len=11"`
+	StudentID string `json:"student_id" validate:"omitempty"`
+	Major     string `json:"major" validate:"omitempty"`
+	Grade     string `json:"grade" validate:"omitempty"`
+}
+
+// UserAdminUpdateRequest 管理员更新用户请求
+type UserAdminUpdateRequest struct {
+	UserUpdateRequest
+	Role   string `json:"role" validate:"omitempty,oneof=student admin super_admin"`
+	Status string `json:"status" validate:"omitempty,oneof=active inactive"`
+}
+
+// UserResetPasswordRequest 管理员重置密码请求，未填写新密码时生成临时密码并邮件通知
+type UserResetPasswordRequest struct {
+	NewPassword string `json:"new_password" validate:"omitempty"`
+}
+
+// UserStatusRequest 更新用户状态请求
+type UserStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=active inactive"`
+}
+
+// UserListResponse 用户列表响应
+type UserListResponse struct {
+	Total int64          `json:"total"`
+	Page  int            `json:"page"`
+	Size  int            `json:"size"`
+	List  []UserResponse `json:"list"`
+}
+
 // UserResponse 用户响应
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	Avatar    string    `json:"avatar"`
-	Phone     string    `json:"phone"`
-	StudentID string    `json:"student_id"`
-	Major     string    `json:"major"`
-	Grade     string    `json:"grade"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	ID              uint           `json:"id"`
+	Username        string         `json:"username"`
+	Email           string         `json:"email"`
+	Role            string         `json:"role"`
+	Avatar          string         `json:"avatar"`
+	Phone           string         `json:"phone"`
+	StudentID       string         `json:"student_id"`
+	Major           string         `json:"major"`
+	Grade           string         `json:"grade"`
+	Status          string         `json:"status"`
+	EmailVerifiedAt *time.Time     `json:"email_verified_at"`
+	Roles           []RoleResponse `json:"roles"`
+	Permissions     []string       `json:"permissions"`
+	CreatedAt       time.Time      `json:"created_at"`
 }
 
 // ToResponse 转换为响应格式
 func (u *User) ToResponse() *UserResponse {
+	roles := make([]RoleResponse, 0, len(u.Roles))
+	for i := range u.Roles {
+		roles = append(roles, u.Roles[i].ToResponse())
+	}
+
 	return &UserResponse{
-		ID:        u.ID,
-		Username:  u.Username,
-		Email:     u.Email,
-		Role:      u.Role,
-		Avatar:    u.Avatar,
-		Phone:     u.Phone,
-		StudentID: u.StudentID,
-		Major:     u.Major,
-		Grade:     u.Grade,
-		Status:    u.Status,
-		CreatedAt: u.CreatedAt,
+		ID:              u.ID,
+		Username:        u.Username,
+		Email:           u.Email,
+		Role:            u.Role,
+		Avatar:          u.Avatar,
+		Phone:           u.Phone,
+		StudentID:       u.StudentID,
+		Major:           u.Major,
+		Grade:           u.Grade,
+		Status:          u.Status,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		Roles:           roles,
+		Permissions:     u.Permissions(),
+		CreatedAt:       u.CreatedAt,
 	}
-} 
\ No newline at end of file
+}
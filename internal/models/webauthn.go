@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebAuthnCredential 用户注册的通行密钥（硬件安全密钥或平台通行密钥）
+type WebAuthnCredential struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          uint       `json:"user_id" gorm:"index;not null"`
+	Name            string     `json:"name" gorm:"size:100;not null"`
+	CredentialID    string     `json:"-" gorm:"size:255;uniqueIndex;not null"` // base64url 编码
+	PublicKey       []byte     `json:"-" gorm:"type:blob;not null"`
+	AttestationType string     `json:"-" gorm:"size:32"`
+	Transports      StringList `json:"transports" gorm:"type:json"`
+	AAGUID          string     `json:"aaguid" gorm:"size:36"`
+	SignCount       uint32     `json:"-" gorm:"default:0;not null"`
+	BackupEligible  bool       `json:"backup_eligible" gorm:"default:false;not null"`
+	BackupState     bool       `json:"backup_state" gorm:"default:false;not null"`
+	CloneWarning    bool       `json:"clone_warning" gorm:"default:false;not null"`
+	LastUsedAt      *time.Time `json:"last_used_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+// BeforeCreate 创建前的钩子
+func (w *WebAuthnCredential) BeforeCreate(tx *gorm.DB) error {
+	w.CreatedAt = time.Now()
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeUpdate 更新前的钩子
+func (w *WebAuthnCredential) BeforeUpdate(tx *gorm.DB) error {
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// WebAuthnRegisterBeginRequest 开始注册通行密钥请求，登录时被要求绑定的管理员需携带预认证令牌
+type WebAuthnRegisterBeginRequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+// WebAuthnRegisterFinishRequest 完成注册通行密钥请求，credential 为 navigator.credentials.create 的返回结果
+type WebAuthnRegisterFinishRequest struct {
+	MFAToken   string          `json:"mfa_token"`
+	CeremonyID string          `json:"ceremony_id" validate:"required"`
+	Name       string          `json:"name" validate:"max=100"`
+	Credential json.RawMessage `json:"credential" validate:"required"`
+}
+
+// WebAuthnLoginBeginRequest 开始通行密钥登录请求
+// 携带预认证令牌时作为密码登录后的第二步验证，否则为无密码登录
+type WebAuthnLoginBeginRequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+// WebAuthnLoginFinishRequest 完成通行密钥登录请求，credential 为 navigator.credentials.get 的返回结果
+type WebAuthnLoginFinishRequest struct {
+	MFAToken   string          `json:"mfa_token"`
+	CeremonyID string          `json:"ceremony_id" validate:"required"`
+	Credential json.RawMessage `json:"credential" validate:"required"`
+}
+
+// WebAuthnCeremonyResponse 通行密钥流程开始响应，options 需原样传给 navigator.credentials
+type WebAuthnCeremonyResponse struct {
+	CeremonyID string      `json:"ceremony_id"`
+	Options    interface{} `json:"options"`
+}
+
+// WebAuthnCredentialUpdateRequest 重命名通行密钥请求
+type WebAuthnCredentialUpdateRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// lockPrefix 分布式锁键前缀，避免多实例重复执行同一任务
+const lockPrefix = "scheduler:lock:"
+
+// JobFunc 定时任务函数
+type JobFunc func(ctx context.Context) error
+
+// job 定时任务
+type job struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+}
+
+// Scheduler 定时任务调度器
+type Scheduler struct {
+	jobs   []job
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New 创建调度器
+func New() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Register 注册定时任务，interval 为执行间隔
+func (s *Scheduler) Register(name string, interval time.Duration, fn JobFunc) {
+	if interval <= 0 {
+		logger.Warnf("定时任务 %s 的执行间隔无效，已跳过", name)
+		return
+	}
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: fn})
+}
+
+// Start 启动所有定时任务
+func (s *Scheduler) Start() {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(j)
+		logger.Infof("定时任务已启动: %s, 间隔: %v", j.name, j.interval)
+	}
+}
+
+// Stop 停止所有定时任务并等待正在执行的任务结束
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// loop 按间隔循环执行任务
+func (s *Scheduler) loop(j job) {
+	defer s.wg.Done()
+
+	// 启动后稍作延迟再首次执行，避免与服务初始化争抢资源
+	timer := time.NewTimer(time.Minute)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-timer.C:
+			s.execute(j)
+			timer.Reset(j.interval)
+		}
+	}
+}
+
+// execute 获取锁后执行一次任务
+func (s *Scheduler) execute(j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("定时任务 %s 发生panic: %v", j.name, r)
+		}
+	}()
+
+	if !s.acquire(j) {
+		logger.Debugf("定时任务 %s 正由其他实例执行，跳过", j.name)
+		return
+	}
+
+	start := time.Now()
+	if err := j.run(s.ctx); err != nil {
+		logger.Errorf("定时任务 %s 执行失败: %v", j.name, err)
+		return
+	}
+	logger.Infof("定时任务 %s 执行完成，耗时: %v", j.name, time.Since(start))
+}
+
+// acquire 通过Redis获取任务锁，Redis不可用时直接执行
+func (s *Scheduler) acquire(j job) bool {
+	client := config.GetRedisClient()
+	if client == nil {
+		return true
+	}
+
+	ok, err := client.SetNX(s.ctx, lockPrefix+j.name, time.Now().Unix(), j.interval/2).Result()
+	if err != nil {
+		logger.Warnf("获取定时任务锁失败: %v", err)
+		return true
+	}
+	return ok
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+const (
+	// analyticsCacheTTL 统计结果缓存时间
+	analyticsCacheTTL = 2 * time.Minute
+	// analyticsCachePrefix 统计结果缓存键前缀
+	analyticsCachePrefix = "analytics:recruitment:"
+	// dateLayout 日期格式
+	dateLayout = "2006-01-02"
+)
+
+// AnalyticsFilter 统计时间范围（From 含，To 不含）
+type AnalyticsFilter struct {
+	From time.Time
+	To   time.Time
+}
+
+// NewAnalyticsFilter 根据日期字符串创建统计范围，默认最近30天
+func NewAnalyticsFilter(from, to string) (*AnalyticsFilter, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	filter := &AnalyticsFilter{
+		From: today.AddDate(0, 0, -29),
+		To:   today.AddDate(0, 0, 1),
+	}
+
+	if to != "" {
+		t, err := time.ParseInLocation(dateLayout, to, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("结束日期格式错误，应为%s", dateLayout)
+		}
+		filter.To = t.AddDate(0, 0, 1)
+		if from == "" {
+			filter.From = t.AddDate(0, 0, -29)
+		}
+	}
+
+	if from != "" {
+		f, err := time.ParseInLocation(dateLayout, from, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("开始日期格式错误，应为%s", dateLayout)
+		}
+		filter.From = f
+	}
+
+	if !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("开始日期不能晚于结束日期")
+	}
+	if filter.To.Sub(filter.From) > 366*24*time.Hour {
+		return nil, fmt.Errorf("统计范围不能超过一年")
+	}
+
+	return filter, nil
+}
+
+// AnalyticsService 招新数据分析服务
+type AnalyticsService struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsService 创建数据分析服务实例
+func NewAnalyticsService() *AnalyticsService {
+	return &AnalyticsService{
+		db: config.GetDB(),
+	}
+}
+
+// RecordCodeSent 记录验证码发送及用途（只保存邮箱的 HMAC）
+func (s *AnalyticsService) RecordCodeSent(email, purpose string) {
+	if err := s.db.Create(&models.VerificationCodeLog{EmailHash: codeLogEmailHash(email), Purpose: purpose}).Error; err != nil {
+		logger.Warnf("记录验证码发送失败: %v", err)
+	}
+}
+
+// codeLogEmailHash 计算验证码记录中的邮箱哈希，使用服务端密钥防止以候选邮箱字典反推
+func codeLogEmailHash(email string) string {
+	mac := hmac.New(sha256.New, config.GlobalConfig.JWT.DeriveKey("verification-code-log"))
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GetRecruitmentAnalytics 获取招新数据分析，结果短时间缓存在Redis中
+func (s *AnalyticsService) GetRecruitmentAnalytics(filter *AnalyticsFilter) (*models.RecruitmentAnalytics, error) {
+	ctx := context.Background()
+	cacheKey := analyticsCachePrefix + filter.From.Format(dateLayout) + ":" + filter.To.Format(dateLayout)
+
+	if cached, err := config.GetCache(ctx, cacheKey); err == nil {
+		var analytics models.RecruitmentAnalytics
+		if err := json.Unmarshal([]byte(cached), &analytics); err == nil {
+			return &analytics, nil
+		}
+	}
+
+	analytics, err := s.buildAnalytics(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(analytics); err == nil {
+		if err := config.SetCache(ctx, cacheKey, data, analyticsCacheTTL); err != nil {
+			logger.Warnf("缓存统计结果失败: %v", err)
+		}
+	}
+
+	return analytics, nil
+}
+
+// buildAnalytics 执行统计查询
+func (s *AnalyticsService) buildAnalytics(filter *AnalyticsFilter) (*models.RecruitmentAnalytics, error) {
+	analytics := &models.RecruitmentAnalytics{
+		From:        filter.From.Format(dateLayout),
+		To:          filter.To.AddDate(0, 0, -1).Format(dateLayout),
+		GeneratedAt: time.Now(),
+	}
+
+	totals, err := countByStatus(s.applications(filter))
+	if err != nil {
+		return nil, fmt.Errorf("统计状态数量失败: %w", err)
+	}
+	analytics.Totals = *totals
+
+	if analytics.DailySubmitted, err = s.dailySubmitted(filter); err != nil {
+		return nil, fmt.Errorf("统计每日申请数失败: %w", err)
+	}
+	if analytics.ByMajor, err = s.breakdown(filter, "major"); err != nil {
+		return nil, fmt.Errorf("按专业统计失败: %w", err)
+	}
+	if analytics.ByGrade, err = s.breakdown(filter, "grade"); err != nil {
+		return nil, fmt.Errorf("按年级统计失败: %w", err)
+	}
+	if err := s.funnel(filter, totals, &analytics.Funnel); err != nil {
+		return nil, fmt.Errorf("统计转化漏斗失败: %w", err)
+	}
+	if analytics.TimeInStatus, err = s.timeInStatus(filter); err != nil {
+		return nil, fmt.Errorf("统计状态停留时长失败: %w", err)
+	}
+
+	return analytics, nil
+}
+
+// applications 返回时间范围内的申请查询
+func (s *AnalyticsService) applications(filter *AnalyticsFilter) *gorm.DB {
+	return s.db.Model(&models.InterviewApplication{}).
+		Where("created_at >= ? AND created_at < ?", filter.From, filter.To)
+}
+
+// dailySubmitted 每日提交数量，缺失日期补零
+func (s *AnalyticsService) dailySubmitted(filter *AnalyticsFilter) ([]models.DailyCount, error) {
+	var rows []models.DailyCount
+	if err := s.applications(filter).
+		Select("DATE_FORMAT(created_at, '%Y-%m-%d') AS date, COUNT(*) AS count").
+		Group("date").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Date] = row.Count
+	}
+
+	series := make([]models.DailyCount, 0)
+	for day := filter.From; day.Before(filter.To); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		series = append(series, models.DailyCount{Date: date, Count: counts[date]})
+	}
+
+	return series, nil
+}
+
+// breakdown 按字段分组统计各状态数量
+func (s *AnalyticsService) breakdown(filter *AnalyticsFilter, column string) ([]models.BreakdownItem, error) {
+	var items []models.BreakdownItem
+	err := s.applications(filter).
+		Select(column + " AS `key`, COUNT(*) AS total, " +
+			"SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END) AS pending, " +
+			"SUM(CASE WHEN status = 'interviewed' THEN 1 ELSE 0 END) AS interviewed, " +
+			"SUM(CASE WHEN status = 'passed' THEN 1 ELSE 0 END) AS passed, " +
+			"SUM(CASE WHEN status = 'rejected' THEN 1 ELSE 0 END) AS rejected").
+		Group(column).
+		Order("total DESC").
+		Scan(&items).Error
+	return items, err
+}
+
+// funnel 转化漏斗：发送验证码 → 提交申请 → 参加面试 → 通过
+func (s *AnalyticsService) funnel(filter *AnalyticsFilter, totals *models.InterviewApplicationStats, funnel *models.ConversionFunnel) error {
+	// 只统计申请验证码，数据导出与删除请求的验证码不属于招新转化
+	if err := s.db.Model(&models.VerificationCodeLog{}).
+		Where("purpose = ? AND created_at >= ? AND created_at < ?", models.CodePurposeApplication, filter.From, filter.To).
+		Distinct("email_hash").
+		Count(&funnel.CodesSent).Error; err != nil {
+		return err
+	}
+
+	// 当前状态为已面试/通过，或曾进入已面试状态（之后被拒绝）的申请
+	interviewedLogs := s.db.Model(&models.ApplicationStatusLog{}).
+		Select("application_id").
+		Where("to_status = ?", "interviewed")
+	if err := s.applications(filter).
+		Where("status IN ? OR id IN (?)", []string{"interviewed", "passed"}, interviewedLogs).
+		Count(&funnel.Interviewed).Error; err != nil {
+		return err
+	}
+
+	funnel.Applications = totals.Total
+	funnel.Passed = totals.Passed
+	return nil
+}
+
+// timeInStatus 计算各状态停留时长中位数（仅统计已离开该状态的记录）
+func (s *AnalyticsService) timeInStatus(filter *AnalyticsFilter) ([]models.StatusDuration, error) {
+	var logs []models.ApplicationStatusLog
+	if err := s.db.Where("application_id IN (?)", s.applications(filter).Select("id")).
+		Order("application_id ASC, created_at ASC, id ASC").
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	durations := make(map[string][]float64)
+	for i := 0; i+1 < len(logs); i++ {
+		current, next := logs[i], logs[i+1]
+		if current.ApplicationID != next.ApplicationID {
+			continue
+		}
+		hours := next.CreatedAt.Sub(current.CreatedAt).Hours()
+		durations[current.ToStatus] = append(durations[current.ToStatus], hours)
+	}
+
+	result := make([]models.StatusDuration, 0, len(durations))
+	for _, status := range []string{"pending", "interviewed", "passed", "rejected"} {
+		values, ok := durations[status]
+		if !ok {
+			continue
+		}
+		result = append(result, models.StatusDuration{
+			Status:      status,
+			MedianHours: median(values),
+			Samples:     len(values),
+		})
+	}
+
+	return result, nil
+}
+
+// median 计算中位数
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
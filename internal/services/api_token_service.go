@@ -0,0 +1,186 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// APITokenPrefix API令牌前缀，用于与JWT区分
+const APITokenPrefix = "lrp_"
+
+// API令牌参数
+const (
+	apiTokenDisplayLen    = 12 // 保存用于辨认的前缀长度
+	maxAPITokensPerUser   = 20
+	apiTokenTouchInterval = time.Minute
+)
+
+var (
+	// ErrInvalidAPIToken API令牌无效
+	ErrInvalidAPIToken = errors.New("API令牌无效、已过期或已撤销")
+	// ErrAPITokenNotFound API令牌不存在
+	ErrAPITokenNotFound = errors.New("API令牌不存在")
+	// ErrInvalidAPITokenScope 权限范围无效或超出账户权限
+	ErrInvalidAPITokenScope = errors.New("权限范围无效或超出账户权限")
+	// ErrTooManyAPITokens API令牌数量已达上限
+	ErrTooManyAPITokens = errors.New("API令牌数量已达上限，请先撤销不再使用的令牌")
+)
+
+// APITokenService 个人API令牌服务
+type APITokenService struct {
+	db *gorm.DB
+}
+
+// NewAPITokenService 创建API令牌服务实例
+func NewAPITokenService() *APITokenService {
+	return &APITokenService{
+		db: config.GetDB(),
+	}
+}
+
+// IsAPIToken 判断凭据是否为API令牌
+func IsAPIToken(credential string) bool {
+	return strings.HasPrefix(credential, APITokenPrefix)
+}
+
+// CreateToken 为用户创建API令牌，权限范围必须是用户当前拥有的权限，返回记录与明文令牌
+func (s *APITokenService) CreateToken(user *models.User, req *models.APITokenCreateRequest) (*models.APIToken, string, error) {
+	scopes, err := normalizeScopes(user, req.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var count int64
+	if err := s.db.Model(&models.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", user.ID).
+		Count(&count).Error; err != nil {
+		return nil, "", err
+	}
+	if count >= maxAPITokensPerUser {
+		return nil, "", ErrTooManyAPITokens
+	}
+
+	secret, err := generateSecureToken()
+	if err != nil {
+		return nil, "", err
+	}
+	token := APITokenPrefix + secret
+
+	record := &models.APIToken{
+		UserID:      user.ID,
+		Name:        strings.TrimSpace(req.Name),
+		TokenPrefix: token[:apiTokenDisplayLen],
+		TokenHash:   hashToken(token),
+		Scopes:      scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		record.ExpiresAt = &expiresAt
+	}
+	if err := s.db.Create(record).Error; err != nil {
+		return nil, "", fmt.Errorf("保存API令牌失败: %w", err)
+	}
+
+	logger.Infof("用户创建API令牌: user_id=%d, token_id=%d, scopes=%v", user.ID, record.ID, scopes)
+	return record, token, nil
+}
+
+// ListTokens 获取用户的API令牌（含已撤销与已过期的）
+func (s *APITokenService) ListTokens(userID uint) ([]models.APIToken, error) {
+	var tokens []models.APIToken
+	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// RevokeToken 撤销用户的API令牌
+func (s *APITokenService) RevokeToken(userID, id uint) error {
+	result := s.db.Model(&models.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPITokenNotFound
+	}
+	return nil
+}
+
+// RevokeAllTokens 撤销用户的全部API令牌，返回撤销数量
+func (s *APITokenService) RevokeAllTokens(userID uint) (int64, error) {
+	result := s.db.Model(&models.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// Authenticate 校验API令牌，返回令牌记录、所属用户及实际生效的权限（令牌范围与用户当前权限的交集）
+func (s *APITokenService) Authenticate(token, ip string) (*models.APIToken, *models.User, []string, error) {
+	var record models.APIToken
+	if err := s.db.Where("token_hash = ?", hashToken(token)).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil, ErrInvalidAPIToken
+		}
+		return nil, nil, nil, err
+	}
+	if !record.IsUsable() {
+		return nil, nil, nil, ErrInvalidAPIToken
+	}
+
+	var user models.User
+	if err := s.db.Preload("Roles").First(&user, record.UserID).Error; err != nil || !user.IsActive() {
+		return nil, nil, nil, ErrInvalidAPIToken
+	}
+
+	granted := make(map[string]bool)
+	for _, permission := range user.Permissions() {
+		granted[permission] = true
+	}
+	permissions := make([]string, 0, len(record.Scopes))
+	for _, scope := range record.Scopes {
+		if granted[scope] {
+			permissions = append(permissions, scope)
+		}
+	}
+
+	// 最近使用时间每分钟至多更新一次
+	now := time.Now()
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= apiTokenTouchInterval {
+		if err := s.db.Model(&record).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		}).Error; err != nil {
+			logger.Warnf("更新API令牌使用记录失败: %v", err)
+		}
+	}
+	return &record, &user, permissions, nil
+}
+
+// normalizeScopes 校验并去重权限范围
+func normalizeScopes(user *models.User, scopes []string) (models.StringList, error) {
+	seen := make(map[string]bool, len(scopes))
+	result := make(models.StringList, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !models.IsAPITokenScope(scope) || !user.HasPermission(scope) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAPITokenScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	if len(result) == 0 {
+		return nil, ErrInvalidAPITokenScope
+	}
+	return result, nil
+}
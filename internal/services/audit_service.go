@@ -0,0 +1,85 @@
+package services
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// AuditEntry 审计记录内容
+type AuditEntry struct {
+	UserID       *uint
+	Action       string
+	ResourceType string
+	ResourceID   *uint
+	Details      models.JSONMap
+	IPAddress    string
+	UserAgent    string
+}
+
+// WithRequest 补充请求来源信息
+func (e AuditEntry) WithRequest(c *gin.Context) AuditEntry {
+	if c != nil {
+		e.IPAddress = c.ClientIP()
+		e.UserAgent = c.Request.UserAgent()
+	}
+	return e
+}
+
+// AuditService 审计日志服务
+type AuditService struct {
+	db *gorm.DB
+}
+
+// NewAuditService 创建审计日志服务实例
+func NewAuditService() *AuditService {
+	return &AuditService{
+		db: config.GetDB(),
+	}
+}
+
+// Record 写入审计记录，失败时只记录日志不影响业务流程
+func (s *AuditService) Record(entry AuditEntry) {
+	log := &models.OperationLog{
+		UserID:       entry.UserID,
+		Action:       entry.Action,
+		ResourceType: entry.ResourceType,
+		ResourceID:   entry.ResourceID,
+		Details:      entry.Details,
+		IPAddress:    entry.IPAddress,
+		UserAgent:    entry.UserAgent,
+	}
+
+	if err := s.db.Create(log).Error; err != nil {
+		logger.Errorf("写入审计记录失败: action=%s, err=%v", entry.Action, err)
+	}
+}
+
+// ListLogs 获取审计记录列表
+func (s *AuditService) ListLogs(page, size int, action string) (*models.OperationLogListResponse, error) {
+	var logs []models.OperationLog
+	var total int64
+
+	query := s.db.Model(&models.OperationLog{})
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Offset(offset).Limit(size).Order("created_at DESC").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.OperationLogListResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		List:  logs,
+	}, nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+)
+
+// ErrRevealForbidden 当前用户没有查看盲审身份信息的权限
+var ErrRevealForbidden = errors.New("盲审阶段需要身份查看权限")
+
+// BlindReviewService 盲审服务
+type BlindReviewService struct {
+	cfg          *config.BlindReviewConfig
+	secret       []byte
+	userService  *UserService
+	auditService *AuditService
+}
+
+// NewBlindReviewService 创建盲审服务实例
+func NewBlindReviewService() *BlindReviewService {
+	return &BlindReviewService{
+		cfg:          &config.GlobalConfig.BlindReview,
+		secret:       config.GlobalConfig.JWT.DeriveKey("candidate-code"),
+		userService:  NewUserService(),
+		auditService: NewAuditService(),
+	}
+}
+
+// CandidateCode 生成申请对应的匿名候选人编号，同一申请始终相同且无法反推
+func (s *BlindReviewService) CandidateCode(applicationID uint) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("candidate:" + strconv.FormatUint(uint64(applicationID), 10)))
+	return "C-" + strings.ToUpper(hex.EncodeToString(mac.Sum(nil))[:8])
+}
+
+// IsBlind 判断指定状态的申请是否需要隐藏身份
+func (s *BlindReviewService) IsBlind(status string) bool {
+	return s.cfg.IsBlind(status)
+}
+
+// Mask 对处于盲审阶段的申请隐藏身份信息
+func (s *BlindReviewService) Mask(resp *models.InterviewApplicationResponse) *models.InterviewApplicationResponse {
+	resp.CandidateCode = s.CandidateCode(resp.ID)
+	if !s.cfg.IsBlind(resp.Status) {
+		return resp
+	}
+
+	resp.Blind = true
+	resp.Name = ""
+	resp.Email = ""
+	resp.Phone = ""
+	resp.StudentID = ""
+	resp.Attachments = nil
+	if !s.cfg.ShowMajor {
+		resp.Major = ""
+	}
+	if !s.cfg.ShowGrade {
+		resp.Grade = ""
+	}
+	return resp
+}
+
+// MaskList 对列表中处于盲审阶段的申请隐藏身份信息
+func (s *BlindReviewService) MaskList(list []models.InterviewApplicationResponse) {
+	for i := range list {
+		s.Mask(&list[i])
+	}
+}
+
+// CanSearchByName 判断当前用户能否按姓名搜索指定状态的申请
+func (s *BlindReviewService) CanSearchByName(c *gin.Context, status string) bool {
+	if !s.cfg.Enabled() || (status != "" && !s.cfg.IsBlind(status)) {
+		return true
+	}
+	return s.canReveal(c)
+}
+
+// Reveal 向有权限的用户展示申请人身份并记录审计日志，purpose 说明查看用途
+func (s *BlindReviewService) Reveal(c *gin.Context, application *models.InterviewApplication, purpose, reason string) error {
+	if !s.cfg.IsBlind(application.Status) {
+		return nil
+	}
+	if !s.canReveal(c) {
+		return ErrRevealForbidden
+	}
+
+	var operatorID *uint
+	if userID, exists := c.Get("user_id"); exists {
+		id := userID.(uint)
+		operatorID = &id
+	}
+
+	s.auditService.Record(AuditEntry{
+		UserID:       operatorID,
+		Action:       "application.reveal",
+		ResourceType: "interview_application",
+		ResourceID:   &application.ID,
+		Details: models.JSONMap{
+			"candidate_code": s.CandidateCode(application.ID),
+			"stage":          application.Status,
+			"purpose":        purpose,
+			"reason":         reason,
+		},
+	}.WithRequest(c))
+	return nil
+}
+
+// canReveal 判断当前用户是否持有身份查看权限
+func (s *BlindReviewService) canReveal(c *gin.Context) bool {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return false
+	}
+
+	user, err := s.userService.GetUserByID(userID.(uint))
+	if err != nil {
+		return false
+	}
+	return user.HasPermission(models.PermApplicationReveal)
+}
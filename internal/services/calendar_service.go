@@ -0,0 +1,177 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/ical"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// feedLookback 订阅源中保留的已结束面试时长
+const feedLookback = 24 * time.Hour
+
+// CalendarService 面试日历服务
+type CalendarService struct {
+	db               *gorm.DB
+	interviewService *InterviewApplicationService
+	blindReview      *BlindReviewService
+	baseURL          string
+}
+
+// NewCalendarService 创建面试日历服务实例
+func NewCalendarService() *CalendarService {
+	return &CalendarService{
+		db:               config.GetDB(),
+		interviewService: NewInterviewApplicationService(),
+		blindReview:      NewBlindReviewService(),
+		baseURL:          strings.TrimRight(config.GlobalConfig.Server.BaseURL, "/"),
+	}
+}
+
+// BuildInvite 生成单场面试的邀请日历
+func (s *CalendarService) BuildInvite(application *models.InterviewApplication) ([]byte, error) {
+	if !application.IsScheduled() {
+		return nil, errors.New("该申请尚未安排面试时间")
+	}
+
+	cal := ical.NewCalendar("EPI实验室面试", ical.MethodRequest)
+	event := s.buildEvent(application)
+	event.Attendees = []ical.Attendee{{Name: application.Name, Email: application.Email}}
+	if application.Interviewer != nil {
+		event.Attendees = append(event.Attendees, ical.Attendee{
+			Name:  application.Interviewer.Username,
+			Email: application.Interviewer.Email,
+		})
+	}
+	cal.AddEvent(event)
+
+	return cal.Bytes(), nil
+}
+
+// BuildInterviewerFeed 生成面试官的面试订阅日历
+// 订阅源仅凭令牌访问且常被同步到第三方日历服务，事件中只包含候选人编号与管理后台链接，不含申请人个人信息
+func (s *CalendarService) BuildInterviewerFeed(interviewer *models.User) ([]byte, error) {
+	applications, err := s.interviewService.ListUpcomingInterviews(interviewer.ID, time.Now().Add(-feedLookback))
+	if err != nil {
+		return nil, err
+	}
+
+	cal := ical.NewCalendar(fmt.Sprintf("%s的面试安排", interviewer.Username), ical.MethodPublish)
+	for i := range applications {
+		code := s.blindReview.CandidateCode(applications[i].ID)
+		event := s.buildEvent(&applications[i])
+		event.Summary = fmt.Sprintf("EPI实验室面试 - %s", code)
+		event.Description = fmt.Sprintf("候选人编号: %s\n申请详情: %s/admin?application=%d",
+			code, s.baseURL, applications[i].ID)
+		cal.AddEvent(event)
+	}
+
+	return cal.Bytes(), nil
+}
+
+// GetFeedURL 获取面试官的订阅地址，首次访问时生成令牌
+func (s *CalendarService) GetFeedURL(userID uint) (string, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("用户不存在")
+		}
+		return "", err
+	}
+
+	if user.CalendarToken == "" {
+		return s.ResetFeedToken(userID)
+	}
+
+	return s.feedURL(user.CalendarToken), nil
+}
+
+// ResetFeedToken 重新生成订阅令牌，旧的订阅地址立即失效
+func (s *CalendarService) ResetFeedToken(userID uint) (string, error) {
+	token, err := generateSecureToken()
+	if err != nil {
+		logger.Errorf("生成日历令牌失败: %v", err)
+		return "", errors.New("生成日历令牌失败")
+	}
+
+	if err := s.db.Model(&models.User{}).Where("id = ?", userID).Update("calendar_token", token).Error; err != nil {
+		logger.Errorf("保存日历令牌失败: %v", err)
+		return "", errors.New("保存日历令牌失败")
+	}
+
+	return s.feedURL(token), nil
+}
+
+// GetUserByFeedToken 根据订阅令牌获取用户
+func (s *CalendarService) GetUserByFeedToken(token string) (*models.User, error) {
+	if token == "" {
+		return nil, errors.New("订阅地址无效")
+	}
+
+	var user models.User
+	if err := s.db.Where("calendar_token = ?", token).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("订阅地址无效")
+		}
+		return nil, err
+	}
+
+	if !user.IsActive() {
+		return nil, errors.New("订阅地址无效")
+	}
+
+	return &user, nil
+}
+
+// buildEvent 构建面试事件的公共部分
+func (s *CalendarService) buildEvent(application *models.InterviewApplication) ical.Event {
+	host := "lab-recruitment-platform"
+	if u, err := url.Parse(s.baseURL); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+
+	return ical.Event{
+		UID:          fmt.Sprintf("interview-%d@%s", application.ID, host),
+		Sequence:     application.ScheduleSequence,
+		Start:        *application.InterviewAt,
+		End:          application.InterviewEndAt(),
+		Summary:      fmt.Sprintf("EPI实验室面试 - %s", application.Name),
+		Description:  "请提前10分钟到达面试地点，如需改期请回复邮件联系我们。",
+		Location:     application.Location,
+		Status:       "CONFIRMED",
+		Organizer:    &ical.Attendee{Name: config.GlobalConfig.Mail.FromName, Email: organizerEmail()},
+		Created:      application.CreatedAt,
+		LastModified: application.UpdatedAt,
+	}
+}
+
+// feedURL 拼接订阅地址
+func (s *CalendarService) feedURL(token string) string {
+	return fmt.Sprintf("%s/api/v1/calendar/%s/interviews.ics", s.baseURL, token)
+}
+
+// organizerEmail 获取日历组织者邮箱
+func organizerEmail() string {
+	if config.GlobalConfig.Mail.Username != "" {
+		return config.GlobalConfig.Mail.Username
+	}
+	return "noreply@lab-recruitment.com"
+}
+
+// generateSecureToken 生成随机令牌（日历订阅、录取答复等）
+func generateSecureToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
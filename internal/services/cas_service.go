@@ -0,0 +1,301 @@
+package services
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// CAS客户端参数
+const (
+	casHTTPTimeout     = 10 * time.Second
+	casMaxResponseSize = 1 << 20
+)
+
+// casPrincipalPattern 允许作为学号的CAS用户名
+var casPrincipalPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,20}$`)
+
+var (
+	// ErrCASDisabled 未启用CAS登录
+	ErrCASDisabled = errors.New("未启用统一身份认证登录")
+	// ErrCASTicketInvalid 票据校验失败
+	ErrCASTicketInvalid = errors.New("统一身份认证票据无效或已过期，请重新登录")
+	// ErrCASUnavailable CAS服务不可用
+	ErrCASUnavailable = errors.New("统一身份认证服务暂不可用")
+	// ErrCASPrincipalInvalid 学号格式不支持
+	ErrCASPrincipalInvalid = errors.New("统一身份认证返回的学号格式无效")
+	// ErrCASEmailRequired 无法确定账户邮箱
+	ErrCASEmailRequired = errors.New("统一身份认证未提供邮箱，无法自动创建账户，请联系管理员")
+	// ErrCASAccountConflict 学号对应多个账户或邮箱对应的账户已关联其他学号
+	ErrCASAccountConflict = errors.New("学号与已有账户信息冲突，请联系管理员处理")
+)
+
+// casServiceResponse CAS 2.0/3.0 票据校验响应
+type casServiceResponse struct {
+	XMLName xml.Name        `xml:"serviceResponse"`
+	Success *casAuthSuccess `xml:"authenticationSuccess"`
+	Failure *casAuthFailure `xml:"authenticationFailure"`
+}
+
+// casAuthSuccess 认证成功
+type casAuthSuccess struct {
+	User       string        `xml:"user"`
+	Attributes casAttributes `xml:"attributes"`
+}
+
+// casAuthFailure 认证失败
+type casAuthFailure struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+// casAttributes 释放的属性，兼容 <cas:mail>x</cas:mail> 与 <cas:attribute name="mail" value="x"/> 两种格式
+type casAttributes struct {
+	Items []casAttribute `xml:",any"`
+}
+
+// casAttribute 单个属性
+type casAttribute struct {
+	XMLName xml.Name
+	Name    string `xml:"name,attr"`
+	Value   string `xml:"value,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// get 按属性名取第一个非空值（不区分大小写）
+func (a casAttributes) get(name string) string {
+	if name == "" {
+		return ""
+	}
+	for _, item := range a.Items {
+		key, value := item.XMLName.Local, item.Text
+		if key == "attribute" && item.Name != "" {
+			key, value = item.Name, item.Value
+		}
+		if strings.EqualFold(key, name) && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// CASResult CAS登录结果
+type CASResult struct {
+	User      *models.User
+	Created   bool
+	Principal string
+}
+
+// CASService CAS 统一身份认证服务
+type CASService struct {
+	db         *gorm.DB
+	cfg        *config.CASConfig
+	sso        *SSOService
+	httpClient *http.Client
+}
+
+// NewCASService 创建CAS服务实例
+func NewCASService() *CASService {
+	return &CASService{
+		db:         config.GetDB(),
+		cfg:        &config.GlobalConfig.CAS,
+		sso:        NewSSOService(),
+		httpClient: &http.Client{Timeout: casHTTPTimeout},
+	}
+}
+
+// Enabled 是否启用CAS登录
+func (s *CASService) Enabled() bool {
+	return s.cfg.Enabled
+}
+
+// DisplayName 登录按钮上显示的名称
+func (s *CASService) DisplayName() string {
+	return s.cfg.DisplayName
+}
+
+// FrontendURL 前端登录回调页地址
+func (s *CASService) FrontendURL() string {
+	return s.cfg.FrontendURL
+}
+
+// SecureCookie 回调地址为 HTTPS 时状态 Cookie 仅通过 HTTPS 发送
+func (s *CASService) SecureCookie() bool {
+	return strings.HasPrefix(s.cfg.ServiceURL, "https://")
+}
+
+// LoginURL 生成跳转到CAS登录页的地址，state 附加在回调地址中，校验票据时须使用同一回调地址
+func (s *CASService) LoginURL(state string) (string, error) {
+	if !s.cfg.Enabled {
+		return "", ErrCASDisabled
+	}
+	return strings.TrimRight(s.cfg.ServerURL, "/") + "/login?" + url.Values{"service": {s.serviceURL(state)}}.Encode(), nil
+}
+
+// HandleCallback 校验服务票据，按学号匹配或创建学生账户
+func (s *CASService) HandleCallback(ticket, state string) (*CASResult, error) {
+	if !s.cfg.Enabled {
+		return nil, ErrCASDisabled
+	}
+	if ticket == "" {
+		return nil, ErrCASTicketInvalid
+	}
+
+	success, err := s.validateTicket(ticket, s.serviceURL(state))
+	if err != nil {
+		return nil, err
+	}
+
+	principal := strings.TrimSpace(success.User)
+	if !casPrincipalPattern.MatchString(principal) {
+		logger.Warnf("CAS学号格式无效: %q", principal)
+		return nil, ErrCASPrincipalInvalid
+	}
+
+	user, created, err := s.resolveStudent(principal, success.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	return &CASResult{User: user, Created: created, Principal: principal}, nil
+}
+
+// validateTicket 调用CAS服务端校验票据
+func (s *CASService) validateTicket(ticket, service string) (*casAuthSuccess, error) {
+	endpoint := "/serviceValidate"
+	if s.cfg.Version == "3.0" {
+		endpoint = "/p3/serviceValidate"
+	}
+	validateURL := strings.TrimRight(s.cfg.ServerURL, "/") + endpoint + "?" +
+		url.Values{"service": {service}, "ticket": {ticket}}.Encode()
+
+	resp, err := s.httpClient.Get(validateURL)
+	if err != nil {
+		logger.Errorf("CAS票据校验请求失败: %v", err)
+		return nil, ErrCASUnavailable
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, casMaxResponseSize))
+	if err != nil || resp.StatusCode != http.StatusOK {
+		logger.Errorf("CAS票据校验响应异常: status=%d, err=%v", resp.StatusCode, err)
+		return nil, ErrCASUnavailable
+	}
+
+	var result casServiceResponse
+	if err := xml.Unmarshal(body, &result); err != nil {
+		logger.Errorf("解析CAS票据校验响应失败: %v", err)
+		return nil, ErrCASUnavailable
+	}
+	if result.Failure != nil || result.Success == nil {
+		if result.Failure != nil {
+			logger.Warnf("CAS票据校验失败: code=%s, %s", result.Failure.Code, strings.TrimSpace(result.Failure.Message))
+		}
+		return nil, ErrCASTicketInvalid
+	}
+	return result.Success, nil
+}
+
+// resolveStudent 按已关联的CAS用户名匹配账户；未匹配时按邮箱关联已有账户，仍不存在则创建学生账户
+// 用户自行填写的学号不作为匹配依据，专业与年级仅在账户中为空时由CAS属性补全
+func (s *CASService) resolveStudent(principal string, attrs casAttributes) (*models.User, bool, error) {
+	major := attrs.get(s.cfg.Attributes.Major)
+	grade := attrs.get(s.cfg.Attributes.Grade)
+
+	var linked models.User
+	err := s.db.Where("cas_principal = ?", principal).First(&linked).Error
+	if err == nil {
+		user, err := s.prefillProfile(&linked, "", major, grade)
+		return user, false, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	email := strings.ToLower(attrs.get(s.cfg.Attributes.Email))
+	if email == "" && s.cfg.EmailDomain != "" {
+		email = strings.ToLower(principal) + "@" + strings.TrimPrefix(s.cfg.EmailDomain, "@")
+	}
+	if email == "" {
+		return nil, false, ErrCASEmailRequired
+	}
+
+	// 已用邮箱注册且未关联其他学号的账户直接关联
+	var existing models.User
+	err = s.db.Where("email = ?", email).First(&existing).Error
+	if err == nil {
+		if existing.IsCASLinked() || (existing.StudentID != "" && existing.StudentID != principal) {
+			logger.Warnf("CAS邮箱对应的账户已关联其他学号: email=%s, student_id=%s, principal=%s", email, existing.StudentID, principal)
+			return nil, false, ErrCASAccountConflict
+		}
+		user, err := s.prefillProfile(&existing, principal, major, grade)
+		return user, false, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	user, created, err := s.sso.findOrCreateUser(email, principal, "student", s.cfg.AutoCreate, &models.UserCreateRequest{
+		StudentID: principal,
+		Major:     truncate(major, 100),
+		Grade:     truncate(grade, 20),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if err := s.db.Model(user).Update("cas_principal", principal).Error; err != nil {
+		return nil, false, fmt.Errorf("关联统一身份认证失败: %w", err)
+	}
+	user.CASPrincipal = &principal
+	if created {
+		logger.Infof("CAS登录自动创建学生账户: user_id=%d, student_id=%s", user.ID, principal)
+	}
+	return user, created, nil
+}
+
+// prefillProfile 关联CAS用户名与学号并补全空缺的专业与年级，随后检查账户状态
+// 学校统一身份认证视为已验证身份，等待邮箱验证的账户按单点登录的规则激活
+func (s *CASService) prefillProfile(user *models.User, principal, major, grade string) (*models.User, error) {
+	updates := map[string]interface{}{}
+	if principal != "" {
+		updates["cas_principal"] = principal
+		updates["student_id"] = principal
+	}
+	if user.Major == "" && major != "" {
+		updates["major"] = truncate(major, 100)
+	}
+	if user.Grade == "" && grade != "" {
+		updates["grade"] = truncate(grade, 20)
+	}
+	if err := s.sso.updateAccount(user, updates); err != nil {
+		return nil, err
+	}
+
+	full, err := s.sso.userService.GetUserByID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !full.IsActive() {
+		return nil, ErrSSOAccountDisabled
+	}
+	return full, nil
+}
+
+// serviceURL 附加 state 的回调地址
+func (s *CASService) serviceURL(state string) string {
+	sep := "?"
+	if strings.Contains(s.cfg.ServiceURL, "?") {
+		sep = "&"
+	}
+	return s.cfg.ServiceURL + sep + url.Values{"state": {state}}.Encode()
+}
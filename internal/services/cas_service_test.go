@@ -0,0 +1,162 @@
+package services
+
+import (
+	"encoding/xml"
+	"net/url"
+	"testing"
+
+	"lab-recruitment-platform/internal/config"
+)
+
+// cas3Success CAS 3.0 成功响应，属性使用 <cas:mail> 形式
+const cas3Success = `<cas:serviceResponse xmlns:cas="http://www.yale.edu/tp/cas">
+  <cas:authenticationSuccess>
+    <cas:user>2024001</cas:user>
+    <cas:attributes>
+      <cas:mail> zhangsan@example.edu </cas:mail>
+      <cas:major></cas:major>
+      <cas:major>计算机科学与技术</cas:major>
+      <cas:grade>2024</cas:grade>
+    </cas:attributes>
+  </cas:authenticationSuccess>
+</cas:serviceResponse>`
+
+// casNameValueSuccess 部分CAS实现使用的 <cas:attribute name="" value=""/> 属性形式
+const casNameValueSuccess = `<cas:serviceResponse xmlns:cas="http://www.yale.edu/tp/cas">
+  <cas:authenticationSuccess>
+    <cas:user>2024002</cas:user>
+    <cas:attributes>
+      <cas:attribute name="Mail" value="lisi@example.edu"/>
+      <cas:attribute name="grade" value="2023"/>
+    </cas:attributes>
+  </cas:authenticationSuccess>
+</cas:serviceResponse>`
+
+// cas2Success CAS 2.0 成功响应，不释放属性
+const cas2Success = `<cas:serviceResponse xmlns:cas="http://www.yale.edu/tp/cas">
+  <cas:authenticationSuccess>
+    <cas:user>2024003</cas:user>
+  </cas:authenticationSuccess>
+</cas:serviceResponse>`
+
+// casFailure 票据无效
+const casFailure = `<cas:serviceResponse xmlns:cas="http://www.yale.edu/tp/cas">
+  <cas:authenticationFailure code="INVALID_TICKET">
+    Ticket ST-1 not recognized
+  </cas:authenticationFailure>
+</cas:serviceResponse>`
+
+func TestCASServiceResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantUser    string
+		wantFailure string
+		wantAttrs   map[string]string
+	}{
+		{
+			name:      "CAS 3.0 元素属性",
+			body:      cas3Success,
+			wantUser:  "2024001",
+			wantAttrs: map[string]string{"mail": "zhangsan@example.edu", "major": "计算机科学与技术", "grade": "2024", "name": ""},
+		},
+		{
+			name:      "name/value 属性",
+			body:      casNameValueSuccess,
+			wantUser:  "2024002",
+			wantAttrs: map[string]string{"mail": "lisi@example.edu", "MAIL": "lisi@example.edu", "grade": "2023", "major": ""},
+		},
+		{
+			name:      "CAS 2.0 无属性",
+			body:      cas2Success,
+			wantUser:  "2024003",
+			wantAttrs: map[string]string{"mail": ""},
+		},
+		{
+			name:        "票据无效",
+			body:        casFailure,
+			wantFailure: "INVALID_TICKET",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp casServiceResponse
+			if err := xml.Unmarshal([]byte(tt.body), &resp); err != nil {
+				t.Fatalf("解析响应失败: %v", err)
+			}
+
+			if tt.wantFailure != "" {
+				if resp.Failure == nil || resp.Failure.Code != tt.wantFailure || resp.Success != nil {
+					t.Fatalf("Failure = %+v, want code %s", resp.Failure, tt.wantFailure)
+				}
+				return
+			}
+			if resp.Success == nil || resp.Failure != nil {
+				t.Fatalf("期望认证成功，得到 %+v", resp)
+			}
+			if resp.Success.User != tt.wantUser {
+				t.Errorf("User = %q, want %q", resp.Success.User, tt.wantUser)
+			}
+			for name, want := range tt.wantAttrs {
+				if got := resp.Success.Attributes.get(name); got != want {
+					t.Errorf("get(%q) = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCASAttributesGetEmptyName(t *testing.T) {
+	var resp casServiceResponse
+	if err := xml.Unmarshal([]byte(cas3Success), &resp); err != nil {
+		t.Fatal(err)
+	}
+	// 未配置属性名时不应匹配任何属性
+	if got := resp.Success.Attributes.get(""); got != "" {
+		t.Errorf(`get("") = %q, want ""`, got)
+	}
+}
+
+func TestCASPrincipalPattern(t *testing.T) {
+	tests := []struct {
+		principal string
+		want      bool
+	}{
+		{"2024001", true},
+		{"zhang_san-01", true},
+		{"", false},
+		{"123456789012345678901", false},
+		{"zhang san", false},
+		{"2024001@example.edu", false},
+		{"学号", false},
+	}
+	for _, tt := range tests {
+		if got := casPrincipalPattern.MatchString(tt.principal); got != tt.want {
+			t.Errorf("casPrincipalPattern.MatchString(%q) = %v, want %v", tt.principal, got, tt.want)
+		}
+	}
+}
+
+func TestCASServiceURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		serviceURL string
+		want       string
+	}{
+		{"无查询参数", "https://lab.example.edu/api/v1/auth/cas/callback", "https://lab.example.edu/api/v1/auth/cas/callback?state=abc%2B1"},
+		{"已有查询参数", "https://lab.example.edu/cas?from=login", "https://lab.example.edu/cas?from=login&state=abc%2B1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &CASService{cfg: &config.CASConfig{ServiceURL: tt.serviceURL}}
+			got := s.serviceURL("abc+1")
+			if got != tt.want {
+				t.Errorf("serviceURL() = %q, want %q", got, tt.want)
+			}
+			u, err := url.Parse(got)
+			if err != nil || u.Query().Get("state") != "abc+1" {
+				t.Errorf("state 参数无法还原: %v", err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 	"lab-recruitment-platform/internal/config"
@@ -9,20 +10,31 @@ import (
 	"lab-recruitment-platform/pkg/logger"
 )
 
+// ErrApplicationEmailConflict 恢复申请时邮箱已被其他有效申请占用
+var ErrApplicationEmailConflict = errors.New("该邮箱已存在有效申请，无法恢复")
+
+// ErrInvalidInterviewer 指定的面试官没有查看申请的权限
+var ErrInvalidInterviewer = errors.New("面试官必须是具有查看申请权限的工作人员")
+
+// ErrVersionConflict 申请已被他人修改（版本号不一致）
+var ErrVersionConflict = errors.New("申请已被他人修改，请刷新后重试")
+
 // InterviewApplicationService 面试申请服务
 type InterviewApplicationService struct {
-	db *gorm.DB
+	db            *gorm.DB
+	uploadService *UploadService
 }
 
 // NewInterviewApplicationService 创建面试申请服务实例
 func NewInterviewApplicationService() *InterviewApplicationService {
 	return &InterviewApplicationService{
-		db: config.GetDB(),
+		db:            config.GetDB(),
+		uploadService: NewUploadService(),
 	}
 }
 
-// CreateApplication 创建面试申请
-func (s *InterviewApplicationService) CreateApplication(name, email, phone, studentID, major, grade, interviewTime string) (*models.InterviewApplication, error) {
+// CreateApplication 创建面试申请，并在同一事务中关联申请人预先上传的附件
+func (s *InterviewApplicationService) CreateApplication(name, email, phone, studentID, major, grade, interviewTime string, attachmentIDs []uint) (*models.InterviewApplication, error) {
 	// 检查邮箱是否已经申请过
 	var existingApp models.InterviewApplication
 	if err := s.db.Where("email = ?", email).First(&existingApp).Error; err == nil {
@@ -41,7 +53,19 @@ func (s *InterviewApplicationService) CreateApplication(name, email, phone, stud
 		Status:        "pending",
 	}
 
-	if err := s.db.Create(application).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(application).Error; err != nil {
+			return err
+		}
+		if err := linkUploads(tx, attachmentIDs, email, application.ID); err != nil {
+			return err
+		}
+		return logStatusChange(tx, application.ID, "", application.Status, nil)
+	})
+	if err != nil {
+		if errors.Is(err, ErrAttachmentInvalid) {
+			return nil, err
+		}
 		logger.Errorf("创建面试申请失败: %v", err)
 		return nil, errors.New("创建面试申请失败")
 	}
@@ -53,7 +77,7 @@ func (s *InterviewApplicationService) CreateApplication(name, email, phone, stud
 // GetApplicationByID 根据ID获取面试申请
 func (s *InterviewApplicationService) GetApplicationByID(id uint) (*models.InterviewApplication, error) {
 	var application models.InterviewApplication
-	if err := s.db.First(&application, id).Error; err != nil {
+	if err := s.db.Preload("Attachments").First(&application, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("面试申请不存在")
 		}
@@ -116,8 +140,8 @@ func (s *InterviewApplicationService) ListApplications(page, size int, status, n
 	}, nil
 }
 
-// UpdateApplication 更新面试申请状态
-func (s *InterviewApplicationService) UpdateApplication(id uint, status, adminRemarks string) (*models.InterviewApplication, error) {
+// UpdateApplication 更新面试申请状态，expectedVersion 不为空时要求与当前版本一致
+func (s *InterviewApplicationService) UpdateApplication(id uint, status, adminRemarks string, operatorID *uint, expectedVersion *uint) (*models.InterviewApplication, error) {
 	var application models.InterviewApplication
 	if err := s.db.First(&application, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -125,43 +149,380 @@ func (s *InterviewApplicationService) UpdateApplication(id uint, status, adminRe
 		}
 		return nil, err
 	}
+	if expectedVersion != nil && *expectedVersion != application.Version {
+		return nil, ErrVersionConflict
+	}
 
-	// 更新状态和备注
-	application.Status = status
-	application.AdminRemarks = adminRemarks
+	updates := map[string]interface{}{
+		"status":        status,
+		"admin_remarks": adminRemarks,
+		"version":       gorm.Expr("version + 1"),
+	}
+	if status != "interviewed" {
+		// 离开已面试状态时移出候补名单
+		updates["waitlist_rank"] = nil
+	}
 
-	if err := s.db.Save(&application).Error; err != nil {
+	previousStatus := application.Status
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 以读取时的版本号作为条件，防止并发修改互相覆盖
+		result := tx.Model(&models.InterviewApplication{}).
+			Where("id = ? AND version = ?", application.ID, application.Version).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		if previousStatus != status {
+			return logStatusChange(tx, application.ID, previousStatus, status, operatorID)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return nil, err
+		}
 		logger.Errorf("更新面试申请失败: %v", err)
 		return nil, errors.New("更新面试申请失败")
 	}
 
-	logger.Infof("面试申请更新成功: ID=%d, 状态=%s", application.ID, application.Status)
+	if err := s.db.First(&application, id).Error; err != nil {
+		return nil, err
+	}
+
+	logger.Infof("面试申请更新成功: ID=%d, 状态=%s, 版本=%d", application.ID, application.Status, application.Version)
 	return &application, nil
 }
 
-// DeleteApplication 删除面试申请
-func (s *InterviewApplicationService) DeleteApplication(id uint) error {
-	if err := s.db.Delete(&models.InterviewApplication{}, id).Error; err != nil {
-		logger.Errorf("删除面试申请失败: %v", err)
+// ScheduleInterview 安排面试时间、地点和面试官
+func (s *InterviewApplicationService) ScheduleInterview(id uint, req *models.InterviewScheduleRequest) (*models.InterviewApplication, error) {
+	application, err := s.GetApplicationByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.InterviewerID != nil {
+		var interviewer models.User
+		if err := s.db.Preload("Roles").First(&interviewer, *req.InterviewerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("面试官不存在")
+			}
+			return nil, err
+		}
+		// 面试官必须是可查看申请的在职工作人员
+		if !interviewer.IsActive() || !interviewer.HasPermission(models.PermApplicationRead) {
+			return nil, ErrInvalidInterviewer
+		}
+		application.Interviewer = &interviewer
+	}
+
+	duration := req.DurationMinutes
+	if duration == 0 {
+		duration = 30
+	}
+
+	interviewAt := req.InterviewAt
+	application.InterviewAt = &interviewAt
+	application.DurationMinutes = duration
+	application.Location = req.Location
+	application.InterviewerID = req.InterviewerID
+	application.InterviewTime = interviewAt.Local().Format("2006-01-02 15:04")
+	// 每次改期递增序号，日历客户端据此更新已有事件
+	application.ScheduleSequence++
+
+	result := s.db.Model(&models.InterviewApplication{}).
+		Where("id = ? AND version = ?", application.ID, application.Version).
+		Updates(map[string]interface{}{
+			"interview_at":      application.InterviewAt,
+			"duration_minutes":  application.DurationMinutes,
+			"location":          application.Location,
+			"interviewer_id":    application.InterviewerID,
+			"interview_time":    application.InterviewTime,
+			"schedule_sequence": application.ScheduleSequence,
+			"version":           gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		logger.Errorf("安排面试失败: %v", result.Error)
+		return nil, errors.New("安排面试失败")
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrVersionConflict
+	}
+	application.Version++
+
+	logger.Infof("面试安排成功: ID=%d, 时间=%s, 地点=%s", application.ID, application.InterviewTime, application.Location)
+	return application, nil
+}
+
+// ListUpcomingInterviews 获取面试官即将进行的面试
+func (s *InterviewApplicationService) ListUpcomingInterviews(interviewerID uint, since time.Time) ([]models.InterviewApplication, error) {
+	var applications []models.InterviewApplication
+	if err := s.db.Where("interviewer_id = ? AND interview_at >= ?", interviewerID, since).
+		Order("interview_at ASC").
+		Find(&applications).Error; err != nil {
+		return nil, err
+	}
+	return applications, nil
+}
+
+// DeleteApplication 删除面试申请，expectedVersion 不为空时要求与当前版本一致
+func (s *InterviewApplicationService) DeleteApplication(id uint, expectedVersion *uint) error {
+	query := s.db.Where("id = ?", id)
+	if expectedVersion != nil {
+		query = query.Where("version = ?", *expectedVersion)
+	}
+
+	result := query.Delete(&models.InterviewApplication{})
+	if result.Error != nil {
+		logger.Errorf("删除面试申请失败: %v", result.Error)
 		return errors.New("删除面试申请失败")
 	}
+	if result.RowsAffected == 0 {
+		if _, err := s.GetApplicationByID(id); err != nil {
+			return err
+		}
+		return ErrVersionConflict
+	}
 
 	logger.Infof("面试申请删除成功: ID=%d", id)
 	return nil
 }
 
+// ListDeletedApplications 获取回收站中的面试申请
+func (s *InterviewApplicationService) ListDeletedApplications(page, size int, name string) (*models.InterviewApplicationListResponse, error) {
+	var applications []models.InterviewApplication
+	var total int64
+
+	query := s.db.Unscoped().Model(&models.InterviewApplication{}).Where("deleted_at IS NOT NULL")
+
+	// 姓名搜索（支持模糊匹配）
+	if name != "" {
+		query = query.Where("name LIKE ?", "%"+name+"%")
+	}
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	// 分页查询
+	offset := (page - 1) * size
+	if err := query.Offset(offset).Limit(size).Order("deleted_at DESC").Find(&applications).Error; err != nil {
+		return nil, err
+	}
+
+	// 转换为响应格式
+	list := make([]models.InterviewApplicationResponse, len(applications))
+	for i, app := range applications {
+		list[i] = *app.ToResponse()
+	}
+
+	return &models.InterviewApplicationListResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		List:  list,
+	}, nil
+}
+
+// RestoreApplication 从回收站恢复面试申请
+func (s *InterviewApplicationService) RestoreApplication(id uint) (*models.InterviewApplication, error) {
+	application, err := s.getDeletedApplication(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 删除期间同一邮箱可能已重新提交申请
+	var count int64
+	if err := s.db.Model(&models.InterviewApplication{}).Where("email = ?", application.Email).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrApplicationEmailConflict
+	}
+
+	if err := s.db.Unscoped().Model(application).Update("deleted_at", nil).Error; err != nil {
+		logger.Errorf("恢复面试申请失败: %v", err)
+		return nil, errors.New("恢复面试申请失败")
+	}
+	application.DeletedAt = gorm.DeletedAt{}
+
+	logger.Infof("面试申请已恢复: ID=%d", application.ID)
+	return application, nil
+}
+
+// PurgeApplication 永久删除回收站中的面试申请
+func (s *InterviewApplicationService) PurgeApplication(id uint) error {
+	if _, err := s.getDeletedApplication(id); err != nil {
+		return err
+	}
+
+	if err := s.purge([]uint{id}); err != nil {
+		logger.Errorf("永久删除面试申请失败: %v", err)
+		return errors.New("永久删除面试申请失败")
+	}
+
+	logger.Infof("面试申请已永久删除: ID=%d", id)
+	return nil
+}
+
+// PurgeExpiredApplications 永久删除在回收站中超过保留期的面试申请
+func (s *InterviewApplicationService) PurgeExpiredApplications(retention time.Duration) (int, error) {
+	var ids []uint
+	if err := s.db.Unscoped().Model(&models.InterviewApplication{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", time.Now().Add(-retention)).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := s.purge(ids); err != nil {
+		return 0, err
+	}
+
+	logger.Infof("回收站自动清理完成: 删除%d条申请", len(ids))
+	return len(ids), nil
+}
+
+// getDeletedApplication 获取回收站中的面试申请
+func (s *InterviewApplicationService) getDeletedApplication(id uint) (*models.InterviewApplication, error) {
+	var application models.InterviewApplication
+	if err := s.db.Unscoped().Where("deleted_at IS NOT NULL").First(&application, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("回收站中不存在该申请")
+		}
+		return nil, err
+	}
+	return &application, nil
+}
+
+// purge 永久删除申请及其附件、状态记录和录取通知
+func (s *InterviewApplicationService) purge(ids []uint) error {
+	var files []models.FileUpload
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("application_id IN ?", ids).Find(&files).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("application_id IN ?", ids).Delete(&models.FileUpload{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("application_id IN ?", ids).Delete(&models.ApplicationStatusLog{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("application_id IN ?", ids).Delete(&models.Offer{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.InterviewApplication{}, ids).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// 数据库提交后再清理磁盘文件
+	s.uploadService.RemoveOrphanFiles(files)
+	return nil
+}
+
+// AnonymizeApplications 不可逆地替换申请人个人信息并删除附件，保留专业、年级、状态等统计字段
+// 同时删除发往申请人邮箱的邮件记录与验证码发送记录
+func (s *InterviewApplicationService) AnonymizeApplications(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var files []models.FileUpload
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("application_id IN ?", ids).Find(&files).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("application_id IN ?", ids).Delete(&models.FileUpload{}).Error; err != nil {
+			return err
+		}
+
+		var emails []string
+		if err := tx.Unscoped().Model(&models.InterviewApplication{}).
+			Where("id IN ? AND anonymized_at IS NULL", ids).
+			Pluck("email", &emails).Error; err != nil {
+			return err
+		}
+		if len(emails) > 0 {
+			hashes := make([]string, len(emails))
+			for i, email := range emails {
+				hashes[i] = codeLogEmailHash(email)
+			}
+			if err := tx.Where("recipient IN ?", emails).Delete(&models.EmailLog{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("email_hash IN ?", hashes).Delete(&models.VerificationCodeLog{}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Unscoped().Model(&models.InterviewApplication{}).
+			Where("id IN ? AND anonymized_at IS NULL", ids).
+			Updates(map[string]interface{}{
+				"name":          "已匿名",
+				"email":         gorm.Expr("CONCAT('anonymized-', id, '@anonymized.invalid')"),
+				"phone":         "",
+				"student_id":    "",
+				"admin_remarks": "",
+				"anonymized_at": time.Now(),
+				"version":       gorm.Expr("version + 1"),
+			}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// 数据库提交后再清理磁盘文件
+	s.uploadService.RemoveOrphanFiles(files)
+	return nil
+}
+
 // GetApplicationStats 获取面试申请统计
 func (s *InterviewApplicationService) GetApplicationStats() (*models.InterviewApplicationStats, error) {
-	var stats models.InterviewApplicationStats
+	return countByStatus(s.db.Model(&models.InterviewApplication{}))
+}
 
-	// 总数
-	s.db.Model(&models.InterviewApplication{}).Count(&stats.Total)
+// countByStatus 单次分组查询统计各状态数量
+func countByStatus(query *gorm.DB) (*models.InterviewApplicationStats, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := query.Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
 
-	// 各状态数量
-	s.db.Model(&models.InterviewApplication{}).Where("status = ?", "pending").Count(&stats.Pending)
-	s.db.Model(&models.InterviewApplication{}).Where("status = ?", "interviewed").Count(&stats.Interviewed)
-	s.db.Model(&models.InterviewApplication{}).Where("status = ?", "passed").Count(&stats.Passed)
-	s.db.Model(&models.InterviewApplication{}).Where("status = ?", "rejected").Count(&stats.Rejected)
+	var stats models.InterviewApplicationStats
+	for _, row := range rows {
+		stats.Total += row.Count
+		switch row.Status {
+		case "pending":
+			stats.Pending = row.Count
+		case "interviewed":
+			stats.Interviewed = row.Count
+		case "passed":
+			stats.Passed = row.Count
+		case "rejected":
+			stats.Rejected = row.Count
+		}
+	}
 
 	return &stats, nil
-} 
\ No newline at end of file
+}
+
+// logStatusChange 记录状态变更
+func logStatusChange(tx *gorm.DB, applicationID uint, fromStatus, toStatus string, changedBy *uint) error {
+	return tx.Create(&models.ApplicationStatusLog{
+		ApplicationID: applicationID,
+		FromStatus:    fromStatus,
+		ToStatus:      toStatus,
+		ChangedBy:     changedBy,
+	}).Error
+}
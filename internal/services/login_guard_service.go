@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// Redis键前缀
+const (
+	loginFailAccountPrefix = "auth:login_fail:account:"
+	loginFailIPPrefix      = "auth:login_fail:ip:"
+	loginNextPrefix        = "auth:login_next:"
+	loginLockAccountPrefix = "auth:login_lock:account:"
+	loginLockIPPrefix      = "auth:login_lock:ip:"
+)
+
+var (
+	// ErrAccountLocked 账户已临时锁定
+	ErrAccountLocked = errors.New("登录失败次数过多，账户已临时锁定")
+	// ErrIPLocked 来源IP已临时锁定
+	ErrIPLocked = errors.New("登录失败次数过多，请稍后再试")
+	// ErrLoginTooFrequent 登录尝试过于频繁
+	ErrLoginTooFrequent = errors.New("登录尝试过于频繁，请稍后再试")
+)
+
+// LoginGuardService 登录防暴力破解服务，按账户与IP统计失败次数
+type LoginGuardService struct {
+	redis *redis.Client
+	cfg   *config.LoginGuardConfig
+}
+
+// NewLoginGuardService 创建登录防暴力破解服务实例
+func NewLoginGuardService() *LoginGuardService {
+	return &LoginGuardService{
+		redis: config.GetRedisClient(),
+		cfg:   &config.GlobalConfig.LoginGuard,
+	}
+}
+
+// Check 判断是否允许本次登录尝试，不允许时返回需等待的时长及原因
+func (s *LoginGuardService) Check(email, ip string) (time.Duration, error) {
+	if !s.enabled() {
+		return 0, nil
+	}
+
+	ctx := context.Background()
+	account := normalizeLoginEmail(email)
+	pipe := s.redis.Pipeline()
+	accountLock := pipe.PTTL(ctx, loginLockAccountPrefix+account)
+	ipLock := pipe.PTTL(ctx, loginLockIPPrefix+ip)
+	next := pipe.PTTL(ctx, loginNextPrefix+account)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		logger.Warnf("检查登录限制失败: %v", err)
+		return 0, nil
+	}
+
+	if ttl := accountLock.Val(); ttl > 0 {
+		return ttl, ErrAccountLocked
+	}
+	if ttl := ipLock.Val(); ttl > 0 {
+		return ttl, ErrIPLocked
+	}
+	if ttl := next.Val(); ttl > 0 {
+		return ttl, ErrLoginTooFrequent
+	}
+	return 0, nil
+}
+
+// RecordFailure 记录一次登录失败，账户因本次失败被锁定时返回 true
+func (s *LoginGuardService) RecordFailure(email, ip string) (bool, error) {
+	if !s.enabled() {
+		return false, nil
+	}
+
+	ctx := context.Background()
+	account := normalizeLoginEmail(email)
+	pipe := s.redis.TxPipeline()
+	accountFails := pipe.Incr(ctx, loginFailAccountPrefix+account)
+	pipe.ExpireNX(ctx, loginFailAccountPrefix+account, s.cfg.Window)
+	ipFails := pipe.Incr(ctx, loginFailIPPrefix+ip)
+	pipe.ExpireNX(ctx, loginFailIPPrefix+ip, s.cfg.Window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+
+	if ipFails.Val() >= int64(s.cfg.IPMaxAttempts) {
+		if err := s.redis.Set(ctx, loginLockIPPrefix+ip, ipFails.Val(), s.cfg.LockoutDuration).Err(); err != nil {
+			return false, err
+		}
+		s.redis.Del(ctx, loginFailIPPrefix+ip)
+		logger.Warnf("登录失败次数过多，临时锁定IP: %s", ip)
+	}
+
+	failures := accountFails.Val()
+	if failures >= int64(s.cfg.MaxAttempts) {
+		locked, err := s.redis.SetNX(ctx, loginLockAccountPrefix+account, failures, s.cfg.LockoutDuration).Result()
+		if err != nil {
+			return false, err
+		}
+		s.redis.Del(ctx, loginFailAccountPrefix+account, loginNextPrefix+account)
+		if locked {
+			logger.Warnf("登录失败次数过多，临时锁定账户: %s", account)
+		}
+		return locked, nil
+	}
+
+	// 逐次翻倍的等待时间
+	if delay := s.delayFor(failures); delay > 0 {
+		if err := s.redis.Set(ctx, loginNextPrefix+account, failures, delay).Err(); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// RecordSuccess 登录成功后清除账户的失败记录
+func (s *LoginGuardService) RecordSuccess(email string) {
+	if !s.enabled() {
+		return
+	}
+
+	account := normalizeLoginEmail(email)
+	if err := s.redis.Del(context.Background(), loginFailAccountPrefix+account, loginNextPrefix+account).Err(); err != nil {
+		logger.Warnf("清除登录失败记录失败: %v", err)
+	}
+}
+
+// Unlock 解除账户锁定并清除失败记录，返回账户此前是否处于锁定状态
+func (s *LoginGuardService) Unlock(email string) (bool, error) {
+	if s.redis == nil {
+		return false, ErrSessionStoreUnavailable
+	}
+
+	account := normalizeLoginEmail(email)
+	ctx := context.Background()
+	pipe := s.redis.TxPipeline()
+	lock := pipe.Del(ctx, loginLockAccountPrefix+account)
+	pipe.Del(ctx, loginFailAccountPrefix+account, loginNextPrefix+account)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return lock.Val() > 0, nil
+}
+
+// LockoutDuration 账户锁定时长
+func (s *LoginGuardService) LockoutDuration() time.Duration {
+	return s.cfg.LockoutDuration
+}
+
+// delayFor 计算第 failures 次失败后的等待时间
+func (s *LoginGuardService) delayFor(failures int64) time.Duration {
+	if s.cfg.BaseDelay <= 0 || failures <= 0 {
+		return 0
+	}
+
+	delay := s.cfg.BaseDelay
+	for i := int64(1); i < failures; i++ {
+		delay *= 2
+		if s.cfg.MaxDelay > 0 && delay >= s.cfg.MaxDelay {
+			return s.cfg.MaxDelay
+		}
+	}
+	return delay
+}
+
+// enabled 是否启用登录保护，Redis不可用时放行
+func (s *LoginGuardService) enabled() bool {
+	return s.cfg.Enabled && s.redis != nil
+}
+
+// normalizeLoginEmail 统一邮箱大小写，避免通过变换大小写绕过统计
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"io"
+
+	"gopkg.in/gomail.v2"
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// MailAttachment 邮件附件
+type MailAttachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// MailService 邮件服务
+type MailService struct {
+	db  *gorm.DB
+	cfg *config.MailConfig
+}
+
+// NewMailService 创建邮件服务实例
+func NewMailService() *MailService {
+	return &MailService{
+		db:  config.GetDB(),
+		cfg: &config.GlobalConfig.Mail,
+	}
+}
+
+// Send 发送HTML邮件，可携带附件，并记录发送结果
+func (s *MailService) Send(to, subject, htmlBody string, attachments ...MailAttachment) error {
+	err := s.send(to, subject, htmlBody, attachments)
+	s.Record(to, subject, err)
+	return err
+}
+
+// Record 记录一次邮件发送结果，供个人数据导出使用
+func (s *MailService) Record(to, subject string, sendErr error) {
+	log := &models.EmailLog{
+		Recipient: to,
+		Subject:   subject,
+		Status:    "sent",
+	}
+	if sendErr != nil {
+		log.Status = "failed"
+		log.Error = sendErr.Error()
+	}
+
+	if err := s.db.Create(log).Error; err != nil {
+		logger.Errorf("记录邮件发送失败: %v", err)
+	}
+}
+
+// send 实际发送邮件
+func (s *MailService) send(to, subject, htmlBody string, attachments []MailAttachment) error {
+	// 测试模式：只记录日志，不实际发送
+	if s.cfg.IsTestMode() {
+		logger.Infof("测试模式 - 邮件《%s》已发送到 %s（附件%d个）", subject, to, len(attachments))
+		return nil
+	}
+
+	m := gomail.NewMessage()
+	m.SetAddressHeader("From", s.cfg.Username, s.cfg.FromName)
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/html", htmlBody)
+
+	for _, a := range attachments {
+		content := a.Content
+		m.Attach(a.Filename,
+			gomail.SetCopyFunc(func(w io.Writer) error {
+				_, err := w.Write(content)
+				return err
+			}),
+			gomail.SetHeader(map[string][]string{
+				"Content-Type": {a.ContentType},
+			}),
+		)
+	}
+
+	d := gomail.NewDialer(s.cfg.Host, s.cfg.Port, s.cfg.Username, s.cfg.Password)
+	return d.DialAndSend(m)
+}
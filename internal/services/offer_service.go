@@ -0,0 +1,467 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// ErrOfferCapacityFull 录取名额已满
+var ErrOfferCapacityFull = errors.New("录取名额已满，请先等待已发放的录取通知答复")
+
+// OfferService 录取通知与候补服务
+type OfferService struct {
+	db          *gorm.DB
+	cfg         *config.OfferConfig
+	mailService *MailService
+	baseURL     string
+}
+
+// NewOfferService 创建录取通知服务实例
+func NewOfferService() *OfferService {
+	return &OfferService{
+		db:          config.GetDB(),
+		cfg:         &config.GlobalConfig.Offer,
+		mailService: NewMailService(),
+		baseURL:     strings.TrimRight(config.GlobalConfig.Server.BaseURL, "/"),
+	}
+}
+
+// CreateOffer 向已通过的申请人发放录取通知
+func (s *OfferService) CreateOffer(applicationID uint, responseDays int, operatorID *uint) (*models.Offer, error) {
+	window := s.cfg.GetResponseWindow()
+	if responseDays > 0 {
+		window = time.Duration(responseDays) * 24 * time.Hour
+	}
+
+	var offer *models.Offer
+	var application models.InterviewApplication
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 锁定申请，避免与候补递补同时向同一申请人发放录取通知
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&application, applicationID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("面试申请不存在")
+			}
+			return err
+		}
+		if !application.IsPassed() {
+			return errors.New("只能向已通过的申请人发放录取通知")
+		}
+
+		active, err := hasActiveOffer(tx, applicationID)
+		if err != nil {
+			return err
+		}
+		if active {
+			return errors.New("该申请人已有有效的录取通知")
+		}
+
+		capacity, err := s.lockCapacity(tx)
+		if err != nil {
+			return err
+		}
+		// 从未发放过录取通知的已通过申请人已占用一个预留名额，向其发放时不再重复占用
+		available := capacity.Remaining
+		var count int64
+		if err := tx.Model(&models.Offer{}).Where("application_id = ?", applicationID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			available++
+		}
+		if capacity.Bounded && available <= 0 {
+			return ErrOfferCapacityFull
+		}
+
+		offer, err = s.issue(tx, application.ID, window, operatorID, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.sendOfferEmail(&application, offer)
+	logger.Infof("录取通知已发放: 申请ID=%d, 截止=%s", applicationID, offer.ExpiresAt.Format(time.RFC3339))
+	return offer, nil
+}
+
+// GetOfferByToken 根据答复令牌获取录取通知
+func (s *OfferService) GetOfferByToken(token string) (*models.Offer, error) {
+	if token == "" {
+		return nil, errors.New("录取通知不存在")
+	}
+
+	var offer models.Offer
+	if err := s.db.Preload("Application").Where("token = ?", token).First(&offer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("录取通知不存在")
+		}
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// Accept 申请人接受录取
+func (s *OfferService) Accept(token string) (*models.Offer, error) {
+	return s.respond(token, "accepted")
+}
+
+// Decline 申请人放弃录取，空出的名额自动递补给候补
+func (s *OfferService) Decline(token string) (*models.Offer, error) {
+	return s.respond(token, "declined")
+}
+
+// respond 记录申请人答复
+func (s *OfferService) respond(token, status string) (*models.Offer, error) {
+	offer, err := s.GetOfferByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if !offer.IsPending() {
+		return nil, errors.New("录取通知已答复或已失效")
+	}
+	if offer.IsExpired() {
+		// 已过答复期限但尚未被定时任务处理
+		if _, err := s.ExpireOffers(); err != nil {
+			logger.Errorf("处理过期录取通知失败: %v", err)
+		}
+		return nil, errors.New("录取通知已超过答复期限")
+	}
+
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Offer{}).
+			Where("id = ? AND status = ?", offer.ID, "pending").
+			Updates(map[string]interface{}{"status": status, "responded_at": now, "updated_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("录取通知已答复或已失效")
+		}
+
+		if status == "accepted" && s.cfg.LabID != 0 {
+			return tx.Model(&models.Lab{}).Where("id = ?", s.cfg.LabID).
+				Update("current_members", gorm.Expr("current_members + 1")).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	offer.Status = status
+	offer.RespondedAt = &now
+	logger.Infof("录取通知已答复: ID=%d, 结果=%s", offer.ID, status)
+
+	if status == "declined" {
+		if _, err := s.FillVacancies(1); err != nil {
+			logger.Errorf("候补递补失败: %v", err)
+		}
+	}
+	return offer, nil
+}
+
+// ExpireOffers 将超过答复期限的录取通知标记为过期并递补候补
+func (s *OfferService) ExpireOffers() (int, error) {
+	result := s.db.Model(&models.Offer{}).
+		Where("status = ? AND expires_at < ?", "pending", time.Now()).
+		Updates(map[string]interface{}{"status": "expired", "updated_at": time.Now()})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	expired := int(result.RowsAffected)
+	if expired > 0 {
+		logger.Infof("录取通知已过期: %d条", expired)
+	}
+
+	if expired > 0 {
+		if _, err := s.FillVacancies(expired); err != nil {
+			return expired, err
+		}
+	}
+	return expired, nil
+}
+
+// FillVacancies 在录取通知被放弃或过期后按候补排名依次递补 freed 个，名额受限时不超过空余名额
+func (s *OfferService) FillVacancies(freed int) (int, error) {
+	type promotion struct {
+		application models.InterviewApplication
+		offer       *models.Offer
+	}
+	var promoted []promotion
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		capacity, err := s.lockCapacity(tx)
+		if err != nil {
+			return err
+		}
+
+		limit := freed
+		if capacity.Bounded && capacity.Remaining < limit {
+			limit = capacity.Remaining
+		}
+		if limit <= 0 {
+			return nil
+		}
+
+		// 锁定候补申请，并发递补（答复拒绝与过期任务同时触发）时后者等待前者提交后读取最新状态
+		var candidates []models.InterviewApplication
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? AND waitlist_rank IS NOT NULL", "interviewed").
+			Order("waitlist_rank ASC, id ASC").Limit(limit).
+			Find(&candidates).Error; err != nil {
+			return err
+		}
+
+		for i := range candidates {
+			application := candidates[i]
+			active, err := hasActiveOffer(tx, application.ID)
+			if err != nil {
+				return err
+			}
+			if active {
+				continue
+			}
+			if err := tx.Model(&application).Updates(map[string]interface{}{
+				"status":        "passed",
+				"waitlist_rank": nil,
+				"version":       gorm.Expr("version + 1"),
+			}).Error; err != nil {
+				return err
+			}
+			if err := logStatusChange(tx, application.ID, "interviewed", "passed", nil); err != nil {
+				return err
+			}
+
+			offer, err := s.issue(tx, application.ID, s.cfg.GetResponseWindow(), nil, true)
+			if err != nil {
+				return err
+			}
+			promoted = append(promoted, promotion{application: application, offer: offer})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range promoted {
+		s.sendOfferEmail(&promoted[i].application, promoted[i].offer)
+		logger.Infof("候补递补成功: 申请ID=%d", promoted[i].application.ID)
+	}
+	return len(promoted), nil
+}
+
+// SetWaitlistRank 设置或移除（rank 为 nil）已面试申请的候补排名
+func (s *OfferService) SetWaitlistRank(applicationID uint, rank *int) (*models.InterviewApplication, error) {
+	var application models.InterviewApplication
+	if err := s.db.First(&application, applicationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("面试申请不存在")
+		}
+		return nil, err
+	}
+	if rank != nil && !application.IsInterviewed() {
+		return nil, errors.New("只有已面试的申请可以加入候补名单")
+	}
+
+	if err := s.db.Model(&application).Updates(map[string]interface{}{
+		"waitlist_rank": rank,
+		"version":       gorm.Expr("version + 1"),
+	}).Error; err != nil {
+		logger.Errorf("更新候补排名失败: %v", err)
+		return nil, errors.New("更新候补排名失败")
+	}
+
+	application.WaitlistRank = rank
+	application.Version++
+	return &application, nil
+}
+
+// ListWaitlist 获取按排名排序的候补名单
+func (s *OfferService) ListWaitlist() ([]models.InterviewApplicationResponse, error) {
+	var applications []models.InterviewApplication
+	if err := s.db.Where("status = ? AND waitlist_rank IS NOT NULL", "interviewed").
+		Order("waitlist_rank ASC, id ASC").Find(&applications).Error; err != nil {
+		return nil, err
+	}
+
+	list := make([]models.InterviewApplicationResponse, len(applications))
+	for i := range applications {
+		list[i] = *applications[i].ToResponse()
+	}
+	return list, nil
+}
+
+// ListOffers 获取录取通知列表
+func (s *OfferService) ListOffers(page, size int, status string) (*models.OfferListResponse, error) {
+	var offers []models.Offer
+	var total int64
+
+	query := s.db.Model(&models.Offer{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Preload("Application").Offset(offset).Limit(size).Order("created_at DESC").Find(&offers).Error; err != nil {
+		return nil, err
+	}
+
+	list := make([]models.OfferResponse, 0, len(offers))
+	for i := range offers {
+		list = append(list, *offers[i].ToResponse())
+	}
+
+	return &models.OfferListResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		List:  list,
+	}, nil
+}
+
+// GetCapacity 获取录取名额使用情况
+func (s *OfferService) GetCapacity() (*models.OfferCapacity, error) {
+	return s.capacity(s.db)
+}
+
+// lockCapacity 在事务中锁定实验室记录后计算名额，避免并发发放超额
+func (s *OfferService) lockCapacity(tx *gorm.DB) (*models.OfferCapacity, error) {
+	if s.cfg.LabID != 0 {
+		var lab models.Lab
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&lab, s.cfg.LabID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("录取名额配置的实验室不存在: ID=%d", s.cfg.LabID)
+			}
+			return nil, err
+		}
+	}
+	return s.capacity(tx)
+}
+
+// capacity 计算录取名额：实验室最大成员数减去现有成员、待答复的录取通知以及尚未发放录取通知的已通过申请人
+func (s *OfferService) capacity(db *gorm.DB) (*models.OfferCapacity, error) {
+	var pending int64
+	if err := db.Model(&models.Offer{}).Where("status = ?", "pending").Count(&pending).Error; err != nil {
+		return nil, err
+	}
+
+	var awaiting int64
+	if err := db.Model(&models.InterviewApplication{}).
+		Where("status = ? AND id NOT IN (?)", "passed", db.Model(&models.Offer{}).Select("application_id")).
+		Count(&awaiting).Error; err != nil {
+		return nil, err
+	}
+
+	capacity := &models.OfferCapacity{PendingOffers: int(pending), AwaitingOffer: int(awaiting)}
+	if s.cfg.LabID == 0 {
+		return capacity, nil
+	}
+
+	var lab models.Lab
+	if err := db.First(&lab, s.cfg.LabID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("录取名额配置的实验室不存在: ID=%d", s.cfg.LabID)
+		}
+		return nil, err
+	}
+
+	capacity.Bounded = true
+	capacity.MaxMembers = lab.MaxMembers
+	capacity.CurrentMembers = lab.CurrentMembers
+	capacity.Remaining = lab.MaxMembers - lab.CurrentMembers - int(pending) - int(awaiting)
+	return capacity, nil
+}
+
+// issue 在事务中创建录取通知
+func (s *OfferService) issue(tx *gorm.DB, applicationID uint, window time.Duration, operatorID *uint, autoPromoted bool) (*models.Offer, error) {
+	token, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+
+	offer := &models.Offer{
+		ApplicationID: applicationID,
+		Token:         token,
+		Status:        "pending",
+		ExpiresAt:     time.Now().Add(window),
+		CreatedBy:     operatorID,
+		AutoPromoted:  autoPromoted,
+	}
+	if err := tx.Create(offer).Error; err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// hasActiveOffer 判断申请人是否已有待答复或已接受的录取通知
+func hasActiveOffer(tx *gorm.DB, applicationID uint) (bool, error) {
+	var count int64
+	if err := tx.Model(&models.Offer{}).
+		Where("application_id = ? AND status IN ?", applicationID, []string{"pending", "accepted"}).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// offerURL 申请人答复录取通知的页面地址
+func (s *OfferService) offerURL(token string) string {
+	return fmt.Sprintf("%s/offer/%s", s.baseURL, token)
+}
+
+// sendOfferEmail 发送录取通知邮件，失败只记录日志
+func (s *OfferService) sendOfferEmail(application *models.InterviewApplication, offer *models.Offer) {
+	htmlBody := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>录取通知</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 20px; text-align: center; border-radius: 8px 8px 0 0; }
+        .content { background: #f9f9f9; padding: 20px; border-radius: 0 0 8px 8px; }
+        .success { background: #52c41a; color: white; padding: 15px; text-align: center; border-radius: 4px; margin: 20px 0; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>🧪 EPI实验室</h1>
+        </div>
+        <div class="content">
+            <p>亲爱的 %s：</p>
+            <div class="success">
+                <h3>🎉 恭喜您被EPI实验室录取！</h3>
+            </div>
+            <p>请在 <strong>%s</strong> 前访问以下链接确认是否接受录取：</p>
+            <p><a href="%s">%s</a></p>
+            <p>逾期未答复将视为放弃，名额将递补给候补同学。</p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(application.Name), offer.ExpiresAt.Local().Format("2006-01-02 15:04"),
+		s.offerURL(offer.Token), s.offerURL(offer.Token))
+
+	if err := s.mailService.Send(application.Email, "EPI实验室录取通知", htmlBody); err != nil {
+		logger.Errorf("发送录取通知邮件失败: 申请ID=%d, err=%v", application.ID, err)
+	}
+}
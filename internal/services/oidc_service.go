@@ -0,0 +1,481 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/jwks"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// oidcStatePrefix 授权请求状态的Redis键前缀
+const oidcStatePrefix = "auth:oidc_state:"
+
+// OIDC客户端参数
+const (
+	oidcHTTPTimeout     = 10 * time.Second
+	oidcMetadataTTL     = time.Hour
+	oidcJWKSRefreshWait = time.Minute // 遇到未知 kid 时重新拉取公钥的最小间隔
+	oidcClockSkew       = time.Minute
+	oidcMaxResponseSize = 1 << 20
+)
+
+// oidcSigningMethods 允许的ID令牌签名算法，不接受 none 与对称算法
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+var (
+	// ErrOIDCDisabled 未启用单点登录
+	ErrOIDCDisabled = errors.New("未启用单点登录")
+	// ErrOIDCStateInvalid 授权状态无效或已过期
+	ErrOIDCStateInvalid = errors.New("登录请求已过期，请重新登录")
+	// ErrOIDCLoginFailed 身份提供方认证失败
+	ErrOIDCLoginFailed = errors.New("统一身份认证失败，请重试")
+	// ErrOIDCEmailRequired 身份提供方未返回已验证的邮箱
+	ErrOIDCEmailRequired = errors.New("统一身份认证未提供已验证的邮箱")
+	// ErrOIDCDomainNotAllowed 邮箱域名不在允许范围内
+	ErrOIDCDomainNotAllowed = errors.New("该邮箱域名不允许登录本平台")
+)
+
+// oidcProviderMetadata 身份提供方发现文档中使用的字段
+type oidcProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcState 授权请求状态，回调时取出并删除
+type oidcState struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// oidcIDTokenClaims ID令牌声明
+type oidcIDTokenClaims struct {
+	Nonce             string      `json:"nonce"`
+	AuthorizedParty   string      `json:"azp"`
+	Email             string      `json:"email"`
+	EmailVerified     interface{} `json:"email_verified"` // 部分身份提供方以字符串返回
+	Name              string      `json:"name"`
+	PreferredUsername string      `json:"preferred_username"`
+	jwt.RegisteredClaims
+}
+
+// emailVerified 判断邮箱是否已被身份提供方验证
+func (c *oidcIDTokenClaims) emailVerified() bool {
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+// oidcProviderCache 发现文档与公钥缓存，在服务实例之间共享
+type oidcProviderCache struct {
+	mu          sync.Mutex
+	issuer      string
+	metadata    *oidcProviderMetadata
+	metadataAt  time.Time
+	keys        jwks.Set
+	keysFetched time.Time
+}
+
+var oidcCache = &oidcProviderCache{}
+
+// OIDCResult 单点登录结果
+type OIDCResult struct {
+	User    *models.User
+	Created bool
+	Subject string
+}
+
+// OIDCService OpenID Connect 单点登录服务（授权码模式 + PKCE）
+type OIDCService struct {
+	cfg        *config.OIDCConfig
+	redis      *redis.Client
+	sso        *SSOService
+	httpClient *http.Client
+}
+
+// NewOIDCService 创建单点登录服务实例
+func NewOIDCService() *OIDCService {
+	return &OIDCService{
+		cfg:        &config.GlobalConfig.OIDC,
+		redis:      config.GetRedisClient(),
+		sso:        NewSSOService(),
+		httpClient: &http.Client{Timeout: oidcHTTPTimeout},
+	}
+}
+
+// Enabled 是否启用单点登录
+func (s *OIDCService) Enabled() bool {
+	return s.cfg.Enabled
+}
+
+// DisplayName 登录按钮上显示的名称
+func (s *OIDCService) DisplayName() string {
+	return s.cfg.DisplayName
+}
+
+// FrontendURL 前端登录回调页地址
+func (s *OIDCService) FrontendURL() string {
+	return s.cfg.FrontendURL
+}
+
+// StateTTL 从跳转到回调的最长时间
+func (s *OIDCService) StateTTL() time.Duration {
+	return s.cfg.StateTTL
+}
+
+// SecureCookie 回调地址为 HTTPS 时状态 Cookie 仅通过 HTTPS 发送
+func (s *OIDCService) SecureCookie() bool {
+	return strings.HasPrefix(s.cfg.RedirectURL, "https://")
+}
+
+// AuthorizationURL 生成跳转到身份提供方的授权地址及 state，nonce 与 PKCE 校验码保存在Redis中
+func (s *OIDCService) AuthorizationURL() (string, string, error) {
+	if !s.cfg.Enabled {
+		return "", "", ErrOIDCDisabled
+	}
+	if s.redis == nil {
+		return "", "", ErrSessionStoreUnavailable
+	}
+
+	metadata, err := s.metadata()
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := generateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := generateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := generateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	data, err := json.Marshal(oidcState{Nonce: nonce, CodeVerifier: verifier})
+	if err != nil {
+		return "", "", err
+	}
+	if err := s.redis.Set(context.Background(), oidcStatePrefix+hashToken(state), data, s.cfg.StateTTL).Err(); err != nil {
+		return "", "", fmt.Errorf("保存登录状态失败: %w", err)
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {s.cfg.ClientID},
+		"redirect_uri":          {s.cfg.RedirectURL},
+		"scope":                 {strings.Join(s.scopes(), " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return metadata.AuthorizationEndpoint + sep + params.Encode(), state, nil
+}
+
+// HandleCallback 处理身份提供方回调：校验 state，用授权码换取并验证ID令牌，再匹配或创建本地账户
+func (s *OIDCService) HandleCallback(code, state string) (*OIDCResult, error) {
+	if !s.cfg.Enabled {
+		return nil, ErrOIDCDisabled
+	}
+
+	saved, err := s.takeState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, err := s.exchangeCode(code, saved.CodeVerifier)
+	if err != nil {
+		logger.Warnf("单点登录换取令牌失败: %v", err)
+		return nil, ErrOIDCLoginFailed
+	}
+
+	claims, err := s.verifyIDToken(rawIDToken, saved.Nonce)
+	if err != nil {
+		logger.Warnf("单点登录ID令牌校验失败: %v", err)
+		return nil, ErrOIDCLoginFailed
+	}
+
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" || !claims.emailVerified() {
+		return nil, ErrOIDCEmailRequired
+	}
+	if !s.domainAllowed(email) {
+		return nil, ErrOIDCDomainNotAllowed
+	}
+
+	user, created, err := s.sso.findOrCreateUser(email, claims.PreferredUsername, s.cfg.DefaultRole, s.cfg.AutoCreate, nil)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		logger.Infof("单点登录自动创建账户: user_id=%d, sub=%s", user.ID, claims.Subject)
+	}
+	return &OIDCResult{User: user, Created: created, Subject: claims.Subject}, nil
+}
+
+// takeState 取出并删除授权状态，防止回调被重放
+func (s *OIDCService) takeState(state string) (*oidcState, error) {
+	if s.redis == nil {
+		return nil, ErrSessionStoreUnavailable
+	}
+	if state == "" {
+		return nil, ErrOIDCStateInvalid
+	}
+
+	data, err := s.redis.GetDel(context.Background(), oidcStatePrefix+hashToken(state)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrOIDCStateInvalid
+		}
+		return nil, err
+	}
+
+	var saved oidcState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, ErrOIDCStateInvalid
+	}
+	return &saved, nil
+}
+
+// exchangeCode 在令牌端点用授权码换取ID令牌
+func (s *OIDCService) exchangeCode(code, verifier string) (string, error) {
+	metadata, err := s.metadata()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {s.cfg.RedirectURL},
+		"client_id":     {s.cfg.ClientID},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequest(http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(s.cfg.ClientID), url.QueryEscape(s.cfg.ClientSecret))
+	}
+
+	var result struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := s.doJSON(req, &result)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK || result.Error != "" {
+		return "", fmt.Errorf("令牌端点返回错误: status=%d, error=%s %s", status, result.Error, result.ErrorDescription)
+	}
+	if result.IDToken == "" {
+		return "", errors.New("令牌端点未返回 id_token")
+	}
+	return result.IDToken, nil
+}
+
+// verifyIDToken 使用身份提供方公钥验证ID令牌签名、签发方、受众、有效期与 nonce
+func (s *OIDCService) verifyIDToken(raw, nonce string) (*oidcIDTokenClaims, error) {
+	metadata, err := s.metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &oidcIDTokenClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := s.signingKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		if key.Alg != "" && key.Alg != token.Method.Alg() {
+			return nil, fmt.Errorf("签名算法与公钥不匹配: %s", token.Method.Alg())
+		}
+		return key.PublicKey()
+	},
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(s.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcClockSkew),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Nonce != nonce {
+		return nil, errors.New("nonce 不匹配")
+	}
+	// 存在多个受众时授权方必须是本客户端
+	if (len(claims.Audience) > 1 || claims.AuthorizedParty != "") && claims.AuthorizedParty != s.cfg.ClientID {
+		return nil, errors.New("azp 与客户端ID不匹配")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("缺少 sub 声明")
+	}
+	return claims, nil
+}
+
+// signingKey 按 kid 查找身份提供方公钥，未找到时重新拉取一次以支持密钥轮换
+func (s *OIDCService) signingKey(kid string) (jwks.JWK, error) {
+	metadata, err := s.metadata()
+	if err != nil {
+		return jwks.JWK{}, err
+	}
+
+	oidcCache.mu.Lock()
+	keys, fetchedAt := oidcCache.keys, oidcCache.keysFetched
+	oidcCache.mu.Unlock()
+
+	if key, ok := keys.Find(kid); ok {
+		return key, nil
+	}
+	if time.Since(fetchedAt) < oidcJWKSRefreshWait {
+		return jwks.JWK{}, fmt.Errorf("未知的签名公钥: %q", kid)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, metadata.JWKSURI, nil)
+	if err != nil {
+		return jwks.JWK{}, err
+	}
+	var set jwks.Set
+	status, err := s.doJSON(req, &set)
+	if err != nil {
+		return jwks.JWK{}, err
+	}
+	if status != http.StatusOK {
+		return jwks.JWK{}, fmt.Errorf("获取身份提供方公钥失败: status=%d", status)
+	}
+
+	oidcCache.mu.Lock()
+	oidcCache.keys, oidcCache.keysFetched = set, time.Now()
+	oidcCache.mu.Unlock()
+
+	if key, ok := set.Find(kid); ok {
+		return key, nil
+	}
+	return jwks.JWK{}, fmt.Errorf("未知的签名公钥: %q", kid)
+}
+
+// metadata 获取身份提供方发现文档，按签发方缓存
+func (s *OIDCService) metadata() (*oidcProviderMetadata, error) {
+	issuer := strings.TrimRight(s.cfg.Issuer, "/")
+
+	oidcCache.mu.Lock()
+	if oidcCache.issuer == issuer && oidcCache.metadata != nil && time.Since(oidcCache.metadataAt) < oidcMetadataTTL {
+		metadata := oidcCache.metadata
+		oidcCache.mu.Unlock()
+		return metadata, nil
+	}
+	oidcCache.mu.Unlock()
+
+	req, err := http.NewRequest(http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var metadata oidcProviderMetadata
+	status, err := s.doJSON(req, &metadata)
+	if err != nil {
+		logger.Errorf("获取身份提供方配置失败: %v", err)
+		return nil, ErrOIDCLoginFailed
+	}
+	if status != http.StatusOK {
+		logger.Errorf("获取身份提供方配置失败: status=%d", status)
+		return nil, ErrOIDCLoginFailed
+	}
+	// 发现文档中的签发方必须与配置一致，防止被替换为其他身份提供方
+	if strings.TrimRight(metadata.Issuer, "/") != issuer {
+		logger.Errorf("身份提供方签发方不匹配: 配置=%s, 实际=%s", issuer, metadata.Issuer)
+		return nil, ErrOIDCLoginFailed
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		logger.Errorf("身份提供方配置缺少必要的端点: %+v", metadata)
+		return nil, ErrOIDCLoginFailed
+	}
+
+	oidcCache.mu.Lock()
+	if oidcCache.issuer != issuer {
+		oidcCache.keys, oidcCache.keysFetched = jwks.Set{}, time.Time{}
+	}
+	oidcCache.issuer, oidcCache.metadata, oidcCache.metadataAt = issuer, &metadata, time.Now()
+	oidcCache.mu.Unlock()
+	return &metadata, nil
+}
+
+// doJSON 发送请求并解析JSON响应，返回HTTP状态码
+func (s *OIDCService) doJSON(req *http.Request, out interface{}) (int, error) {
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, oidcMaxResponseSize))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(body, out); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// scopes 请求的权限范围，始终包含 openid
+func (s *OIDCService) scopes() []string {
+	for _, scope := range s.cfg.Scopes {
+		if scope == "openid" {
+			return s.cfg.Scopes
+		}
+	}
+	return append([]string{"openid"}, s.cfg.Scopes...)
+}
+
+// domainAllowed 判断邮箱域名是否允许登录
+func (s *OIDCService) domainAllowed(email string) bool {
+	if len(s.cfg.AllowedDomains) == 0 {
+		return true
+	}
+	domain := email[strings.LastIndex(email, "@")+1:]
+	for _, allowed := range s.cfg.AllowedDomains {
+		if strings.EqualFold(domain, strings.TrimPrefix(allowed, "@")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"crypto"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/pkg/jwks"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testOIDCProvider 在本地提供发现文档与公钥集合的身份提供方
+type testOIDCProvider struct {
+	server *httptest.Server
+	rsaKey crypto.Signer
+	edKey  crypto.Signer
+}
+
+func newTestOIDCProvider(t *testing.T) *testOIDCProvider {
+	t.Helper()
+	rsaKey, err := jwks.GenerateKey(jwks.AlgRS256, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey, err := jwks.GenerateKey(jwks.AlgEdDSA, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaJWK, err := jwks.NewJWK("rsa", rsaKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	edJWK, err := jwks.NewJWK("ed", edKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &testOIDCProvider{rsaKey: rsaKey, edKey: edKey}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcProviderMetadata{
+			Issuer:                p.server.URL,
+			AuthorizationEndpoint: p.server.URL + "/authorize",
+			TokenEndpoint:         p.server.URL + "/token",
+			JWKSURI:               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwks.Set{Keys: []jwks.JWK{rsaJWK, edJWK}})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	// 发现文档与公钥缓存为包级变量，每个测试使用独立的缓存
+	saved := oidcCache
+	oidcCache = &oidcProviderCache{}
+	t.Cleanup(func() { oidcCache = saved })
+	return p
+}
+
+// claims 返回一组可通过校验的ID令牌声明
+func (p *testOIDCProvider) claims() *oidcIDTokenClaims {
+	now := time.Now()
+	return &oidcIDTokenClaims{
+		Nonce: "nonce-1",
+		Email: "alice@example.edu",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.server.URL,
+			Subject:   "alice",
+			Audience:  jwt.ClaimStrings{"lab-platform"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+	}
+}
+
+// signIDToken 使用指定算法与 kid 签发ID令牌
+func signIDToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims *oidcIDTokenClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("签发ID令牌失败: %v", err)
+	}
+	return raw
+}
+
+func TestOIDCVerifyIDToken(t *testing.T) {
+	p := newTestOIDCProvider(t)
+	s := &OIDCService{
+		cfg:        &config.OIDCConfig{Issuer: p.server.URL, ClientID: "lab-platform"},
+		httpClient: p.server.Client(),
+	}
+
+	modify := func(f func(c *oidcIDTokenClaims)) *oidcIDTokenClaims {
+		c := p.claims()
+		f(c)
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		nonce   string
+		wantErr bool
+	}{
+		{"EdDSA签名", signIDToken(t, jwt.SigningMethodEdDSA, p.edKey, "ed", p.claims()), "nonce-1", false},
+		{"RS256签名", signIDToken(t, jwt.SigningMethodRS256, p.rsaKey, "rsa", p.claims()), "nonce-1", false},
+		{"多个受众且azp为本客户端", signIDToken(t, jwt.SigningMethodEdDSA, p.edKey, "ed", modify(func(c *oidcIDTokenClaims) {
+			c.Audience = jwt.ClaimStrings{"lab-platform", "other"}
+			c.AuthorizedParty = "lab-platform"
+		})), "nonce-1", false},
+		{"nonce不匹配", signIDToken(t, jwt.SigningMethodEdDSA, p.edKey, "ed", p.claims()), "nonce-2", true},
+		{"缺少nonce", signIDToken(t, jwt.SigningMethodEdDSA, p.edKey, "ed", modify(func(c *oidcIDTokenClaims) {
+			c.Nonce = ""
+		})), "nonce-1", true},
+		{"alg为none", signIDToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "ed", p.claims()), "nonce-1", true},
+		{"对称算法", signIDToken(t, jwt.SigningMethodHS256, []byte("lab-platform-client-secret"), "ed", p.claims()), "nonce-1", true},
+		{"签名算法与公钥声明的算法不一致", signIDToken(t, jwt.SigningMethodPS256, p.rsaKey, "rsa", p.claims()), "nonce-1", true},
+		{"使用其他公钥的kid", signIDToken(t, jwt.SigningMethodEdDSA, p.edKey, "rsa", p.claims()), "nonce-1", true},
+		{"未知kid", signIDToken(t, jwt.SigningMethodEdDSA, p.edKey, "unknown", p.claims()), "nonce-1", true},
+		{"签发方不匹配", signIDToken(t, jwt.SigningMethodEdDSA, p.edKey, "ed", modify(func(c *oidcIDTokenClaims) {
+			c.Issuer = "https://evil.example.com"
+		})), "nonce-1", true},
+		{"受众不匹配", signIDToken(t, jwt.SigningMethodEdDSA, p.edKey, "ed", modify(func(c *oidcIDTokenClaims) {
+			c.Audience = jwt.ClaimStrings{"other"}
+		})), "nonce-1", true},
+		{"多个受众缺少azp", signIDToken(t, jwt.SigningMethodEdDSA, p.edKey, "ed", modify(func(c *oidcIDTokenClaims) {
+			c.Audience = jwt.ClaimStrings{"lab-platform", "other"}
+		})), "nonce-1", true},
+		{"已过期", signIDToken(t, jwt.SigningMethodEdDSA, p.edKey, "ed", modify(func(c *oidcIDTokenClaims) {
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-2 * oidcClockSkew))
+		})), "nonce-1", true},
+		{"缺少过期时间", signIDToken(t, jwt.SigningMethodEdDSA, p.edKey, "ed", modify(func(c *oidcIDTokenClaims) {
+			c.ExpiresAt = nil
+		})), "nonce-1", true},
+		{"缺少sub", signIDToken(t, jwt.SigningMethodEdDSA, p.edKey, "ed", modify(func(c *oidcIDTokenClaims) {
+			c.Subject = ""
+		})), "nonce-1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := s.verifyIDToken(tt.token, tt.nonce)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyIDToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && claims.Subject != "alice" {
+				t.Errorf("Subject = %q, want alice", claims.Subject)
+			}
+		})
+	}
+}
+
+func TestOIDCMetadataIssuerMismatch(t *testing.T) {
+	p := newTestOIDCProvider(t)
+	// 配置的签发方与发现文档不一致时拒绝使用该身份提供方
+	s := &OIDCService{
+		cfg:        &config.OIDCConfig{Issuer: p.server.URL + "/realms/other", ClientID: "lab-platform"},
+		httpClient: p.server.Client(),
+	}
+	if _, err := s.metadata(); err != ErrOIDCLoginFailed {
+		t.Errorf("metadata() error = %v, want %v", err, ErrOIDCLoginFailed)
+	}
+}
+
+func TestOIDCEmailVerified(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  bool
+	}{
+		{true, true},
+		{false, false},
+		{"true", true},
+		{"TRUE", true},
+		{"false", false},
+		{nil, false},
+		{1.0, false},
+	}
+	for _, tt := range tests {
+		c := &oidcIDTokenClaims{EmailVerified: tt.value}
+		if got := c.emailVerified(); got != tt.want {
+			t.Errorf("emailVerified(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// generatedPasswordLength 系统生成的临时密码的最小长度
+const generatedPasswordLength = 12
+
+// 生成临时密码使用的字符，去掉了容易混淆的 0/O、1/l/I
+const (
+	passwordUpperChars  = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	passwordLowerChars  = "abcdefghijkmnpqrstuvwxyz"
+	passwordDigitChars  = "23456789"
+	passwordSymbolChars = "!@#$%^&*-_=+?"
+)
+
+// ErrPasswordPolicy 密码不符合策略，具体原因附加在错误信息中
+var ErrPasswordPolicy = errors.New("密码不符合安全要求")
+
+//go:embed data/common_passwords.txt
+var builtinCommonPasswords []byte
+
+var (
+	commonPasswordsOnce sync.Once
+	commonPasswords     map[string]struct{}
+)
+
+// PasswordPolicyService 密码策略服务：校验强度、常见密码与历史密码
+type PasswordPolicyService struct {
+	db  *gorm.DB
+	cfg *config.PasswordPolicyConfig
+}
+
+// NewPasswordPolicyService 创建密码策略服务实例
+func NewPasswordPolicyService() *PasswordPolicyService {
+	return &PasswordPolicyService{
+		db:  config.GetDB(),
+		cfg: &config.GlobalConfig.PasswordPolicy,
+	}
+}
+
+// Validate 按策略校验密码，user 提供用户名、邮箱与学号用于禁止密码包含个人信息，可为 nil
+// 不符合时返回包装了 ErrPasswordPolicy 的错误，错误信息列出全部未满足的要求
+func (s *PasswordPolicyService) Validate(password string, user *models.User) error {
+	var problems []string
+
+	if utf8.RuneCountInString(password) < s.cfg.MinLength {
+		problems = append(problems, fmt.Sprintf("长度不能少于%d位", s.cfg.MinLength))
+	}
+	if len(password) > s.cfg.MaxLength {
+		problems = append(problems, fmt.Sprintf("长度不能超过%d个字节", s.cfg.MaxLength))
+	}
+
+	upper, lower, digit, symbol := passwordCharClasses(password)
+	if s.cfg.RequireUpper && !upper {
+		problems = append(problems, "必须包含大写字母")
+	}
+	if s.cfg.RequireLower && !lower {
+		problems = append(problems, "必须包含小写字母")
+	}
+	if s.cfg.RequireDigit && !digit {
+		problems = append(problems, "必须包含数字")
+	}
+	if s.cfg.RequireSymbol && !symbol {
+		problems = append(problems, "必须包含特殊字符")
+	}
+	if classes := countTrue(upper, lower, digit, symbol); classes < s.cfg.MinCharClasses {
+		problems = append(problems, fmt.Sprintf("需包含大写字母、小写字母、数字、特殊字符中的至少%d类", s.cfg.MinCharClasses))
+	}
+
+	if s.cfg.ForbidUserInfo && user != nil && containsUserInfo(password, user) {
+		problems = append(problems, "不能包含用户名、邮箱或学号")
+	}
+	if s.cfg.CheckCommon && s.isCommon(password) {
+		problems = append(problems, "过于常见或已在泄露数据中出现，请更换")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w：%s", ErrPasswordPolicy, strings.Join(problems, "；"))
+	}
+	return nil
+}
+
+// CheckReuse 检查新密码是否与当前密码或最近使用过的密码相同
+func (s *PasswordPolicyService) CheckReuse(userID uint, currentHash, password string) error {
+	if s.cfg.HistoryCount <= 0 {
+		return nil
+	}
+
+	hashes := []string{currentHash}
+	var histories []models.PasswordHistory
+	if err := s.db.Where("user_id = ?", userID).Order("id DESC").Limit(s.cfg.HistoryCount).
+		Find(&histories).Error; err != nil {
+		return err
+	}
+	for _, history := range histories {
+		hashes = append(hashes, history.PasswordHash)
+	}
+
+	for _, hash := range hashes {
+		if hash != "" && bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return fmt.Errorf("%w：不能与最近%d次使用过的密码相同", ErrPasswordPolicy, s.cfg.HistoryCount)
+		}
+	}
+	return nil
+}
+
+// Record 在 tx 中记录新设置的密码哈希，并只保留最近 history_count 条
+func (s *PasswordPolicyService) Record(tx *gorm.DB, userID uint, hash string) error {
+	if s.cfg.HistoryCount <= 0 {
+		return nil
+	}
+	if err := tx.Create(&models.PasswordHistory{UserID: userID, PasswordHash: hash}).Error; err != nil {
+		return err
+	}
+
+	var keep []uint
+	if err := tx.Model(&models.PasswordHistory{}).Where("user_id = ?", userID).
+		Order("id DESC").Limit(s.cfg.HistoryCount).Pluck("id", &keep).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ? AND id NOT IN ?", userID, keep).Delete(&models.PasswordHistory{}).Error
+}
+
+// GeneratePassword 生成满足当前策略的随机临时密码
+func (s *PasswordPolicyService) GeneratePassword() (string, error) {
+	length := generatedPasswordLength
+	if s.cfg.MinLength > length {
+		length = s.cfg.MinLength
+	}
+
+	// 始终包含大小写字母与数字，策略要求时再加入特殊字符
+	sets := []string{passwordUpperChars, passwordLowerChars, passwordDigitChars}
+	if s.cfg.RequireSymbol || s.cfg.MinCharClasses > len(sets) {
+		sets = append(sets, passwordSymbolChars)
+	}
+
+	b := make([]byte, 0, length)
+	for _, set := range sets {
+		c, err := randomChar(set)
+		if err != nil {
+			return "", err
+		}
+		b = append(b, c)
+	}
+	all := strings.Join(sets, "")
+	for len(b) < length {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		b = append(b, c)
+	}
+
+	// 打乱顺序，避免固定位置出现固定类别的字符
+	for i := len(b) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		b[i], b[j.Int64()] = b[j.Int64()], b[i]
+	}
+	return string(b), nil
+}
+
+// Describe 返回当前密码策略及文字说明
+func (s *PasswordPolicyService) Describe() *models.PasswordPolicyResponse {
+	rules := []string{fmt.Sprintf("长度为%d到%d位", s.cfg.MinLength, s.cfg.MaxLength)}
+	var required []string
+	if s.cfg.RequireUpper {
+		required = append(required, "大写字母")
+	}
+	if s.cfg.RequireLower {
+		required = append(required, "小写字母")
+	}
+	if s.cfg.RequireDigit {
+		required = append(required, "数字")
+	}
+	if s.cfg.RequireSymbol {
+		required = append(required, "特殊字符")
+	}
+	if len(required) > 0 {
+		rules = append(rules, "必须包含"+strings.Join(required, "、"))
+	}
+	if s.cfg.MinCharClasses > 1 {
+		rules = append(rules, fmt.Sprintf("需包含大写字母、小写字母、数字、特殊字符中的至少%d类", s.cfg.MinCharClasses))
+	}
+	if s.cfg.ForbidUserInfo {
+		rules = append(rules, "不能包含用户名、邮箱或学号")
+	}
+	if s.cfg.CheckCommon {
+		rules = append(rules, "不能使用常见或已泄露的密码")
+	}
+	if s.cfg.HistoryCount > 0 {
+		rules = append(rules, fmt.Sprintf("不能与最近%d次使用过的密码相同", s.cfg.HistoryCount))
+	}
+
+	return &models.PasswordPolicyResponse{
+		MinLength:      s.cfg.MinLength,
+		MaxLength:      s.cfg.MaxLength,
+		RequireUpper:   s.cfg.RequireUpper,
+		RequireLower:   s.cfg.RequireLower,
+		RequireDigit:   s.cfg.RequireDigit,
+		RequireSymbol:  s.cfg.RequireSymbol,
+		MinCharClasses: s.cfg.MinCharClasses,
+		ForbidUserInfo: s.cfg.ForbidUserInfo,
+		HistoryCount:   s.cfg.HistoryCount,
+		Rules:          rules,
+	}
+}
+
+// isCommon 判断密码是否在常见密码列表中
+// 去掉末尾的数字与符号后再比较一次，拦截 password2024! 这类简单变体
+func (s *PasswordPolicyService) isCommon(password string) bool {
+	commonPasswordsOnce.Do(func() {
+		commonPasswords = make(map[string]struct{})
+		loadPasswordList(bytes.NewReader(builtinCommonPasswords), commonPasswords)
+		if s.cfg.CommonListFile == "" {
+			return
+		}
+		f, err := os.Open(s.cfg.CommonListFile)
+		if err != nil {
+			logger.Warnf("加载弱密码列表失败，仅使用内置列表: %v", err)
+			return
+		}
+		defer f.Close()
+		loadPasswordList(f, commonPasswords)
+	})
+
+	normalized := strings.ToLower(password)
+	if _, ok := commonPasswords[normalized]; ok {
+		return true
+	}
+	base := strings.TrimRightFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if base == "" || base == normalized {
+		return false
+	}
+	_, ok := commonPasswords[base]
+	return ok
+}
+
+// loadPasswordList 读取每行一个的密码列表，忽略空行与 # 开头的注释
+func loadPasswordList(r io.Reader, set map[string]struct{}) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Warnf("读取弱密码列表失败: %v", err)
+	}
+}
+
+// passwordCharClasses 判断密码包含的字符类别
+func passwordCharClasses(password string) (upper, lower, digit, symbol bool) {
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	return
+}
+
+// countTrue 统计为 true 的个数
+func countTrue(values ...bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
+
+// containsUserInfo 密码是否包含用户名、邮箱前缀或学号（不区分大小写，过短的片段不检查）
+func containsUserInfo(password string, user *models.User) bool {
+	normalized := strings.ToLower(password)
+	localPart := strings.SplitN(user.Email, "@", 2)[0]
+	for _, info := range []string{user.Username, localPart, user.StudentID} {
+		info = strings.ToLower(strings.TrimSpace(info))
+		if utf8.RuneCountInString(info) >= 3 && strings.Contains(normalized, info) {
+			return true
+		}
+	}
+	return false
+}
+
+// randomChar 从字符集中随机取一个字符
+func randomChar(set string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+	if err != nil {
+		return 0, err
+	}
+	return set[n.Int64()], nil
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+)
+
+// defaultPolicy 与 config.yaml 默认值一致的密码策略
+func defaultPolicy() config.PasswordPolicyConfig {
+	return config.PasswordPolicyConfig{
+		MinLength:      8,
+		MaxLength:      72,
+		MinCharClasses: 2,
+		ForbidUserInfo: true,
+		CheckCommon:    true,
+		HistoryCount:   5,
+	}
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	strict := defaultPolicy()
+	strict.RequireUpper = true
+	strict.RequireLower = true
+	strict.RequireDigit = true
+	strict.RequireSymbol = true
+
+	relaxed := defaultPolicy()
+	relaxed.MinCharClasses = 0
+	relaxed.ForbidUserInfo = false
+	relaxed.CheckCommon = false
+
+	user := &models.User{Username: "alice", Email: "zhangsan@example.com", StudentID: "2024001"}
+
+	tests := []struct {
+		name     string
+		cfg      config.PasswordPolicyConfig
+		password string
+		user     *models.User
+		wantErr  string
+	}{
+		{"满足默认策略", defaultPolicy(), "Lab-Recruit7", user, ""},
+		{"长度不足", defaultPolicy(), "Ab3$x", nil, "长度不能少于8位"},
+		{"中文按字符计算长度", defaultPolicy(), "实验室招新平台1", nil, ""},
+		{"超过最大字节数", defaultPolicy(), strings.Repeat("Ab3", 25), nil, "长度不能超过72个字节"},
+		{"字符类别不足", defaultPolicy(), "abcdefghij", nil, "至少2类"},
+		{"严格策略缺少大写与特殊字符", strict, "labrecruit7", nil, "必须包含大写字母；必须包含特殊字符"},
+		{"严格策略满足", strict, "Lab-Recruit7", nil, ""},
+		{"包含用户名", defaultPolicy(), "Alice2024!x", user, "不能包含用户名、邮箱或学号"},
+		{"包含邮箱前缀", defaultPolicy(), "zhangsan#99", user, "不能包含用户名、邮箱或学号"},
+		{"包含学号", defaultPolicy(), "x2024001y", user, "不能包含用户名、邮箱或学号"},
+		{"未提供用户时不检查个人信息", defaultPolicy(), "Alice2024!x", nil, ""},
+		{"常见密码", defaultPolicy(), "Password", nil, "过于常见"},
+		{"常见密码加数字符号后缀", defaultPolicy(), "password2024!", nil, "过于常见"},
+		{"放宽策略允许常见密码", relaxed, "password", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			s := &PasswordPolicyService{cfg: &cfg}
+			err := s.Validate(tt.password, tt.user)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate(%q) error = %v, want nil", tt.password, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate(%q) = nil, want error containing %q", tt.password, tt.wantErr)
+			}
+			if !errors.Is(err, ErrPasswordPolicy) {
+				t.Errorf("错误未包装 ErrPasswordPolicy: %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate(%q) error = %q, want containing %q", tt.password, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPasswordCharClasses(t *testing.T) {
+	tests := []struct {
+		password                    string
+		upper, lower, digit, symbol bool
+	}{
+		{"", false, false, false, false},
+		{"ABC", true, false, false, false},
+		{"abc", false, true, false, false},
+		{"123", false, false, true, false},
+		{"!@#", false, false, false, true},
+		{"Ab1!", true, true, true, true},
+		{"a b", false, true, false, false},
+		{"密码", false, false, false, true},
+	}
+	for _, tt := range tests {
+		upper, lower, digit, symbol := passwordCharClasses(tt.password)
+		if upper != tt.upper || lower != tt.lower || digit != tt.digit || symbol != tt.symbol {
+			t.Errorf("passwordCharClasses(%q) = (%v, %v, %v, %v), want (%v, %v, %v, %v)",
+				tt.password, upper, lower, digit, symbol, tt.upper, tt.lower, tt.digit, tt.symbol)
+		}
+	}
+}
+
+func TestContainsUserInfo(t *testing.T) {
+	user := &models.User{Username: "Bob", Email: "ab@example.com", StudentID: "20240001"}
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{"包含用户名（不区分大小写）", "xxBOBxx1", true},
+		{"邮箱前缀过短不检查", "xxabxx12", false},
+		{"包含学号", "pw20240001", true},
+		{"不包含", "Lab-Recruit7", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsUserInfo(tt.password, user); got != tt.want {
+				t.Errorf("containsUserInfo(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyGeneratePassword(t *testing.T) {
+	long := defaultPolicy()
+	long.MinLength = 20
+
+	symbols := defaultPolicy()
+	symbols.RequireUpper = true
+	symbols.RequireLower = true
+	symbols.RequireDigit = true
+	symbols.RequireSymbol = true
+
+	fourClasses := defaultPolicy()
+	fourClasses.MinCharClasses = 4
+
+	tests := []struct {
+		name    string
+		cfg     config.PasswordPolicyConfig
+		wantLen int
+	}{
+		{"默认策略", defaultPolicy(), generatedPasswordLength},
+		{"最小长度大于默认长度", long, 20},
+		{"要求特殊字符", symbols, generatedPasswordLength},
+		{"要求四类字符", fourClasses, generatedPasswordLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			s := &PasswordPolicyService{cfg: &cfg}
+			for i := 0; i < 20; i++ {
+				password, err := s.GeneratePassword()
+				if err != nil {
+					t.Fatalf("GeneratePassword() error = %v", err)
+				}
+				if len(password) != tt.wantLen {
+					t.Errorf("长度 = %d, want %d", len(password), tt.wantLen)
+				}
+				if strings.ContainsAny(password, "0O1lI") {
+					t.Errorf("生成的密码 %q 包含易混淆字符", password)
+				}
+				if err := s.Validate(password, nil); err != nil {
+					t.Errorf("生成的密码 %q 不满足策略: %v", password, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyDescribe(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.PasswordPolicyConfig
+		want []string
+	}{
+		{"默认策略", defaultPolicy(), []string{"长度为8到72位", "至少2类", "不能包含用户名、邮箱或学号", "常见或已泄露", "最近5次"}},
+		{"要求大写与数字", config.PasswordPolicyConfig{MinLength: 10, MaxLength: 64, RequireUpper: true, RequireDigit: true},
+			[]string{"长度为10到64位", "必须包含大写字母、数字"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			resp := (&PasswordPolicyService{cfg: &cfg}).Describe()
+			rules := strings.Join(resp.Rules, "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(rules, want) {
+					t.Errorf("规则说明缺少 %q:\n%s", want, rules)
+				}
+			}
+			if resp.MinLength != cfg.MinLength || resp.HistoryCount != cfg.HistoryCount {
+				t.Errorf("Describe() = %+v, 与配置不一致", resp)
+			}
+		})
+	}
+}
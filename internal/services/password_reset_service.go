@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// ErrInvalidResetToken 重置链接无效或已过期
+var ErrInvalidResetToken = errors.New("重置链接无效或已过期，请重新申请")
+
+// PasswordResetService 找回密码服务
+type PasswordResetService struct {
+	db     *gorm.DB
+	cfg    *config.PasswordResetConfig
+	policy *PasswordPolicyService
+}
+
+// NewPasswordResetService 创建找回密码服务实例
+func NewPasswordResetService() *PasswordResetService {
+	return &PasswordResetService{
+		db:     config.GetDB(),
+		cfg:    &config.GlobalConfig.PasswordReset,
+		policy: NewPasswordPolicyService(),
+	}
+}
+
+// RequestReset 为账户生成重置令牌，返回用户及明文令牌
+// 账户不存在、不可用或申请过于频繁时返回 nil 用户，调用方不得向请求方透露差异
+func (s *PasswordResetService) RequestReset(email, ip string) (*models.User, string, error) {
+	var user models.User
+	if err := s.db.Where("email = ?", strings.ToLower(strings.TrimSpace(email))).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	if !user.IsActive() {
+		return nil, "", nil
+	}
+
+	var latest models.PasswordResetToken
+	err := s.db.Where("user_id = ?", user.ID).Order("created_at DESC").First(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", err
+	}
+	if err == nil && time.Since(latest.CreatedAt) < s.cfg.RequestInterval {
+		logger.Warnf("重置密码申请过于频繁: user_id=%d", user.ID)
+		return nil, "", nil
+	}
+
+	token, err := generateSecureToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// 只保留最新一次申请的令牌
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).
+			Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: hashToken(token),
+			ExpiresAt: time.Now().Add(s.cfg.TokenTTL),
+			RequestIP: ip,
+		}).Error
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("保存重置令牌失败: %w", err)
+	}
+
+	logger.Infof("生成重置密码令牌: user_id=%d", user.ID)
+	return &user, token, nil
+}
+
+// ResetPassword 使用令牌设置新密码，令牌随即作废并清除该用户其余未使用的令牌
+func (s *PasswordResetService) ResetPassword(token, newPassword string) (*models.User, error) {
+	var record models.PasswordResetToken
+	if err := s.db.Where("token_hash = ?", hashToken(token)).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidResetToken
+		}
+		return nil, err
+	}
+	if record.UsedAt != nil || time.Now().After(record.ExpiresAt) {
+		return nil, ErrInvalidResetToken
+	}
+
+	var user models.User
+	if err := s.db.First(&user, record.UserID).Error; err != nil || !user.IsActive() {
+		return nil, ErrInvalidResetToken
+	}
+
+	// 令牌保持有效，用户可换一个符合要求的密码重新提交
+	if err := s.policy.Validate(newPassword, &user); err != nil {
+		return nil, err
+	}
+	if err := s.policy.CheckReuse(user.ID, user.Password, newPassword); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Errorf("密码加密失败: %v", err)
+		return nil, errors.New("密码加密失败")
+	}
+
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// 条件更新保证并发提交同一令牌时只有一次成功
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", record.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidResetToken
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+			Update("password", string(hashedPassword)).Error; err != nil {
+			return err
+		}
+		if err := s.policy.Record(tx, user.ID, string(hashedPassword)); err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND used_at IS NULL", user.ID).
+			Delete(&models.PasswordResetToken{}).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidResetToken) {
+			return nil, err
+		}
+		logger.Errorf("重置密码失败: %v", err)
+		return nil, errors.New("重置密码失败")
+	}
+
+	logger.Infof("用户通过邮件重置密码: user_id=%d", user.ID)
+	return &user, nil
+}
+
+// ResetURL 生成重置密码页面链接
+func (s *PasswordResetService) ResetURL(token string) string {
+	base := s.cfg.URL
+	if base == "" {
+		base = strings.TrimRight(config.GlobalConfig.Server.BaseURL, "/") + "/reset-password"
+	}
+
+	sep := "?"
+	if strings.Contains(base, "?") {
+		sep = "&"
+	}
+	return base + sep + "token=" + url.QueryEscape(token)
+}
+
+// TokenTTL 重置链接有效期
+func (s *PasswordResetService) TokenTTL() time.Duration {
+	return s.cfg.TokenTTL
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// ErrErasureRequestPending 同一邮箱已有待审核的删除请求
+var ErrErasureRequestPending = errors.New("已有待审核的删除请求，请勿重复提交")
+
+// PrivacyService 申请人个人数据导出与删除服务
+type PrivacyService struct {
+	db               *gorm.DB
+	interviewService *InterviewApplicationService
+	uploadService    *UploadService
+	auditService     *AuditService
+}
+
+// NewPrivacyService 创建个人数据服务实例
+func NewPrivacyService() *PrivacyService {
+	return &PrivacyService{
+		db:               config.GetDB(),
+		interviewService: NewInterviewApplicationService(),
+		uploadService:    NewUploadService(),
+		auditService:     NewAuditService(),
+	}
+}
+
+// ExportData 汇总邮箱对应的全部个人数据（包括回收站中的申请）
+func (s *PrivacyService) ExportData(email string) (*models.PersonalDataExport, []models.FileUpload, error) {
+	var applications []models.InterviewApplication
+	if err := s.db.Unscoped().Preload("Attachments").
+		Where("email = ?", email).Order("created_at ASC").
+		Find(&applications).Error; err != nil {
+		return nil, nil, err
+	}
+
+	export := &models.PersonalDataExport{
+		Email:           email,
+		ExportedAt:      time.Now(),
+		Applications:    make([]models.InterviewApplicationResponse, 0, len(applications)),
+		StatusHistory:   []models.ApplicationStatusLog{},
+		Offers:          []models.Offer{},
+		PendingUploads:  []models.FileUploadResponse{},
+		EmailsSent:      []models.EmailLog{},
+		ErasureRequests: []models.ErasureRequest{},
+	}
+
+	var files []models.FileUpload
+	ids := make([]uint, 0, len(applications))
+	for i := range applications {
+		export.Applications = append(export.Applications, *applications[i].ToResponse())
+		files = append(files, applications[i].Attachments...)
+		ids = append(ids, applications[i].ID)
+	}
+
+	if len(ids) > 0 {
+		if err := s.db.Where("application_id IN ?", ids).Order("created_at ASC").Find(&export.StatusHistory).Error; err != nil {
+			return nil, nil, err
+		}
+		if err := s.db.Where("application_id IN ?", ids).Order("created_at ASC").Find(&export.Offers).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+
+	pending, err := s.uploadService.ListUnlinkedByEmail(email)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range pending {
+		export.PendingUploads = append(export.PendingUploads, *pending[i].ToResponse())
+	}
+	files = append(files, pending...)
+
+	if err := s.db.Where("recipient = ?", email).Order("created_at ASC").Find(&export.EmailsSent).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := s.db.Where("email = ?", email).Order("created_at ASC").Find(&export.ErasureRequests).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return export, files, nil
+}
+
+// BuildExportArchive 将导出数据和附件原文件打包为ZIP
+func (s *PrivacyService) BuildExportArchive(export *models.PersonalDataExport, files []models.FileUpload) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	w, err := zw.Create("data.json")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+
+	for i := range files {
+		name := fmt.Sprintf("attachments/%d_%s", files[i].ID, filepath.Base(files[i].OriginalName))
+		if err := s.addFile(zw, name, s.uploadService.GetFullPath(&files[i])); err != nil {
+			logger.Errorf("打包附件失败: ID=%d, err=%v", files[i].ID, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// addFile 将磁盘文件写入ZIP
+func (s *PrivacyService) addFile(zw *zip.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// CreateErasureRequest 提交个人数据删除请求
+func (s *PrivacyService) CreateErasureRequest(email, reason string) (*models.ErasureRequest, error) {
+	var count int64
+	if err := s.db.Model(&models.ErasureRequest{}).Where("email = ? AND status = ?", email, "pending").Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrErasureRequestPending
+	}
+
+	request := &models.ErasureRequest{
+		Email:  email,
+		Reason: reason,
+		Status: "pending",
+	}
+	if err := s.db.Create(request).Error; err != nil {
+		logger.Errorf("创建删除请求失败: %v", err)
+		return nil, errors.New("提交删除请求失败")
+	}
+
+	logger.Infof("收到个人数据删除请求: ID=%d", request.ID)
+	return request, nil
+}
+
+// ListErasureRequests 获取删除请求列表
+func (s *PrivacyService) ListErasureRequests(page, size int, status string) (*models.ErasureRequestListResponse, error) {
+	var requests []models.ErasureRequest
+	var total int64
+
+	query := s.db.Model(&models.ErasureRequest{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Offset(offset).Limit(size).Order("created_at DESC").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.ErasureRequestListResponse{
+		Total: total,
+		Page:  page,
+		Size:  size,
+		List:  requests,
+	}, nil
+}
+
+// ReviewErasureRequest 审核删除请求，批准时按指定方式删除或匿名化数据
+func (s *PrivacyService) ReviewErasureRequest(id uint, req *models.ErasureReviewRequest, operatorID *uint) (*models.ErasureRequest, error) {
+	var request models.ErasureRequest
+	if err := s.db.First(&request, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("删除请求不存在")
+		}
+		return nil, err
+	}
+	if !request.IsPending() {
+		return nil, errors.New("删除请求已处理")
+	}
+
+	details := models.JSONMap{}
+	if req.Approve {
+		mode := req.Mode
+		if mode == "" {
+			mode = "delete"
+		}
+		counts, err := s.erase(request.Email, mode)
+		if err != nil {
+			logger.Errorf("执行个人数据删除失败: ID=%d, err=%v", id, err)
+			return nil, errors.New("执行个人数据删除失败")
+		}
+		request.Status = "approved"
+		request.Mode = mode
+		details = counts
+	} else {
+		request.Status = "rejected"
+	}
+
+	now := time.Now()
+	request.ReviewedBy = operatorID
+	request.ReviewRemarks = req.Remarks
+	request.ReviewedAt = &now
+	request.UpdatedAt = now
+	if err := s.db.Save(&request).Error; err != nil {
+		logger.Errorf("更新删除请求失败: %v", err)
+		return nil, errors.New("更新删除请求失败")
+	}
+
+	details["status"] = request.Status
+	details["mode"] = request.Mode
+	s.auditService.Record(AuditEntry{
+		UserID:       operatorID,
+		Action:       "privacy.erasure",
+		ResourceType: "erasure_request",
+		ResourceID:   &request.ID,
+		Details:      details,
+	})
+
+	logger.Infof("删除请求已处理: ID=%d, 结果=%s, 方式=%s", request.ID, request.Status, request.Mode)
+	return &request, nil
+}
+
+// erase 删除或匿名化邮箱对应的全部个人数据，返回各类数据的处理数量
+func (s *PrivacyService) erase(email, mode string) (models.JSONMap, error) {
+	var ids []uint
+	if err := s.db.Unscoped().Model(&models.InterviewApplication{}).
+		Where("email = ?", email).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	if len(ids) > 0 {
+		var err error
+		if mode == "anonymize" {
+			err = s.interviewService.AnonymizeApplications(ids)
+		} else {
+			err = s.interviewService.purge(ids)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	uploads, err := s.uploadService.DeleteUnlinkedByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.db.Where("recipient = ?", email).Delete(&models.EmailLog{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	// 验证码发送记录按邮箱 HMAC 保存，没有申请的邮箱也可能存在
+	codeLogs := s.db.Where("email_hash = ?", codeLogEmailHash(email)).Delete(&models.VerificationCodeLog{})
+	if codeLogs.Error != nil {
+		return nil, codeLogs.Error
+	}
+
+	return models.JSONMap{
+		"applications":           len(ids),
+		"pending_uploads":        uploads,
+		"email_logs":             result.RowsAffected,
+		"verification_code_logs": codeLogs.RowsAffected,
+	}, nil
+}
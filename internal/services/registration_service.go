@@ -0,0 +1,224 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+var (
+	// ErrRegistrationDisabled 未开放自助注册
+	ErrRegistrationDisabled = errors.New("暂未开放注册")
+	// ErrInvalidVerifyToken 验证链接无效或已过期
+	ErrInvalidVerifyToken = errors.New("验证链接无效或已过期")
+	// ErrResendTooFrequent 重新发送过于频繁
+	ErrResendTooFrequent = errors.New("验证邮件发送过于频繁，请稍后再试")
+)
+
+// RegistrationService 学生注册服务
+type RegistrationService struct {
+	db     *gorm.DB
+	cfg    *config.RegisterConfig
+	policy *PasswordPolicyService
+	secret []byte
+}
+
+// NewRegistrationService 创建学生注册服务实例
+func NewRegistrationService() *RegistrationService {
+	return &RegistrationService{
+		db:     config.GetDB(),
+		cfg:    &config.GlobalConfig.Register,
+		policy: NewPasswordPolicyService(),
+		secret: config.GlobalConfig.JWT.DeriveKey("email-verification"),
+	}
+}
+
+// Register 注册学生账户，账户在邮箱验证前处于 pending 状态，返回用户及验证令牌
+func (s *RegistrationService) Register(req *models.UserRegisterRequest) (*models.User, string, error) {
+	if !s.cfg.Enabled {
+		return nil, "", ErrRegistrationDisabled
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	var count int64
+	if err := s.db.Unscoped().Model(&models.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return nil, "", err
+	}
+	if count > 0 {
+		return nil, "", errors.New("邮箱已被注册，如未收到验证邮件请重新发送")
+	}
+	if err := s.db.Unscoped().Model(&models.User{}).Where("username = ?", req.Username).Count(&count).Error; err != nil {
+		return nil, "", err
+	}
+	if count > 0 {
+		return nil, "", errors.New("用户名已存在")
+	}
+
+	if err := s.policy.Validate(req.Password, &models.User{Username: req.Username, Email: email}); err != nil {
+		return nil, "", err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Errorf("密码加密失败: %v", err)
+		return nil, "", errors.New("密码加密失败")
+	}
+
+	now := time.Now()
+	user := &models.User{
+		Username:           req.Username,
+		Email:              email,
+		Password:           string(hashedPassword),
+		Role:               "student",
+		Status:             "pending",
+		VerificationSentAt: &now,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return s.policy.Record(tx, user.ID, user.Password)
+	})
+	if err != nil {
+		logger.Errorf("注册用户失败: %v", err)
+		return nil, "", errors.New("注册失败")
+	}
+
+	logger.Infof("学生注册成功，等待邮箱验证: ID=%d", user.ID)
+	return user, s.signToken(user, now.Add(s.cfg.VerifyTTL)), nil
+}
+
+// VerifyEmail 校验验证令牌并激活账户
+func (s *RegistrationService) VerifyEmail(token string) (*models.User, error) {
+	user, err := s.parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsPendingVerification() {
+		if user.EmailVerifiedAt != nil {
+			return user, nil
+		}
+		return nil, ErrInvalidVerifyToken
+	}
+
+	now := time.Now()
+	result := s.db.Model(&models.User{}).
+		Where("id = ? AND status = ?", user.ID, "pending").
+		Updates(map[string]interface{}{
+			"status":            "active",
+			"email_verified_at": now,
+		})
+	if result.Error != nil {
+		logger.Errorf("激活账户失败: %v", result.Error)
+		return nil, errors.New("激活账户失败")
+	}
+
+	user.Status = "active"
+	user.EmailVerifiedAt = &now
+	logger.Infof("邮箱验证成功，账户已激活: ID=%d", user.ID)
+	return user, nil
+}
+
+// ResendVerification 为待验证账户重新生成验证令牌，账户不存在或已验证时返回 nil 用户
+func (s *RegistrationService) ResendVerification(email string) (*models.User, string, error) {
+	var user models.User
+	if err := s.db.Where("email = ?", strings.ToLower(strings.TrimSpace(email))).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	if !user.IsPendingVerification() {
+		return nil, "", nil
+	}
+
+	now := time.Now()
+	if user.VerificationSentAt != nil && now.Sub(*user.VerificationSentAt) < s.cfg.ResendInterval {
+		return nil, "", ErrResendTooFrequent
+	}
+
+	if err := s.db.Model(&user).Update("verification_sent_at", now).Error; err != nil {
+		return nil, "", err
+	}
+	user.VerificationSentAt = &now
+
+	return &user, s.signToken(&user, now.Add(s.cfg.VerifyTTL)), nil
+}
+
+// VerifyURL 生成邮箱验证链接
+func (s *RegistrationService) VerifyURL(token string) string {
+	baseURL := strings.TrimRight(config.GlobalConfig.Server.BaseURL, "/")
+	return fmt.Sprintf("%s/api/v1/auth/verify-email?token=%s", baseURL, token)
+}
+
+// VerifyTTL 验证链接有效期
+func (s *RegistrationService) VerifyTTL() time.Duration {
+	return s.cfg.VerifyTTL
+}
+
+// signToken 生成签名验证令牌，格式为 base64(用户ID.过期时间).签名
+// 签名绑定邮箱与最近一次发送时间，重新发送后旧链接随即失效
+func (s *RegistrationService) signToken(user *models.User, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%d.%d", user.ID, expiresAt.Unix())
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + s.signature(user, payload)
+}
+
+// parseToken 解析并校验验证令牌
+func (s *RegistrationService) parseToken(token string) (*models.User, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidVerifyToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidVerifyToken
+	}
+	payload := string(raw)
+
+	idStr, expStr, ok := strings.Cut(payload, ".")
+	if !ok {
+		return nil, ErrInvalidVerifyToken
+	}
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return nil, ErrInvalidVerifyToken
+	}
+	exp, err := strconv.ParseInt(expStr, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return nil, ErrInvalidVerifyToken
+	}
+
+	var user models.User
+	if err := s.db.First(&user, uint(id)).Error; err != nil {
+		return nil, ErrInvalidVerifyToken
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.signature(&user, payload))) {
+		return nil, ErrInvalidVerifyToken
+	}
+	return &user, nil
+}
+
+// signature 计算验证令牌签名
+func (s *RegistrationService) signature(user *models.User, payload string) string {
+	var sentAt int64
+	if user.VerificationSentAt != nil {
+		sentAt = user.VerificationSentAt.Unix()
+	}
+
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "email-verify:%s:%s:%d", payload, user.Email, sentAt)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
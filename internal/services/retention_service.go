@@ -0,0 +1,158 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// anonymizeBatchSize 每批匿名化的申请数量
+const anonymizeBatchSize = 200
+
+// decidedAtExpr 申请进入当前状态的时间，无状态记录时退化为更新时间
+const decidedAtExpr = "COALESCE((SELECT MAX(l.created_at) FROM application_status_logs l " +
+	"WHERE l.application_id = interview_applications.id AND l.to_status = interview_applications.status), " +
+	"interview_applications.updated_at)"
+
+// RetentionService 个人信息保留策略服务
+type RetentionService struct {
+	db               *gorm.DB
+	cfg              *config.RetentionConfig
+	interviewService *InterviewApplicationService
+	auditService     *AuditService
+}
+
+// NewRetentionService 创建保留策略服务实例
+func NewRetentionService() *RetentionService {
+	return &RetentionService{
+		db:               config.GetDB(),
+		cfg:              &config.GlobalConfig.Retention,
+		interviewService: NewInterviewApplicationService(),
+		auditService:     NewAuditService(),
+	}
+}
+
+// Preview 预演：列出按当前策略将被匿名化的申请，不做任何修改
+func (s *RetentionService) Preview() (*models.RetentionReport, error) {
+	return s.evaluate(true)
+}
+
+// Run 执行匿名化并写入审计记录，trigger 标明触发方式（scheduled/manual）
+func (s *RetentionService) Run(operatorID *uint, trigger string) (*models.RetentionReport, error) {
+	report, err := s.evaluate(false)
+
+	details := models.JSONMap{
+		"trigger": trigger,
+	}
+	if report != nil {
+		summary := make([]map[string]interface{}, 0, len(report.Policies))
+		ids := make([]uint, 0, report.Total)
+		for _, policy := range report.Policies {
+			summary = append(summary, map[string]interface{}{
+				"status":     policy.Status,
+				"after_days": policy.AfterDays,
+				"count":      policy.Count,
+			})
+			for _, candidate := range policy.Candidates {
+				ids = append(ids, candidate.ApplicationID)
+			}
+		}
+		details["total"] = report.Total
+		details["policies"] = summary
+		details["application_ids"] = ids
+	}
+	if err != nil {
+		details["error"] = err.Error()
+	}
+
+	s.auditService.Record(AuditEntry{
+		UserID:       operatorID,
+		Action:       "retention.anonymize",
+		ResourceType: "interview_application",
+		Details:      details,
+	})
+
+	return report, err
+}
+
+// evaluate 按策略查找并（非预演时）匿名化到期申请
+func (s *RetentionService) evaluate(dryRun bool) (*models.RetentionReport, error) {
+	report := &models.RetentionReport{
+		DryRun:      dryRun,
+		Policies:    make([]models.RetentionPolicyReport, 0, len(s.cfg.Policies)),
+		GeneratedAt: time.Now(),
+	}
+
+	for _, policy := range s.cfg.Policies {
+		cutoff := time.Now().AddDate(0, 0, -policy.AfterDays)
+		candidates, err := s.findCandidates(policy.Status, cutoff)
+		if err != nil {
+			return report, fmt.Errorf("查询待匿名化申请失败: %w", err)
+		}
+
+		if !dryRun {
+			for start := 0; start < len(candidates); start += anonymizeBatchSize {
+				end := start + anonymizeBatchSize
+				if end > len(candidates) {
+					end = len(candidates)
+				}
+				ids := make([]uint, 0, end-start)
+				for _, candidate := range candidates[start:end] {
+					ids = append(ids, candidate.ApplicationID)
+				}
+				if err := s.interviewService.AnonymizeApplications(ids); err != nil {
+					return report, fmt.Errorf("匿名化申请失败: %w", err)
+				}
+			}
+			if len(candidates) > 0 {
+				logger.Infof("个人信息匿名化完成: 状态=%s, 数量=%d", policy.Status, len(candidates))
+			}
+		}
+
+		report.Policies = append(report.Policies, models.RetentionPolicyReport{
+			Status:     policy.Status,
+			AfterDays:  policy.AfterDays,
+			Cutoff:     cutoff,
+			Count:      len(candidates),
+			Candidates: candidates,
+		})
+		report.Total += len(candidates)
+	}
+
+	return report, nil
+}
+
+// findCandidates 查找进入指定状态早于截止时间且尚未匿名化的申请（包括回收站中的申请）
+func (s *RetentionService) findCandidates(status string, cutoff time.Time) ([]models.RetentionCandidate, error) {
+	var rows []struct {
+		ID        uint
+		Status    string
+		DecidedAt time.Time
+		DeletedAt gorm.DeletedAt
+	}
+
+	if err := s.db.Unscoped().Model(&models.InterviewApplication{}).
+		Select("id, status, deleted_at, "+decidedAtExpr+" AS decided_at").
+		Where("status = ? AND anonymized_at IS NULL", status).
+		Where(decidedAtExpr+" < ?", cutoff).
+		Order("id ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	candidates := make([]models.RetentionCandidate, len(rows))
+	for i, row := range rows {
+		candidates[i] = models.RetentionCandidate{
+			ApplicationID: row.ID,
+			Status:        row.Status,
+			DecidedAt:     row.DecidedAt,
+			Deleted:       row.DeletedAt.Valid,
+		}
+	}
+
+	return candidates, nil
+}
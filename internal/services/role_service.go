@@ -0,0 +1,165 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// RoleService 角色服务
+type RoleService struct {
+	db *gorm.DB
+}
+
+// NewRoleService 创建角色服务实例
+func NewRoleService() *RoleService {
+	return &RoleService{
+		db: config.GetDB(),
+	}
+}
+
+// EnsureDefaultRoles 创建缺失的系统预置角色，已存在的角色保持不变
+func (s *RoleService) EnsureDefaultRoles() error {
+	for _, role := range models.DefaultRoles {
+		role := role
+		if err := s.db.Where("name = ?", role.Name).FirstOrCreate(&role).Error; err != nil {
+			return fmt.Errorf("初始化角色 %s 失败: %w", role.Name, err)
+		}
+	}
+	return nil
+}
+
+// ListRoles 获取全部角色
+func (s *RoleService) ListRoles() ([]models.Role, error) {
+	var roles []models.Role
+	if err := s.db.Order("id ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetRoleByID 根据ID获取角色
+func (s *RoleService) GetRoleByID(id uint) (*models.Role, error) {
+	var role models.Role
+	if err := s.db.First(&role, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("角色不存在")
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// RoleUserIDs 获取被分配了指定角色的用户ID
+func (s *RoleService) RoleUserIDs(roleID uint) ([]uint, error) {
+	var ids []uint
+	if err := s.db.Table("user_roles").Where("role_id = ?", roleID).Pluck("user_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// CreateRole 创建角色
+func (s *RoleService) CreateRole(req *models.RoleCreateRequest) (*models.Role, error) {
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.Model(&models.Role{}).Where("name = ?", req.Name).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("角色名称已存在")
+	}
+
+	role := &models.Role{
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+		Permissions: permissions,
+	}
+	if err := s.db.Create(role).Error; err != nil {
+		logger.Errorf("创建角色失败: %v", err)
+		return nil, errors.New("创建角色失败")
+	}
+
+	logger.Infof("角色创建成功: ID=%d, 名称=%s", role.ID, role.Name)
+	return role, nil
+}
+
+// UpdateRole 更新角色的展示信息与权限
+func (s *RoleService) UpdateRole(id uint, req *models.RoleUpdateRequest) (*models.Role, error) {
+	role, err := s.GetRoleByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DisplayName != nil {
+		role.DisplayName = *req.DisplayName
+	}
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+	if req.Permissions != nil {
+		permissions, err := normalizePermissions(req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		role.Permissions = permissions
+	}
+
+	if err := s.db.Save(role).Error; err != nil {
+		logger.Errorf("更新角色失败: %v", err)
+		return nil, errors.New("更新角色失败")
+	}
+
+	return role, nil
+}
+
+// DeleteRole 删除角色并解除其与用户的关联，系统预置角色不可删除
+func (s *RoleService) DeleteRole(id uint) error {
+	role, err := s.GetRoleByID(id)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return errors.New("系统预置角色不可删除")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM user_roles WHERE role_id = ?", role.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(role).Error; err != nil {
+			logger.Errorf("删除角色失败: %v", err)
+			return errors.New("删除角色失败")
+		}
+		return nil
+	})
+}
+
+// normalizePermissions 校验并去重权限标识
+func normalizePermissions(permissions []string) (models.StringList, error) {
+	seen := make(map[string]struct{}, len(permissions))
+	result := make(models.StringList, 0, len(permissions))
+	for _, p := range permissions {
+		if !models.IsValidPermission(p) {
+			return nil, fmt.Errorf("未知的权限: %s", p)
+		}
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		result = append(result, p)
+	}
+	if len(result) == 0 {
+		return nil, errors.New("角色至少需要包含一项权限")
+	}
+	return result, nil
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// ssoLoginCodePrefix 单点登录一次性登录码的Redis键前缀
+const ssoLoginCodePrefix = "auth:sso_code:"
+
+// ssoLoginCodeTTL 一次性登录码有效期，前端回调页收到后应立即兑换
+const ssoLoginCodeTTL = time.Minute
+
+// 单点登录方式
+const (
+	SSOProviderOIDC = "oidc"
+	SSOProviderCAS  = "cas"
+)
+
+var (
+	// ErrSSOLoginCodeInvalid 登录码无效或已使用
+	ErrSSOLoginCodeInvalid = errors.New("登录码无效或已过期，请重新登录")
+	// ErrSSOAccountDisabled 账户不可用
+	ErrSSOAccountDisabled = errors.New("账户已被禁用")
+	// ErrSSOAccountNotFound 账户不存在且未开启自动创建
+	ErrSSOAccountNotFound = errors.New("该身份尚未开通本平台账户，请联系管理员")
+)
+
+// ssoUsernamePattern 用户名中保留的字符
+var ssoUsernamePattern = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// ssoLogin 一次性登录码对应的登录结果
+type ssoLogin struct {
+	UserID   uint   `json:"user_id"`
+	Provider string `json:"provider"`
+}
+
+// SSOService 单点登录公共服务：身份提供方回调完成后签发一次性登录码，由前端兑换为平台令牌，
+// 避免在跳转地址中携带访问令牌
+type SSOService struct {
+	db           *gorm.DB
+	redis        *redis.Client
+	userService  *UserService
+	tokenService *TokenService
+}
+
+// NewSSOService 创建单点登录公共服务实例
+func NewSSOService() *SSOService {
+	return &SSOService{
+		db:           config.GetDB(),
+		redis:        config.GetRedisClient(),
+		userService:  NewUserService(),
+		tokenService: NewTokenService(),
+	}
+}
+
+// IssueLoginCode 为完成外部认证的用户签发一次性登录码
+func (s *SSOService) IssueLoginCode(userID uint, provider string) (string, error) {
+	if s.redis == nil {
+		return "", ErrSessionStoreUnavailable
+	}
+
+	code, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(ssoLogin{UserID: userID, Provider: provider})
+	if err != nil {
+		return "", err
+	}
+	if err := s.redis.Set(context.Background(), ssoLoginCodePrefix+hashToken(code), data, ssoLoginCodeTTL).Err(); err != nil {
+		return "", fmt.Errorf("保存登录码失败: %w", err)
+	}
+	return code, nil
+}
+
+// RedeemLoginCode 兑换一次性登录码，返回用户ID与登录方式，登录码随即作废
+func (s *SSOService) RedeemLoginCode(code string) (uint, string, error) {
+	if s.redis == nil {
+		return 0, "", ErrSessionStoreUnavailable
+	}
+
+	data, err := s.redis.GetDel(context.Background(), ssoLoginCodePrefix+hashToken(code)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, "", ErrSSOLoginCodeInvalid
+		}
+		return 0, "", err
+	}
+
+	var login ssoLogin
+	if err := json.Unmarshal(data, &login); err != nil || login.UserID == 0 {
+		return 0, "", ErrSSOLoginCodeInvalid
+	}
+	return login.UserID, login.Provider, nil
+}
+
+// NewLoginState 生成跳转登录时使用的随机 state
+func NewLoginState() (string, error) {
+	return generateSecureToken()
+}
+
+// FrontendRedirect 生成回跳前端的地址，参数放在片段中以免出现在服务器日志与 Referer 中
+func FrontendRedirect(frontendURL string, params url.Values) string {
+	base := frontendURL
+	if base == "" {
+		base = strings.TrimRight(config.GlobalConfig.Server.BaseURL, "/") + "/sso/callback"
+	}
+	return base + "#" + params.Encode()
+}
+
+// findOrCreateUser 按邮箱匹配本地账户，不存在且允许自动创建时以指定角色创建账户
+// 外部身份提供方已验证邮箱，等待邮箱验证的注册账户随之激活，见 updateAccount
+func (s *SSOService) findOrCreateUser(email, preferredUsername, role string, autoCreate bool, profile *models.UserCreateRequest) (*models.User, bool, error) {
+	var existing models.User
+	err := s.db.Preload("Roles").Where("email = ?", email).First(&existing).Error
+	if err == nil {
+		if err := s.updateAccount(&existing, map[string]interface{}{}); err != nil {
+			return nil, false, err
+		}
+		if !existing.IsActive() {
+			return nil, false, ErrSSOAccountDisabled
+		}
+		return &existing, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+	if !autoCreate {
+		return nil, false, ErrSSOAccountNotFound
+	}
+
+	username, err := s.uniqueUsername(preferredUsername, email)
+	if err != nil {
+		return nil, false, err
+	}
+	req := &models.UserCreateRequest{}
+	if profile != nil {
+		*req = *profile
+	}
+	req.Username = username
+	req.Email = email
+	req.Role = role
+	if _, _, err := s.userService.CreateUser(req); err != nil {
+		return nil, false, err
+	}
+
+	user, err := s.userService.GetUserByEmail(email)
+	if err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
+}
+
+// updateAccount 更新单点登录匹配到的账户；等待邮箱验证的账户由外部身份提供方确认邮箱后随之激活
+// 待验证账户的密码可能由抢先用该邮箱注册的他人设置，激活时替换为无法登录的随机密码并撤销已有会话，
+// 账户持有人之后通过单点登录或找回密码登录
+func (s *SSOService) updateAccount(user *models.User, updates map[string]interface{}) error {
+	pending := user.IsPendingVerification()
+	if pending {
+		random, err := generateSecureToken()
+		if err != nil {
+			return err
+		}
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(random), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		updates["status"] = "active"
+		updates["email_verified_at"] = now
+		updates["password"] = string(hashedPassword)
+		user.Status = "active"
+		user.EmailVerifiedAt = &now
+		user.Password = string(hashedPassword)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	if err := s.db.Model(user).Updates(updates).Error; err != nil {
+		return fmt.Errorf("更新账户资料失败: %w", err)
+	}
+	if pending {
+		logger.Infof("单点登录激活待验证账户并重置密码: user_id=%d", user.ID)
+		if err := s.tokenService.RevokeAllSessions(user.ID); err != nil {
+			logger.Errorf("撤销用户会话失败: user_id=%d, %v", user.ID, err)
+		}
+	}
+	return nil
+}
+
+// uniqueUsername 根据外部用户名或邮箱前缀生成未被占用的用户名
+func (s *SSOService) uniqueUsername(preferred, email string) (string, error) {
+	base := ssoUsernamePattern.ReplaceAllString(preferred, "")
+	if len(base) < 3 {
+		base = ssoUsernamePattern.ReplaceAllString(strings.SplitN(email, "@", 2)[0], "")
+	}
+	if len(base) < 3 {
+		base = "user" + base
+	}
+	if len(base) > 20 {
+		base = base[:20]
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		var count int64
+		if err := s.db.Model(&models.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+
+		suffix, err := generateSecureToken()
+		if err != nil {
+			return "", err
+		}
+		prefix := base
+		if len(prefix) > 15 {
+			prefix = prefix[:15]
+		}
+		candidate = prefix + "_" + suffix[:4]
+	}
+
+	logger.Warnf("无法为单点登录账户生成唯一用户名: %s", email)
+	return "", errors.New("无法生成唯一的用户名")
+}
@@ -0,0 +1,471 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// Redis键前缀
+const (
+	refreshTokenPrefix  = "auth:refresh:"
+	refreshUsedPrefix   = "auth:refresh_used:"
+	sessionPrefix       = "auth:session:"
+	userSessionsPrefix  = "auth:user_sessions:"
+	denylistPrefix      = "auth:denylist:"
+	revokedBeforePrefix = "auth:revoked_before:"
+	sessionSeenPrefix   = "auth:session_seen:"
+)
+
+// sessionTouchInterval 会话最近活动时间的最小更新间隔
+const sessionTouchInterval = time.Minute
+
+var (
+	// ErrInvalidRefreshToken 刷新令牌无效
+	ErrInvalidRefreshToken = errors.New("刷新令牌无效或已过期")
+	// ErrRefreshTokenReused 刷新令牌被重复使用
+	ErrRefreshTokenReused = errors.New("刷新令牌已被使用，会话已撤销，请重新登录")
+	// ErrSessionStoreUnavailable 会话存储不可用
+	ErrSessionStoreUnavailable = errors.New("会话服务暂不可用")
+	// ErrSessionNotFound 会话不存在或已结束
+	ErrSessionNotFound = errors.New("会话不存在或已结束")
+)
+
+// TokenService 令牌会话服务，刷新令牌以哈希形式存储在Redis中
+type TokenService struct {
+	db    *gorm.DB
+	redis *redis.Client
+	cfg   *config.JWTConfig
+}
+
+// NewTokenService 创建令牌会话服务实例
+func NewTokenService() *TokenService {
+	return &TokenService{
+		db:    config.GetDB(),
+		redis: config.GetRedisClient(),
+		cfg:   &config.GlobalConfig.JWT,
+	}
+}
+
+// CreateSession 为用户创建新会话并记录登录设备，返回会话ID及首个刷新令牌
+func (s *TokenService) CreateSession(userID uint, meta models.SessionMeta) (string, string, error) {
+	if s.redis == nil {
+		return "", "", ErrSessionStoreUnavailable
+	}
+
+	sessionID, err := generateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+	sessionID = sessionID[:32]
+
+	ctx := context.Background()
+	ttl := s.cfg.RefreshExpireTime
+	pipe := s.redis.TxPipeline()
+	pipe.Set(ctx, sessionPrefix+sessionID, userID, ttl)
+	pipe.SAdd(ctx, userSessionsKey(userID), sessionID)
+	pipe.Expire(ctx, userSessionsKey(userID), ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", "", fmt.Errorf("创建会话失败: %w", err)
+	}
+
+	refreshToken, err := s.issueRefreshToken(ctx, userID, sessionID)
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	record := &models.UserSession{
+		SessionID:  sessionID,
+		UserID:     userID,
+		UserAgent:  truncate(meta.UserAgent, 500),
+		Device:     describeUserAgent(meta.UserAgent),
+		IP:         meta.IP,
+		LastSeenAt: now,
+		LastSeenIP: meta.IP,
+		ExpiresAt:  now.Add(ttl),
+	}
+	if err := s.db.Create(record).Error; err != nil {
+		logger.Errorf("保存会话记录失败: %v", err)
+	}
+	return sessionID, refreshToken, nil
+}
+
+// Rotate 使用刷新令牌换取新的刷新令牌，旧令牌随即失效；检测到重复使用时撤销整个会话
+func (s *TokenService) Rotate(refreshToken string) (uint, string, string, error) {
+	if s.redis == nil {
+		return 0, "", "", ErrSessionStoreUnavailable
+	}
+
+	ctx := context.Background()
+	hash := hashToken(refreshToken)
+	record, err := s.redis.Get(ctx, refreshTokenPrefix+hash).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, "", "", ErrInvalidRefreshToken
+		}
+		return 0, "", "", err
+	}
+
+	userID, sessionID, ok := parseRefreshRecord(record)
+	if !ok {
+		return 0, "", "", ErrInvalidRefreshToken
+	}
+
+	alive, err := s.redis.Exists(ctx, sessionPrefix+sessionID).Result()
+	if err != nil {
+		return 0, "", "", err
+	}
+	if alive == 0 {
+		return 0, "", "", ErrInvalidRefreshToken
+	}
+
+	// 原子标记为已使用，标记失败说明该令牌已被轮换过
+	first, err := s.redis.SetNX(ctx, refreshUsedPrefix+hash, time.Now().Unix(), s.cfg.RefreshExpireTime).Result()
+	if err != nil {
+		return 0, "", "", err
+	}
+	if !first {
+		logger.Warnf("检测到刷新令牌重复使用，撤销会话: user_id=%d, session=%s", userID, sessionID)
+		if err := s.RevokeSession(userID, sessionID, models.SessionEndRefreshReuse); err != nil {
+			logger.Errorf("撤销会话失败: %v", err)
+		}
+		return 0, "", "", ErrRefreshTokenReused
+	}
+
+	newToken, err := s.issueRefreshToken(ctx, userID, sessionID)
+	if err != nil {
+		return 0, "", "", err
+	}
+
+	pipe := s.redis.TxPipeline()
+	pipe.Expire(ctx, sessionPrefix+sessionID, s.cfg.RefreshExpireTime)
+	pipe.Expire(ctx, userSessionsKey(userID), s.cfg.RefreshExpireTime)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Warnf("延长会话有效期失败: %v", err)
+	}
+	s.db.Model(&models.UserSession{}).Where("session_id = ?", sessionID).
+		Update("expires_at", time.Now().Add(s.cfg.RefreshExpireTime))
+
+	return userID, sessionID, newToken, nil
+}
+
+// TouchSession 更新会话最近活动时间与IP，同一会话每分钟最多写库一次
+func (s *TokenService) TouchSession(sessionID, ip string) {
+	if s.redis == nil || sessionID == "" {
+		return
+	}
+
+	ctx := context.Background()
+	first, err := s.redis.SetNX(ctx, sessionSeenPrefix+sessionID, 1, sessionTouchInterval).Result()
+	if err != nil || !first {
+		return
+	}
+	if err := s.db.Model(&models.UserSession{}).
+		Where("session_id = ? AND ended_at IS NULL", sessionID).
+		Updates(map[string]interface{}{
+			"last_seen_at": time.Now(),
+			"last_seen_ip": ip,
+		}).Error; err != nil {
+		logger.Warnf("更新会话活动时间失败: %v", err)
+	}
+}
+
+// ListSessions 获取用户当前有效的会话，currentSessionID 对应的会话标记为当前会话
+func (s *TokenService) ListSessions(userID uint, currentSessionID string) ([]models.UserSession, error) {
+	if s.redis == nil {
+		return nil, ErrSessionStoreUnavailable
+	}
+
+	var records []models.UserSession
+	if err := s.db.Where("user_id = ? AND ended_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	// 以Redis中的会话为准，过滤已失效的记录
+	alive, err := s.redis.SMembers(context.Background(), userSessionsKey(userID)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	aliveSet := make(map[string]bool, len(alive))
+	for _, sessionID := range alive {
+		aliveSet[sessionID] = true
+	}
+
+	sessions := make([]models.UserSession, 0, len(records))
+	for _, record := range records {
+		if !aliveSet[record.SessionID] {
+			continue
+		}
+		record.Current = record.SessionID == currentSessionID
+		sessions = append(sessions, record)
+	}
+	return sessions, nil
+}
+
+// RevokeSessionByID 按会话记录ID撤销用户的会话
+func (s *TokenService) RevokeSessionByID(userID, id uint) error {
+	var record models.UserSession
+	if err := s.db.Where("id = ? AND user_id = ? AND ended_at IS NULL", id, userID).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	return s.RevokeSession(userID, record.SessionID, models.SessionEndRevoked)
+}
+
+// RevokeOtherSessions 撤销用户除当前会话外的全部会话，返回撤销数量
+func (s *TokenService) RevokeOtherSessions(userID uint, currentSessionID string) (int, error) {
+	if s.redis == nil {
+		return 0, ErrSessionStoreUnavailable
+	}
+
+	sessionIDs, err := s.redis.SMembers(context.Background(), userSessionsKey(userID)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, sessionID := range sessionIDs {
+		if sessionID == currentSessionID {
+			continue
+		}
+		if err := s.RevokeSession(userID, sessionID, models.SessionEndRevoked); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// RevokeSession 撤销指定会话，会话下的刷新令牌与访问令牌均失效，reason 为会话结束原因
+func (s *TokenService) RevokeSession(userID uint, sessionID, reason string) error {
+	if s.redis == nil || sessionID == "" {
+		return nil
+	}
+
+	ctx := context.Background()
+	pipe := s.redis.TxPipeline()
+	pipe.Del(ctx, sessionPrefix+sessionID)
+	pipe.SRem(ctx, userSessionsKey(userID), sessionID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	s.endSessionRecords(s.db.Where("session_id = ?", sessionID), reason)
+	return nil
+}
+
+// RevokeAllSessions 撤销用户的全部会话，并使此前签发的访问令牌全部失效
+func (s *TokenService) RevokeAllSessions(userID uint) error {
+	if s.redis == nil {
+		return ErrSessionStoreUnavailable
+	}
+
+	ctx := context.Background()
+	sessionIDs, err := s.redis.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	pipe := s.redis.TxPipeline()
+	for _, sessionID := range sessionIDs {
+		pipe.Del(ctx, sessionPrefix+sessionID)
+	}
+	pipe.Del(ctx, userSessionsKey(userID))
+	pipe.Set(ctx, revokedBeforePrefix+strconv.FormatUint(uint64(userID), 10), time.Now().Unix(), s.revocationTTL())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	s.endSessionRecords(s.db.Where("user_id = ?", userID), models.SessionEndRevokedAll)
+
+	logger.Infof("已撤销用户全部会话: user_id=%d, 会话数=%d", userID, len(sessionIDs))
+	return nil
+}
+
+// DenyAccessToken 将访问令牌加入黑名单直至其自然过期
+func (s *TokenService) DenyAccessToken(jti string, expiresAt time.Time) error {
+	if s.redis == nil || jti == "" {
+		return nil
+	}
+
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.redis.Set(context.Background(), denylistPrefix+jti, 1, ttl).Err()
+}
+
+// IsAccessTokenRevoked 判断访问令牌是否已被撤销（黑名单、会话撤销或全部会话撤销）
+// Redis不可用时放行，避免缓存故障导致全站无法访问
+func (s *TokenService) IsAccessTokenRevoked(userID uint, jti, sessionID string, issuedAt time.Time) bool {
+	if s.redis == nil {
+		return false
+	}
+
+	ctx := context.Background()
+	pipe := s.redis.Pipeline()
+	var denied, sessionAlive *redis.IntCmd
+	if jti != "" {
+		denied = pipe.Exists(ctx, denylistPrefix+jti)
+	}
+	if sessionID != "" {
+		sessionAlive = pipe.Exists(ctx, sessionPrefix+sessionID)
+	}
+	revokedBefore := pipe.Get(ctx, revokedBeforePrefix+strconv.FormatUint(uint64(userID), 10))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		logger.Warnf("检查令牌撤销状态失败: %v", err)
+		return false
+	}
+
+	if denied != nil && denied.Val() > 0 {
+		return true
+	}
+	if sessionAlive != nil && sessionAlive.Val() == 0 {
+		return true
+	}
+	if before, err := revokedBefore.Int64(); err == nil && issuedAt.Unix() < before {
+		return true
+	}
+	return false
+}
+
+// issueRefreshToken 签发刷新令牌，仅保存其哈希
+func (s *TokenService) issueRefreshToken(ctx context.Context, userID uint, sessionID string) (string, error) {
+	token, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	record := fmt.Sprintf("%d:%s", userID, sessionID)
+	if err := s.redis.Set(ctx, refreshTokenPrefix+hashToken(token), record, s.cfg.RefreshExpireTime).Err(); err != nil {
+		return "", fmt.Errorf("保存刷新令牌失败: %w", err)
+	}
+	return token, nil
+}
+
+// endSessionRecords 标记会话记录已结束
+func (s *TokenService) endSessionRecords(scope *gorm.DB, reason string) {
+	if err := scope.Model(&models.UserSession{}).
+		Where("ended_at IS NULL").
+		Updates(map[string]interface{}{
+			"ended_at":   time.Now(),
+			"end_reason": reason,
+		}).Error; err != nil {
+		logger.Warnf("更新会话记录失败: %v", err)
+	}
+}
+
+// PurgeSessionRecords 删除结束或过期超过 retention 的会话记录，返回删除数量
+func (s *TokenService) PurgeSessionRecords(retention time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-retention)
+	result := s.db.Where("(ended_at IS NOT NULL AND ended_at < ?) OR expires_at < ?", cutoff, cutoff).
+		Delete(&models.UserSession{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected > 0 {
+		logger.Infof("已清理过期会话记录: %d 条", result.RowsAffected)
+	}
+	return result.RowsAffected, nil
+}
+
+// revocationTTL 全部会话撤销标记的保留时长，需覆盖访问令牌与刷新令牌的最长有效期
+func (s *TokenService) revocationTTL() time.Duration {
+	if s.cfg.RefreshExpireTime > s.cfg.ExpireTime {
+		return s.cfg.RefreshExpireTime
+	}
+	return s.cfg.ExpireTime
+}
+
+// userSessionsKey 用户会话集合的键
+func userSessionsKey(userID uint) string {
+	return userSessionsPrefix + strconv.FormatUint(uint64(userID), 10)
+}
+
+// parseRefreshRecord 解析刷新令牌记录
+func parseRefreshRecord(record string) (uint, string, bool) {
+	idStr, sessionID, ok := strings.Cut(record, ":")
+	if !ok || sessionID == "" {
+		return 0, "", false
+	}
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return 0, "", false
+	}
+	return uint(id), sessionID, true
+}
+
+// describeUserAgent 从 User-Agent 中提取浏览器与操作系统，用于会话列表展示
+func describeUserAgent(ua string) string {
+	if ua == "" {
+		return "未知设备"
+	}
+
+	browser := "未知浏览器"
+	for _, b := range []struct{ token, name string }{
+		{"MicroMessenger", "微信"},
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+		{"python-requests", "Python"},
+		{"Go-http-client", "Go"},
+	} {
+		if strings.Contains(ua, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	platform := ""
+	for _, o := range []struct{ token, name string }{
+		{"Windows", "Windows"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(ua, o.token) {
+			platform = o.name
+			break
+		}
+	}
+
+	if platform == "" {
+		return browser
+	}
+	return browser + " / " + platform
+}
+
+// truncate 按字符截断字符串
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
+
+// hashToken 计算令牌的SHA-256哈希
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
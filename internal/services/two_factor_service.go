@@ -0,0 +1,404 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// TOTP参数（RFC 6238 默认值，兼容主流验证器应用）
+const (
+	totpPeriod = 30
+	totpDigits = 6
+)
+
+// 预认证令牌用途
+const (
+	MFAPurposeVerify = "verify"
+	MFAPurposeEnroll = "enroll"
+)
+
+// Redis键前缀
+const (
+	mfaTokenPrefix    = "auth:mfa:"
+	mfaAttemptsPrefix = "auth:mfa_attempts:"
+)
+
+var (
+	// ErrInvalidMFAToken 预认证令牌无效
+	ErrInvalidMFAToken = errors.New("预认证令牌无效或已过期，请重新登录")
+	// ErrInvalidTwoFactorCode 验证码错误
+	ErrInvalidTwoFactorCode = errors.New("验证码错误")
+	// ErrTwoFactorNotEnabled 未启用两步验证
+	ErrTwoFactorNotEnabled = errors.New("未启用两步验证")
+)
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorService 两步验证服务
+type TwoFactorService struct {
+	db    *gorm.DB
+	redis *redis.Client
+	cfg   *config.TwoFactorConfig
+}
+
+// NewTwoFactorService 创建两步验证服务实例
+func NewTwoFactorService() *TwoFactorService {
+	return &TwoFactorService{
+		db:    config.GetDB(),
+		redis: config.GetRedisClient(),
+		cfg:   &config.GlobalConfig.TwoFactor,
+	}
+}
+
+// IsRequired 判断用户是否被强制要求启用两步验证
+func (s *TwoFactorService) IsRequired(user *models.User) bool {
+	return s.cfg.RequireForAdmins && user.IsAdmin()
+}
+
+// IsEnabled 判断用户是否已启用两步验证
+func (s *TwoFactorService) IsEnabled(userID uint) (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.UserTwoFactor{}).
+		Where("user_id = ? AND enabled = ?", userID, true).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetStatus 获取用户两步验证状态
+func (s *TwoFactorService) GetStatus(user *models.User) (*models.TwoFactorStatusResponse, error) {
+	status := &models.TwoFactorStatusResponse{Required: s.IsRequired(user)}
+
+	var record models.UserTwoFactor
+	err := s.db.Where("user_id = ?", user.ID).First(&record).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil && record.Enabled {
+		status.Enabled = true
+		status.ConfirmedAt = record.ConfirmedAt
+	}
+
+	if err := s.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", user.ID).
+		Count(&status.RemainingRecoveryCodes).Error; err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Setup 为用户生成新的TOTP密钥，需通过 Enable 确认后才生效
+func (s *TwoFactorService) Setup(user *models.User) (*models.TwoFactorSetupResponse, error) {
+	var record models.UserTwoFactor
+	err := s.db.Where("user_id = ?", user.ID).First(&record).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil && record.Enabled {
+		return nil, errors.New("两步验证已启用，如需更换请先停用")
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	record.UserID = user.ID
+	record.Secret = secret
+	record.Enabled = false
+	record.LastUsedStep = 0
+	if err := s.db.Save(&record).Error; err != nil {
+		logger.Errorf("保存两步验证密钥失败: %v", err)
+		return nil, errors.New("生成两步验证密钥失败")
+	}
+
+	return &models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: totpURI(s.cfg.Issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable 使用验证码确认密钥并启用两步验证，返回一次性恢复码明文
+func (s *TwoFactorService) Enable(userID uint, code string) ([]string, error) {
+	var record models.UserTwoFactor
+	if err := s.db.Where("user_id = ?", userID).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("请先生成两步验证密钥")
+		}
+		return nil, err
+	}
+	if record.Enabled {
+		return nil, errors.New("两步验证已启用")
+	}
+
+	step, ok := verifyTOTP(record.Secret, code, time.Now(), s.cfg.Skew)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&record).Updates(map[string]interface{}{
+			"enabled":        true,
+			"confirmed_at":   now,
+			"last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		logger.Errorf("启用两步验证失败: %v", err)
+		return nil, errors.New("启用两步验证失败")
+	}
+
+	logger.Infof("用户已启用两步验证: user_id=%d", userID)
+	return codes, nil
+}
+
+// Disable 停用两步验证并删除恢复码
+func (s *TwoFactorService) Disable(userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，原有恢复码全部作废
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uint) ([]string, error) {
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		logger.Errorf("生成恢复码失败: %v", err)
+		return nil, errors.New("生成恢复码失败")
+	}
+	return codes, nil
+}
+
+// VerifyCode 校验TOTP验证码，同一时间步的验证码只能使用一次
+func (s *TwoFactorService) VerifyCode(userID uint, code string) error {
+	var record models.UserTwoFactor
+	if err := s.db.Where("user_id = ? AND enabled = ?", userID, true).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTwoFactorNotEnabled
+		}
+		return err
+	}
+
+	step, ok := verifyTOTP(record.Secret, code, time.Now(), s.cfg.Skew)
+	if !ok || step <= record.LastUsedStep {
+		return ErrInvalidTwoFactorCode
+	}
+
+	// 以条件更新防止并发请求重放同一验证码
+	result := s.db.Model(&models.UserTwoFactor{}).
+		Where("id = ? AND last_used_step < ?", record.ID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// UseRecoveryCode 使用恢复码完成验证，恢复码使用后即失效
+func (s *TwoFactorService) UseRecoveryCode(userID uint, code string) error {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	if normalized == "" {
+		return ErrInvalidTwoFactorCode
+	}
+
+	result := s.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashToken(normalized)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+
+	logger.Infof("用户使用恢复码完成两步验证: user_id=%d", userID)
+	return nil
+}
+
+// CreatePreAuthToken 签发登录第二步使用的短期预认证令牌
+func (s *TwoFactorService) CreatePreAuthToken(userID uint, purpose string) (string, error) {
+	if s.redis == nil {
+		return "", ErrSessionStoreUnavailable
+	}
+
+	token, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	record := fmt.Sprintf("%d:%s", userID, purpose)
+	if err := s.redis.Set(context.Background(), mfaTokenPrefix+hashToken(token), record, s.cfg.PreAuthTTL).Err(); err != nil {
+		return "", fmt.Errorf("保存预认证令牌失败: %w", err)
+	}
+	return token, nil
+}
+
+// CheckPreAuthToken 校验预认证令牌并计入一次尝试，超过次数后令牌作废
+func (s *TwoFactorService) CheckPreAuthToken(token, purpose string) (uint, error) {
+	if s.redis == nil {
+		return 0, ErrSessionStoreUnavailable
+	}
+	if token == "" {
+		return 0, ErrInvalidMFAToken
+	}
+
+	ctx := context.Background()
+	hash := hashToken(token)
+	record, err := s.redis.Get(ctx, mfaTokenPrefix+hash).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, ErrInvalidMFAToken
+		}
+		return 0, err
+	}
+
+	idStr, tokenPurpose, ok := strings.Cut(record, ":")
+	if !ok || tokenPurpose != purpose {
+		return 0, ErrInvalidMFAToken
+	}
+	userID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return 0, ErrInvalidMFAToken
+	}
+
+	attempts, err := s.redis.Incr(ctx, mfaAttemptsPrefix+hash).Result()
+	if err != nil {
+		return 0, err
+	}
+	if attempts == 1 {
+		s.redis.Expire(ctx, mfaAttemptsPrefix+hash, s.cfg.PreAuthTTL)
+	}
+	if attempts > int64(s.cfg.MaxAttempts) {
+		s.DeletePreAuthToken(token)
+		return 0, ErrInvalidMFAToken
+	}
+	return uint(userID), nil
+}
+
+// DeletePreAuthToken 删除预认证令牌
+func (s *TwoFactorService) DeletePreAuthToken(token string) {
+	if s.redis == nil || token == "" {
+		return
+	}
+	hash := hashToken(token)
+	s.redis.Del(context.Background(), mfaTokenPrefix+hash, mfaAttemptsPrefix+hash)
+}
+
+// PreAuthTTL 预认证令牌有效期
+func (s *TwoFactorService) PreAuthTTL() time.Duration {
+	return s.cfg.PreAuthTTL
+}
+
+// replaceRecoveryCodes 删除旧恢复码并生成新的恢复码
+func (s *TwoFactorService) replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, s.cfg.RecoveryCodes)
+	records := make([]models.RecoveryCode, 0, s.cfg.RecoveryCodes)
+	for i := 0; i < s.cfg.RecoveryCodes; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := hex.EncodeToString(b)
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		records = append(records, models.RecoveryCode{UserID: userID, CodeHash: hashToken(raw)})
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateTOTPSecret 生成160位TOTP密钥（Base32编码）
+func generateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32NoPadding.EncodeToString(b), nil
+}
+
+// totpURI 生成验证器应用使用的 otpauth URI，可直接编码为二维码
+func totpURI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", strconv.Itoa(totpDigits))
+	params.Set("period", strconv.Itoa(totpPeriod))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// verifyTOTP 校验验证码，允许前后 skew 个时间步的偏移，返回匹配的时间步
+func verifyTOTP(secret, code string, at time.Time, skew int) (int64, bool) {
+	key, err := base32NoPadding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := at.Unix() / totpPeriod
+	for offset := -int64(skew); offset <= int64(skew); offset++ {
+		step := current + offset
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpCode 按 RFC 4226 计算指定计数器的HOTP值
+func totpCode(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
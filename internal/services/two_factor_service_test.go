@@ -0,0 +1,122 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret RFC 4226/6238 测试向量使用的密钥 "12345678901234567890"（Base32编码）
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	key := []byte("12345678901234567890")
+	// RFC 4226 附录D的HOTP测试向量
+	tests := []struct {
+		counter int64
+		want    string
+	}{
+		{0, "755224"},
+		{1, "287082"},
+		{2, "359152"},
+		{3, "969429"},
+		{4, "338314"},
+		{5, "254676"},
+		{6, "287922"},
+		{7, "162583"},
+		{8, "399871"},
+		{9, "520489"},
+	}
+	for _, tt := range tests {
+		if got := totpCode(key, tt.counter); got != tt.want {
+			t.Errorf("totpCode(counter=%d) = %s, want %s", tt.counter, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	// RFC 6238 附录B的SHA1测试向量（取8位结果的后6位）
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		at       int64
+		skew     int
+		wantOK   bool
+		wantStep int64
+	}{
+		{"T=59", rfcSecret, "287082", 59, 0, true, 1},
+		{"T=1111111109", rfcSecret, "081804", 1111111109, 0, true, 37037036},
+		{"T=1111111111", rfcSecret, "050471", 1111111111, 0, true, 37037037},
+		{"T=1234567890", rfcSecret, "005924", 1234567890, 0, true, 41152263},
+		{"T=2000000000", rfcSecret, "279037", 2000000000, 0, true, 66666666},
+		{"小写密钥", strings.ToLower(rfcSecret), "287082", 59, 0, true, 1},
+		{"允许前一个时间步", rfcSecret, "287082", 59 + 30, 1, true, 1},
+		{"允许后一个时间步", rfcSecret, "287082", 59 - 30, 1, true, 1},
+		{"超出偏移范围", rfcSecret, "287082", 59 + 60, 1, false, 0},
+		{"不允许偏移时拒绝相邻时间步", rfcSecret, "287082", 59 + 30, 0, false, 0},
+		{"验证码错误", rfcSecret, "000000", 59, 1, false, 0},
+		{"验证码位数错误", rfcSecret, "28708", 59, 1, false, 0},
+		{"密钥格式错误", "not-base32!", "287082", 59, 1, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := verifyTOTP(tt.secret, tt.code, time.Unix(tt.at, 0), tt.skew)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("verifyTOTP() = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		secret, err := generateTOTPSecret()
+		if err != nil {
+			t.Fatalf("generateTOTPSecret() error = %v", err)
+		}
+		key, err := base32NoPadding.DecodeString(secret)
+		if err != nil || len(key) != 20 {
+			t.Fatalf("密钥 %q 不是160位Base32编码", secret)
+		}
+		if seen[secret] {
+			t.Fatalf("生成了重复的密钥 %q", secret)
+		}
+		seen[secret] = true
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	tests := []struct {
+		name      string
+		issuer    string
+		account   string
+		wantLabel string
+	}{
+		{"普通账户", "EPI实验室", "alice@example.com", "EPI实验室:alice@example.com"},
+		{"含空格与斜杠", "Lab Platform", "a/b@example.com", "Lab Platform:a/b@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(totpURI(tt.issuer, tt.account, rfcSecret))
+			if err != nil {
+				t.Fatalf("URI无法解析: %v", err)
+			}
+			if u.Scheme != "otpauth" || u.Host != "totp" {
+				t.Errorf("scheme/host = %s/%s, want otpauth/totp", u.Scheme, u.Host)
+			}
+			if label := strings.TrimPrefix(u.Path, "/"); label != tt.wantLabel {
+				t.Errorf("label = %q, want %q", label, tt.wantLabel)
+			}
+			q := u.Query()
+			want := map[string]string{"secret": rfcSecret, "issuer": tt.issuer, "algorithm": "SHA1", "digits": "6", "period": "30"}
+			for k, v := range want {
+				if q.Get(k) != v {
+					t.Errorf("参数 %s = %q, want %q", k, q.Get(k), v)
+				}
+			}
+		})
+	}
+}
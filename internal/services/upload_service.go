@@ -0,0 +1,317 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// sniffLen 内容嗅探读取的字节数
+const sniffLen = 512
+
+// maxPendingUploadsPerEmail 同一邮箱上传但尚未关联申请的附件数上限，与提交申请时可关联的附件数一致
+const maxPendingUploadsPerEmail = 5
+
+var (
+	// ErrTooManyPendingUploads 未关联申请的附件过多
+	ErrTooManyPendingUploads = fmt.Errorf("最多只能上传%d个待提交的附件", maxPendingUploadsPerEmail)
+	// ErrAttachmentInvalid 提交申请时引用的附件不存在或不属于该邮箱
+	ErrAttachmentInvalid = errors.New("附件不存在或已被使用，请重新上传")
+)
+
+// extMimeTypes 扩展名与嗅探得到的MIME类型对应关系
+var extMimeTypes = map[string]string{
+	"jpg":  "image/jpeg",
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+	"gif":  "image/gif",
+	"pdf":  "application/pdf",
+	"doc":  "application/msword",
+	"docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+}
+
+// oleMagic Office 97-2003 复合文档文件头
+var oleMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// UploadService 文件上传服务
+type UploadService struct {
+	db  *gorm.DB
+	cfg *config.UploadConfig
+}
+
+// NewUploadService 创建文件上传服务实例
+func NewUploadService() *UploadService {
+	return &UploadService{
+		db:  config.GetDB(),
+		cfg: &config.GlobalConfig.Upload,
+	}
+}
+
+// SaveFile 校验并保存上传文件，按内容哈希存储；applicationID 不为空时在同一条记录中直接关联到该申请（管理员上传）
+func (s *UploadService) SaveFile(fileHeader *multipart.FileHeader, category string, uploaderID *uint, uploaderEmail string, applicationID *uint) (*models.FileUpload, error) {
+	originalName := filepath.Base(fileHeader.Filename)
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(originalName), "."))
+	if !s.isAllowedExt(ext) {
+		return nil, fmt.Errorf("不支持的文件类型，仅允许: %s", s.cfg.AllowedExt)
+	}
+
+	if fileHeader.Size > s.cfg.MaxSize {
+		return nil, fmt.Errorf("文件大小不能超过%dMB", s.cfg.MaxSize/1024/1024)
+	}
+
+	// 申请人上传的附件在提交申请前不归属任何账户，限制数量以免被用于占满磁盘
+	if uploaderEmail != "" {
+		var pending int64
+		if err := s.db.Model(&models.FileUpload{}).
+			Where("uploader_email = ? AND application_id IS NULL", uploaderEmail).
+			Count(&pending).Error; err != nil {
+			return nil, err
+		}
+		if pending >= maxPendingUploadsPerEmail {
+			return nil, ErrTooManyPendingUploads
+		}
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		return nil, errors.New("读取上传文件失败")
+	}
+	defer src.Close()
+
+	// 读取文件头用于内容嗅探
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, errors.New("读取上传文件失败")
+	}
+	head = head[:n]
+
+	mimeType, ok := matchContentType(ext, head)
+	if !ok {
+		return nil, errors.New("文件内容与扩展名不符")
+	}
+
+	if err := os.MkdirAll(s.cfg.Path, 0755); err != nil {
+		logger.Errorf("创建上传目录失败: %v", err)
+		return nil, errors.New("保存文件失败")
+	}
+
+	// 先写入临时文件，同时计算哈希并限制大小
+	tmp, err := os.CreateTemp(s.cfg.Path, ".upload-*")
+	if err != nil {
+		logger.Errorf("创建临时文件失败: %v", err)
+		return nil, errors.New("保存文件失败")
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	hasher := sha256.New()
+	reader := io.LimitReader(io.MultiReader(bytes.NewReader(head), src), s.cfg.MaxSize+1)
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), reader)
+	tmp.Close()
+	if err != nil {
+		logger.Errorf("写入临时文件失败: %v", err)
+		return nil, errors.New("保存文件失败")
+	}
+	if size > s.cfg.MaxSize {
+		return nil, fmt.Errorf("文件大小不能超过%dMB", s.cfg.MaxSize/1024/1024)
+	}
+
+	// docx 为 zip 容器，需要检查内部结构
+	if ext == "docx" && !isWordDocument(tmpPath) {
+		return nil, errors.New("文件内容与扩展名不符")
+	}
+
+	sum := hex.EncodeToString(hasher.Sum(nil))
+	filename := sum + "." + ext
+	relPath := filepath.Join(sum[:2], sum[2:4], filename)
+	fullPath := filepath.Join(s.cfg.Path, relPath)
+
+	// 相同内容的文件只保存一份
+	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			logger.Errorf("创建文件目录失败: %v", err)
+			return nil, errors.New("保存文件失败")
+		}
+		if err := os.Rename(tmpPath, fullPath); err != nil {
+			logger.Errorf("移动上传文件失败: %v", err)
+			return nil, errors.New("保存文件失败")
+		}
+	}
+
+	upload := &models.FileUpload{
+		Filename:      filename,
+		OriginalName:  originalName,
+		FilePath:      relPath,
+		FileSize:      size,
+		MimeType:      mimeType,
+		SHA256:        sum,
+		Category:      category,
+		UploaderID:    uploaderID,
+		UploaderEmail: uploaderEmail,
+		ApplicationID: applicationID,
+	}
+
+	if err := s.db.Create(upload).Error; err != nil {
+		logger.Errorf("保存文件记录失败: %v", err)
+		s.RemoveOrphanFiles([]models.FileUpload{*upload})
+		return nil, errors.New("保存文件记录失败")
+	}
+
+	logger.Infof("文件上传成功: ID=%d, 文件=%s, 大小=%d", upload.ID, upload.OriginalName, upload.FileSize)
+	return upload, nil
+}
+
+// linkUploads 在 tx 中将申请人上传的附件关联到面试申请，任一附件无法关联时返回 ErrAttachmentInvalid
+func linkUploads(tx *gorm.DB, fileIDs []uint, email string, applicationID uint) error {
+	fileIDs = uniqueIDs(fileIDs)
+	if len(fileIDs) == 0 {
+		return nil
+	}
+
+	// 只允许关联同一邮箱上传且尚未关联的附件
+	result := tx.Model(&models.FileUpload{}).
+		Where("id IN ? AND uploader_email = ? AND application_id IS NULL", fileIDs, email).
+		Update("application_id", applicationID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if int(result.RowsAffected) != len(fileIDs) {
+		logger.Warnf("附件关联失败: 申请ID=%d, 请求=%d, 实际=%d", applicationID, len(fileIDs), result.RowsAffected)
+		return ErrAttachmentInvalid
+	}
+	return nil
+}
+
+// GetFileByID 根据ID获取文件记录
+func (s *UploadService) GetFileByID(id uint) (*models.FileUpload, error) {
+	var upload models.FileUpload
+	if err := s.db.First(&upload, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("文件不存在")
+		}
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// ListApplicationFiles 获取面试申请的附件列表
+func (s *UploadService) ListApplicationFiles(applicationID uint) ([]models.FileUpload, error) {
+	var uploads []models.FileUpload
+	if err := s.db.Where("application_id = ?", applicationID).Order("created_at ASC").Find(&uploads).Error; err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
+
+// ListUnlinkedByEmail 获取申请人上传但尚未关联申请的附件
+func (s *UploadService) ListUnlinkedByEmail(email string) ([]models.FileUpload, error) {
+	var uploads []models.FileUpload
+	if err := s.db.Where("uploader_email = ? AND application_id IS NULL", email).Order("created_at ASC").Find(&uploads).Error; err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
+
+// DeleteUnlinkedByEmail 永久删除申请人上传但尚未关联申请的附件
+func (s *UploadService) DeleteUnlinkedByEmail(email string) (int, error) {
+	var uploads []models.FileUpload
+	if err := s.db.Unscoped().Where("uploader_email = ? AND application_id IS NULL", email).Find(&uploads).Error; err != nil {
+		return 0, err
+	}
+	if len(uploads) == 0 {
+		return 0, nil
+	}
+
+	if err := s.db.Unscoped().Delete(&uploads).Error; err != nil {
+		return 0, err
+	}
+
+	s.RemoveOrphanFiles(uploads)
+	return len(uploads), nil
+}
+
+// RemoveOrphanFiles 删除已没有任何记录引用的磁盘文件（记录需先行删除）
+func (s *UploadService) RemoveOrphanFiles(uploads []models.FileUpload) {
+	for i := range uploads {
+		var count int64
+		if err := s.db.Unscoped().Model(&models.FileUpload{}).Where("sha256 = ?", uploads[i].SHA256).Count(&count).Error; err != nil {
+			logger.Errorf("检查文件引用失败: %v", err)
+			continue
+		}
+		if count > 0 {
+			continue
+		}
+		if err := os.Remove(s.GetFullPath(&uploads[i])); err != nil && !os.IsNotExist(err) {
+			logger.Errorf("删除磁盘文件失败: %s, err=%v", uploads[i].FilePath, err)
+		}
+	}
+}
+
+// GetFullPath 获取文件在磁盘上的完整路径
+func (s *UploadService) GetFullPath(upload *models.FileUpload) string {
+	return filepath.Join(s.cfg.Path, upload.FilePath)
+}
+
+// isAllowedExt 判断扩展名是否在允许列表中
+func (s *UploadService) isAllowedExt(ext string) bool {
+	for _, allowed := range s.cfg.GetAllowedExts() {
+		if ext == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// matchContentType 根据文件头嗅探内容类型并与扩展名比对
+func matchContentType(ext string, head []byte) (string, bool) {
+	expected, known := extMimeTypes[ext]
+	if !known {
+		// 未内置的扩展名按标准库映射比对
+		expected, _, _ = mime.ParseMediaType(mime.TypeByExtension("." + ext))
+		if expected == "" {
+			return "", false
+		}
+	}
+
+	switch ext {
+	case "doc":
+		return expected, bytes.HasPrefix(head, oleMagic)
+	case "docx":
+		return expected, http.DetectContentType(head) == "application/zip"
+	}
+
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return expected, detected == expected
+}
+
+// isWordDocument 检查zip容器中是否包含Word文档主体
+func isWordDocument(path string) bool {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return false
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.Name == "word/document.xml" {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -10,24 +11,94 @@ import (
 	"lab-recruitment-platform/pkg/logger"
 )
 
+// ErrStudentIDLocked 学号已通过统一身份认证关联
+var ErrStudentIDLocked = errors.New("学号已通过统一身份认证关联，不能修改")
+
 // UserService 用户服务
 type UserService struct {
-	db *gorm.DB
+	db     *gorm.DB
+	policy *PasswordPolicyService
 }
 
 // NewUserService 创建用户服务实例
 func NewUserService() *UserService {
 	return &UserService{
-		db: config.GetDB(),
+		db:     config.GetDB(),
+		policy: NewPasswordPolicyService(),
 	}
 }
 
+// CreateUser 创建用户，返回用户及明文初始密码（用于邀请邮件）
+func (s *UserService) CreateUser(req *models.UserCreateRequest) (*models.User, string, error) {
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("email = ?", req.Email).Count(&count).Error; err != nil {
+		return nil, "", err
+	}
+	if count > 0 {
+		return nil, "", errors.New("邮箱已被注册")
+	}
+	if err := s.db.Model(&models.User{}).Where("username = ?", req.Username).Count(&count).Error; err != nil {
+		return nil, "", err
+	}
+	if count > 0 {
+		return nil, "", errors.New("用户名已存在")
+	}
 
+	password := req.Password
+	if password == "" {
+		generated, err := s.policy.GeneratePassword()
+		if err != nil {
+			logger.Errorf("生成临时密码失败: %v", err)
+			return nil, "", errors.New("生成临时密码失败")
+		}
+		password = generated
+	} else if err := s.policy.Validate(password, &models.User{
+		Username:  req.Username,
+		Email:     req.Email,
+		StudentID: req.StudentID,
+	}); err != nil {
+		return nil, "", err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Errorf("密码加密失败: %v", err)
+		return nil, "", errors.New("密码加密失败")
+	}
+
+	// 管理员创建的账户视为邮箱已验证
+	now := time.Now()
+	user := &models.User{
+		Username:        req.Username,
+		Email:           req.Email,
+		Password:        string(hashedPassword),
+		Role:            req.Role,
+		Phone:           req.Phone,
+		StudentID:       req.StudentID,
+		Major:           req.Major,
+		Grade:           req.Grade,
+		Status:          "active",
+		EmailVerifiedAt: &now,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return s.policy.Record(tx, user.ID, user.Password)
+	})
+	if err != nil {
+		logger.Errorf("创建用户失败: %v", err)
+		return nil, "", errors.New("创建用户失败")
+	}
+
+	logger.Infof("用户创建成功: ID=%d, 角色=%s", user.ID, user.Role)
+	return user, password, nil
+}
 
 // GetUserByID 根据ID获取用户
 func (s *UserService) GetUserByID(id uint) (*models.User, error) {
 	var user models.User
-	if err := s.db.First(&user, id).Error; err != nil {
+	if err := s.db.Preload("Roles").First(&user, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("用户不存在")
 		}
@@ -39,7 +110,7 @@ func (s *UserService) GetUserByID(id uint) (*models.User, error) {
 // GetUserByEmail 根据邮箱获取用户
 func (s *UserService) GetUserByEmail(email string) (*models.User, error) {
 	var user models.User
-	if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := s.db.Preload("Roles").Where("email = ?", email).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("用户不存在")
 		}
@@ -68,7 +139,10 @@ func (s *UserService) UpdateUser(id uint, req *models.UserUpdateRequest) (*model
 	if req.Phone != "" {
 		user.Phone = req.Phone
 	}
-	if req.StudentID != "" {
+	if req.StudentID != "" && req.StudentID != user.StudentID {
+		if user.IsCASLinked() {
+			return nil, ErrStudentIDLocked
+		}
 		user.StudentID = req.StudentID
 	}
 	if req.Major != "" {
@@ -81,7 +155,7 @@ func (s *UserService) UpdateUser(id uint, req *models.UserUpdateRequest) (*model
 		user.Avatar = req.Avatar
 	}
 
-	if err := s.db.Save(user).Error; err != nil {
+	if err := s.db.Omit("Roles").Save(user).Error; err != nil {
 		logger.Errorf("更新用户失败: %v", err)
 		return nil, errors.New("更新用户失败")
 	}
@@ -89,6 +163,40 @@ func (s *UserService) UpdateUser(id uint, req *models.UserUpdateRequest) (*model
 	return user, nil
 }
 
+// UpdateUserRole 更新用户角色
+func (s *UserService) UpdateUserRole(id uint, role string) error {
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+	if user.IsSuperAdmin() && role != "super_admin" {
+		if err := s.ensureOtherSuperAdmin(id); err != nil {
+			return err
+		}
+	}
+
+	if err := s.db.Model(user).Update("role", role).Error; err != nil {
+		logger.Errorf("更新用户角色失败: %v", err)
+		return errors.New("更新用户角色失败")
+	}
+
+	return nil
+}
+
+// ensureOtherSuperAdmin 确保除指定用户外仍有可用的超级管理员
+func (s *UserService) ensureOtherSuperAdmin(id uint) error {
+	var count int64
+	if err := s.db.Model(&models.User{}).
+		Where("role = ? AND status = ? AND id != ?", "super_admin", "active", id).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("至少需要保留一名可用的超级管理员")
+	}
+	return nil
+}
+
 // DeleteUser 删除用户
 func (s *UserService) DeleteUser(id uint) error {
 	user, err := s.GetUserByID(id)
@@ -96,6 +204,12 @@ func (s *UserService) DeleteUser(id uint) error {
 		return err
 	}
 
+	if user.IsSuperAdmin() {
+		if err := s.ensureOtherSuperAdmin(id); err != nil {
+			return err
+		}
+	}
+
 	if err := s.db.Delete(user).Error; err != nil {
 		logger.Errorf("删除用户失败: %v", err)
 		return errors.New("删除用户失败")
@@ -104,8 +218,8 @@ func (s *UserService) DeleteUser(id uint) error {
 	return nil
 }
 
-// ListUsers 获取用户列表
-func (s *UserService) ListUsers(page, size int, search string) ([]models.User, int64, error) {
+// ListUsers 获取用户列表，支持关键字搜索及角色、状态过滤
+func (s *UserService) ListUsers(page, size int, search, role, status string) ([]models.User, int64, error) {
 	var users []models.User
 	var total int64
 
@@ -116,6 +230,12 @@ func (s *UserService) ListUsers(page, size int, search string) ([]models.User, i
 		query = query.Where("username LIKE ? OR email LIKE ? OR student_id LIKE ?", 
 			"%"+search+"%", "%"+search+"%", "%"+search+"%")
 	}
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
 
 	// 获取总数
 	if err := query.Count(&total).Error; err != nil {
@@ -124,13 +244,22 @@ func (s *UserService) ListUsers(page, size int, search string) ([]models.User, i
 
 	// 分页查询
 	offset := (page - 1) * size
-	if err := query.Offset(offset).Limit(size).Find(&users).Error; err != nil {
+	if err := query.Preload("Roles").Offset(offset).Limit(size).Order("created_at DESC").Find(&users).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return users, total, nil
 }
 
+// ListAdmins 获取全部管理员与超级管理员账户
+func (s *UserService) ListAdmins() ([]models.User, error) {
+	var users []models.User
+	if err := s.db.Where("role IN ?", []string{"admin", "super_admin"}).Order("id ASC").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // ChangePassword 修改密码
 func (s *UserService) ChangePassword(id uint, oldPassword, newPassword string) error {
 	user, err := s.GetUserByID(id)
@@ -143,6 +272,13 @@ func (s *UserService) ChangePassword(id uint, oldPassword, newPassword string) e
 		return errors.New("旧密码错误")
 	}
 
+	if err := s.policy.Validate(newPassword, user); err != nil {
+		return err
+	}
+	if err := s.policy.CheckReuse(user.ID, user.Password, newPassword); err != nil {
+		return err
+	}
+
 	// 加密新密码
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
@@ -151,8 +287,7 @@ func (s *UserService) ChangePassword(id uint, oldPassword, newPassword string) e
 	}
 
 	// 更新密码
-	user.Password = string(hashedPassword)
-	if err := s.db.Save(user).Error; err != nil {
+	if err := s.savePassword(user, string(hashedPassword)); err != nil {
 		logger.Errorf("修改密码失败: %v", err)
 		return errors.New("修改密码失败")
 	}
@@ -160,28 +295,42 @@ func (s *UserService) ChangePassword(id uint, oldPassword, newPassword string) e
 	return nil
 }
 
-// ResetPassword 重置密码
-func (s *UserService) ResetPassword(id uint, newPassword string) error {
+// ResetPassword 重置密码，newPassword 为空时生成临时密码，返回实际设置的密码
+func (s *UserService) ResetPassword(id uint, newPassword string) (string, error) {
 	user, err := s.GetUserByID(id)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	if newPassword == "" {
+		newPassword, err = s.policy.GeneratePassword()
+		if err != nil {
+			logger.Errorf("生成临时密码失败: %v", err)
+			return "", errors.New("生成临时密码失败")
+		}
+	} else {
+		if err := s.policy.Validate(newPassword, user); err != nil {
+			return "", err
+		}
+		if err := s.policy.CheckReuse(user.ID, user.Password, newPassword); err != nil {
+			return "", err
+		}
 	}
 
 	// 加密新密码
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
 		logger.Errorf("密码加密失败: %v", err)
-		return errors.New("密码加密失败")
+		return "", errors.New("密码加密失败")
 	}
 
 	// 更新密码
-	user.Password = string(hashedPassword)
-	if err := s.db.Save(user).Error; err != nil {
+	if err := s.savePassword(user, string(hashedPassword)); err != nil {
 		logger.Errorf("重置密码失败: %v", err)
-		return errors.New("重置密码失败")
+		return "", errors.New("重置密码失败")
 	}
 
-	return nil
+	return newPassword, nil
 }
 
 // UpdateUserStatus 更新用户状态
@@ -191,8 +340,14 @@ func (s *UserService) UpdateUserStatus(id uint, status string) error {
 		return err
 	}
 
+	if user.IsSuperAdmin() && status != "active" {
+		if err := s.ensureOtherSuperAdmin(id); err != nil {
+			return err
+		}
+	}
+
 	user.Status = status
-	if err := s.db.Save(user).Error; err != nil {
+	if err := s.db.Omit("Roles").Save(user).Error; err != nil {
 		logger.Errorf("更新用户状态失败: %v", err)
 		return errors.New("更新用户状态失败")
 	}
@@ -227,7 +382,7 @@ func (s *UserService) GetUserStats() (map[string]int64, error) {
 
 	// 管理员用户数
 	var admins int64
-	if err := s.db.Model(&models.User{}).Where("role = ?", "admin").Count(&admins).Error; err != nil {
+	if err := s.db.Model(&models.User{}).Where("role IN ?", []string{"admin", "super_admin"}).Count(&admins).Error; err != nil {
 		return nil, err
 	}
 	stats["admins"] = admins
@@ -239,4 +394,54 @@ func (s *UserService) GetUserStats() (map[string]int64, error) {
 func (s *UserService) VerifyPassword(user *models.User, password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	return err == nil
-} 
\ No newline at end of file
+} 
+
+// savePassword 保存新的密码哈希并记录密码历史
+func (s *UserService) savePassword(user *models.User, hash string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		user.Password = hash
+		if err := tx.Omit("Roles").Save(user).Error; err != nil {
+			return err
+		}
+		return s.policy.Record(tx, user.ID, hash)
+	})
+}
+
+// SetUserRoles 替换用户被分配的角色
+func (s *UserService) SetUserRoles(id uint, roleIDs []uint) (*models.User, error) {
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]models.Role, 0, len(roleIDs))
+	if len(roleIDs) > 0 {
+		if err := s.db.Where("id IN ?", roleIDs).Find(&roles).Error; err != nil {
+			return nil, err
+		}
+		if len(roles) != len(uniqueIDs(roleIDs)) {
+			return nil, errors.New("角色不存在")
+		}
+	}
+
+	if err := s.db.Model(user).Association("Roles").Replace(roles); err != nil {
+		logger.Errorf("分配用户角色失败: %v", err)
+		return nil, errors.New("分配用户角色失败")
+	}
+
+	return s.GetUserByID(id)
+}
+
+// uniqueIDs 去除重复ID
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]struct{}, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
@@ -0,0 +1,541 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"lab-recruitment-platform/internal/config"
+	"lab-recruitment-platform/internal/models"
+	"lab-recruitment-platform/pkg/logger"
+)
+
+// webauthnCeremonyPrefix 注册/登录流程状态的Redis键前缀
+const webauthnCeremonyPrefix = "auth:webauthn:"
+
+// 通行密钥流程类型
+const (
+	webauthnCeremonyRegister = "register"
+	webauthnCeremonyLogin    = "login"
+)
+
+var (
+	// ErrWebAuthnDisabled 未启用通行密钥
+	ErrWebAuthnDisabled = errors.New("未启用通行密钥登录")
+	// ErrWebAuthnNotAllowed 当前账户不允许使用通行密钥
+	ErrWebAuthnNotAllowed = errors.New("仅管理员账户可以使用通行密钥")
+	// ErrWebAuthnCeremonyExpired 流程不存在或已过期
+	ErrWebAuthnCeremonyExpired = errors.New("通行密钥验证已过期，请重新发起")
+	// ErrWebAuthnVerifyFailed 通行密钥校验失败
+	ErrWebAuthnVerifyFailed = errors.New("通行密钥验证失败")
+	// ErrWebAuthnCredentialNotFound 通行密钥不存在
+	ErrWebAuthnCredentialNotFound = errors.New("通行密钥不存在")
+)
+
+// webauthnCeremony 保存在Redis中的流程状态，每个流程只能完成一次
+type webauthnCeremony struct {
+	Type    string               `json:"type"`
+	UserID  uint                 `json:"user_id"` // 无密码登录时为0
+	Session webauthn.SessionData `json:"session"`
+}
+
+// webauthnUser 适配 webauthn.User 接口
+type webauthnUser struct {
+	user        *models.User
+	credentials []webauthn.Credential
+}
+
+// WebAuthnID 用户句柄，使用用户ID而非邮箱等个人信息
+func (u *webauthnUser) WebAuthnID() []byte {
+	return webauthnUserHandle(u.user.ID)
+}
+
+// WebAuthnName 账户名，显示在认证器的账户选择界面
+func (u *webauthnUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+// WebAuthnDisplayName 账户显示名称
+func (u *webauthnUser) WebAuthnDisplayName() string {
+	return u.user.Username
+}
+
+// WebAuthnIcon 账户图标（规范已弃用，返回空值）
+func (u *webauthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+// WebAuthnCredentials 用户已注册的通行密钥
+func (u *webauthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// WebAuthnService 通行密钥（WebAuthn）服务
+type WebAuthnService struct {
+	db    *gorm.DB
+	redis *redis.Client
+	cfg   *config.WebAuthnConfig
+}
+
+// NewWebAuthnService 创建通行密钥服务实例
+func NewWebAuthnService() *WebAuthnService {
+	return &WebAuthnService{
+		db:    config.GetDB(),
+		redis: config.GetRedisClient(),
+		cfg:   &config.GlobalConfig.WebAuthn,
+	}
+}
+
+// IsAllowed 判断用户是否可以使用通行密钥
+func (s *WebAuthnService) IsAllowed(user *models.User) bool {
+	return s.cfg.Enabled && (!s.cfg.AdminOnly || user.IsAdmin())
+}
+
+// HasCredentials 判断用户是否已注册可用的通行密钥
+func (s *WebAuthnService) HasCredentials(user *models.User) (bool, error) {
+	if !s.IsAllowed(user) {
+		return false, nil
+	}
+
+	var count int64
+	if err := s.db.Model(&models.WebAuthnCredential{}).
+		Where("user_id = ?", user.ID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ListCredentials 获取用户的通行密钥列表
+func (s *WebAuthnService) ListCredentials(userID uint) ([]models.WebAuthnCredential, error) {
+	var credentials []models.WebAuthnCredential
+	if err := s.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&credentials).Error; err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// RenameCredential 重命名用户的通行密钥
+func (s *WebAuthnService) RenameCredential(userID, id uint, name string) (*models.WebAuthnCredential, error) {
+	var credential models.WebAuthnCredential
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&credential).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebAuthnCredentialNotFound
+		}
+		return nil, err
+	}
+
+	credential.Name = strings.TrimSpace(name)
+	if err := s.db.Save(&credential).Error; err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+// DeleteCredential 撤销用户的通行密钥
+func (s *WebAuthnService) DeleteCredential(userID, id uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.WebAuthnCredential{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebAuthnCredentialNotFound
+	}
+	return nil
+}
+
+// DeleteAllCredentials 撤销用户的全部通行密钥，返回撤销数量
+func (s *WebAuthnService) DeleteAllCredentials(userID uint) (int64, error) {
+	result := s.db.Where("user_id = ?", userID).Delete(&models.WebAuthnCredential{})
+	return result.RowsAffected, result.Error
+}
+
+// BeginRegistration 开始注册通行密钥，返回需传给浏览器的创建选项
+func (s *WebAuthnService) BeginRegistration(user *models.User) (*models.WebAuthnCeremonyResponse, error) {
+	if !s.cfg.Enabled {
+		return nil, ErrWebAuthnDisabled
+	}
+	if !s.IsAllowed(user) {
+		return nil, ErrWebAuthnNotAllowed
+	}
+
+	wa, err := s.relyingParty()
+	if err != nil {
+		return nil, err
+	}
+	waUser, err := s.loadUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	// 排除已注册的认证器，避免同一设备重复注册
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(waUser.credentials))
+	for _, credential := range waUser.credentials {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	creation, session, err := wa.BeginRegistration(waUser,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("生成注册选项失败: %w", err)
+	}
+
+	ceremonyID, err := s.saveCeremony(&webauthnCeremony{
+		Type:    webauthnCeremonyRegister,
+		UserID:  user.ID,
+		Session: *session,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.WebAuthnCeremonyResponse{CeremonyID: ceremonyID, Options: creation}, nil
+}
+
+// FinishRegistration 校验浏览器返回的注册结果并保存通行密钥
+func (s *WebAuthnService) FinishRegistration(user *models.User, ceremonyID, name string, body []byte) (*models.WebAuthnCredential, error) {
+	if !s.cfg.Enabled {
+		return nil, ErrWebAuthnDisabled
+	}
+	if !s.IsAllowed(user) {
+		return nil, ErrWebAuthnNotAllowed
+	}
+
+	ceremony, err := s.takeCeremony(ceremonyID)
+	if err != nil {
+		return nil, err
+	}
+	if ceremony.Type != webauthnCeremonyRegister || ceremony.UserID != user.ID {
+		return nil, ErrWebAuthnCeremonyExpired
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(body))
+	if err != nil {
+		return nil, ErrWebAuthnVerifyFailed
+	}
+
+	wa, err := s.relyingParty()
+	if err != nil {
+		return nil, err
+	}
+	waUser, err := s.loadUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := wa.CreateCredential(waUser, ceremony.Session, parsed)
+	if err != nil {
+		logger.Warnf("通行密钥注册校验失败: user_id=%d, %v", user.ID, err)
+		return nil, ErrWebAuthnVerifyFailed
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = fmt.Sprintf("通行密钥 %s", time.Now().Format("2006-01-02 15:04"))
+	}
+
+	transports := make(models.StringList, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	record := &models.WebAuthnCredential{
+		UserID:          user.ID,
+		Name:            name,
+		CredentialID:    base64.RawURLEncoding.EncodeToString(credential.ID),
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      transports,
+		AAGUID:          formatAAGUID(credential.Authenticator.AAGUID),
+		SignCount:       credential.Authenticator.SignCount,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+	if err := s.db.Create(record).Error; err != nil {
+		return nil, fmt.Errorf("保存通行密钥失败: %w", err)
+	}
+
+	logger.Infof("用户注册通行密钥: user_id=%d, credential_id=%d", user.ID, record.ID)
+	return record, nil
+}
+
+// BeginLogin 开始通行密钥登录
+// user 为空时发起无密码登录，由认证器选择账户；否则作为该用户密码登录后的第二步验证
+func (s *WebAuthnService) BeginLogin(user *models.User) (*models.WebAuthnCeremonyResponse, error) {
+	if !s.cfg.Enabled {
+		return nil, ErrWebAuthnDisabled
+	}
+
+	wa, err := s.relyingParty()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		assertion *protocol.CredentialAssertion
+		session   *webauthn.SessionData
+		ceremony  = &webauthnCeremony{Type: webauthnCeremonyLogin}
+	)
+	if user == nil {
+		// 无密码登录以通行密钥作为唯一凭据，必须校验用户身份（PIN或生物识别）
+		assertion, session, err = wa.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	} else {
+		if !s.IsAllowed(user) {
+			return nil, ErrWebAuthnNotAllowed
+		}
+		var waUser *webauthnUser
+		if waUser, err = s.loadUser(user); err != nil {
+			return nil, err
+		}
+		if len(waUser.credentials) == 0 {
+			return nil, ErrWebAuthnCredentialNotFound
+		}
+		ceremony.UserID = user.ID
+		assertion, session, err = wa.BeginLogin(waUser)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("生成登录选项失败: %w", err)
+	}
+
+	ceremony.Session = *session
+	ceremonyID, err := s.saveCeremony(ceremony)
+	if err != nil {
+		return nil, err
+	}
+	return &models.WebAuthnCeremonyResponse{CeremonyID: ceremonyID, Options: assertion}, nil
+}
+
+// FinishLogin 校验浏览器返回的登录断言，返回通过验证的用户ID
+// expectUserID 为第二步验证的用户ID，无密码登录时传0
+func (s *WebAuthnService) FinishLogin(ceremonyID string, expectUserID uint, body []byte) (uint, error) {
+	if !s.cfg.Enabled {
+		return 0, ErrWebAuthnDisabled
+	}
+
+	ceremony, err := s.takeCeremony(ceremonyID)
+	if err != nil {
+		return 0, err
+	}
+	if ceremony.Type != webauthnCeremonyLogin || ceremony.UserID != expectUserID {
+		return 0, ErrWebAuthnCeremonyExpired
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(body))
+	if err != nil {
+		return 0, ErrWebAuthnVerifyFailed
+	}
+
+	wa, err := s.relyingParty()
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		waUser     *webauthnUser
+		credential *webauthn.Credential
+	)
+	if expectUserID == 0 {
+		credential, err = wa.ValidateDiscoverableLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+			found, lookupErr := s.loadUserByHandle(userHandle)
+			if lookupErr != nil {
+				return nil, lookupErr
+			}
+			waUser = found
+			return found, nil
+		}, ceremony.Session, parsed)
+	} else {
+		var user models.User
+		if err := s.db.First(&user, expectUserID).Error; err != nil {
+			return 0, ErrWebAuthnVerifyFailed
+		}
+		if waUser, err = s.loadUser(&user); err != nil {
+			return 0, err
+		}
+		credential, err = wa.ValidateLogin(waUser, ceremony.Session, parsed)
+	}
+	if err != nil || waUser == nil {
+		logger.Warnf("通行密钥登录校验失败: %v", err)
+		return 0, ErrWebAuthnVerifyFailed
+	}
+	if !s.IsAllowed(waUser.user) {
+		return 0, ErrWebAuthnNotAllowed
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(credential.ID)
+	if credential.Authenticator.CloneWarning {
+		// 签名计数回退，认证器可能被复制，拒绝登录并标记
+		s.db.Model(&models.WebAuthnCredential{}).
+			Where("credential_id = ?", credentialID).
+			Update("clone_warning", true)
+		logger.Warnf("通行密钥签名计数异常，可能已被复制: user_id=%d", waUser.user.ID)
+		return 0, ErrWebAuthnVerifyFailed
+	}
+
+	now := time.Now()
+	if err := s.db.Model(&models.WebAuthnCredential{}).
+		Where("credential_id = ?", credentialID).
+		Updates(map[string]interface{}{
+			"sign_count":   credential.Authenticator.SignCount,
+			"backup_state": credential.Flags.BackupState,
+			"last_used_at": &now,
+		}).Error; err != nil {
+		logger.Errorf("更新通行密钥使用记录失败: %v", err)
+	}
+	return waUser.user.ID, nil
+}
+
+// relyingParty 根据配置创建依赖方实例
+func (s *WebAuthnService) relyingParty() (*webauthn.WebAuthn, error) {
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          s.cfg.RPID,
+		RPDisplayName: s.cfg.RPDisplayName,
+		RPOrigins:     s.cfg.RPOrigins,
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Enforce: true, Timeout: s.cfg.Timeout, TimeoutUVD: s.cfg.Timeout},
+			Registration: webauthn.TimeoutConfig{Enforce: true, Timeout: s.cfg.Timeout, TimeoutUVD: s.cfg.Timeout},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("通行密钥配置无效: %w", err)
+	}
+	return wa, nil
+}
+
+// loadUser 加载用户及其已注册的通行密钥
+func (s *WebAuthnService) loadUser(user *models.User) (*webauthnUser, error) {
+	records, err := s.ListCredentials(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]webauthn.Credential, 0, len(records))
+	for i := range records {
+		credential, err := toWebAuthnCredential(&records[i])
+		if err != nil {
+			logger.Warnf("跳过无法解析的通行密钥: id=%d, %v", records[i].ID, err)
+			continue
+		}
+		credentials = append(credentials, credential)
+	}
+	return &webauthnUser{user: user, credentials: credentials}, nil
+}
+
+// loadUserByHandle 根据认证器返回的用户句柄加载用户
+func (s *WebAuthnService) loadUserByHandle(handle []byte) (*webauthnUser, error) {
+	if len(handle) != 8 {
+		return nil, ErrWebAuthnVerifyFailed
+	}
+
+	var user models.User
+	if err := s.db.First(&user, binary.BigEndian.Uint64(handle)).Error; err != nil {
+		return nil, ErrWebAuthnVerifyFailed
+	}
+	return s.loadUser(&user)
+}
+
+// saveCeremony 保存流程状态，返回流程ID
+func (s *WebAuthnService) saveCeremony(ceremony *webauthnCeremony) (string, error) {
+	if s.redis == nil {
+		return "", ErrSessionStoreUnavailable
+	}
+
+	ceremonyID, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(ceremony)
+	if err != nil {
+		return "", err
+	}
+	if err := s.redis.Set(context.Background(), webauthnCeremonyPrefix+hashToken(ceremonyID), data, s.cfg.Timeout).Err(); err != nil {
+		return "", fmt.Errorf("保存通行密钥流程失败: %w", err)
+	}
+	return ceremonyID, nil
+}
+
+// takeCeremony 取出并删除流程状态，防止同一挑战被重复使用
+func (s *WebAuthnService) takeCeremony(ceremonyID string) (*webauthnCeremony, error) {
+	if s.redis == nil {
+		return nil, ErrSessionStoreUnavailable
+	}
+	if ceremonyID == "" {
+		return nil, ErrWebAuthnCeremonyExpired
+	}
+
+	data, err := s.redis.GetDel(context.Background(), webauthnCeremonyPrefix+hashToken(ceremonyID)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrWebAuthnCeremonyExpired
+		}
+		return nil, err
+	}
+
+	var ceremony webauthnCeremony
+	if err := json.Unmarshal(data, &ceremony); err != nil {
+		return nil, ErrWebAuthnCeremonyExpired
+	}
+	return &ceremony, nil
+}
+
+// toWebAuthnCredential 转换为 webauthn 库使用的凭据结构
+func toWebAuthnCredential(record *models.WebAuthnCredential) (webauthn.Credential, error) {
+	id, err := base64.RawURLEncoding.DecodeString(record.CredentialID)
+	if err != nil {
+		return webauthn.Credential{}, err
+	}
+
+	var aaguid []byte
+	if record.AAGUID != "" {
+		if aaguid, err = hex.DecodeString(strings.ReplaceAll(record.AAGUID, "-", "")); err != nil {
+			return webauthn.Credential{}, err
+		}
+	}
+
+	transports := make([]protocol.AuthenticatorTransport, 0, len(record.Transports))
+	for _, transport := range record.Transports {
+		transports = append(transports, protocol.AuthenticatorTransport(transport))
+	}
+
+	return webauthn.Credential{
+		ID:              id,
+		PublicKey:       record.PublicKey,
+		AttestationType: record.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: record.BackupEligible,
+			BackupState:    record.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    aaguid,
+			SignCount: record.SignCount,
+		},
+	}, nil
+}
+
+// webauthnUserHandle 由用户ID生成固定长度的用户句柄
+func webauthnUserHandle(userID uint) []byte {
+	handle := make([]byte, 8)
+	binary.BigEndian.PutUint64(handle, uint64(userID))
+	return handle
+}
+
+// formatAAGUID 将认证器型号标识格式化为UUID形式
+func formatAAGUID(aaguid []byte) string {
+	if len(aaguid) != 16 {
+		return ""
+	}
+	h := hex.EncodeToString(aaguid)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
@@ -0,0 +1,181 @@
+package ical
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 日历方法（RFC 5546）
+const (
+	MethodPublish = "PUBLISH"
+	MethodRequest = "REQUEST"
+	MethodCancel  = "CANCEL"
+)
+
+// prodID 产品标识
+const prodID = "-//Lab Recruitment Platform//Interview Calendar//ZH"
+
+// maxLineOctets 单行最大字节数（RFC 5545 3.1）
+const maxLineOctets = 75
+
+// utcFormat UTC时间格式
+const utcFormat = "20060102T150405Z"
+
+// Attendee 参与者
+type Attendee struct {
+	Name  string
+	Email string
+}
+
+// Event 日历事件
+type Event struct {
+	UID          string
+	Sequence     int
+	Start        time.Time
+	End          time.Time
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Status       string // CONFIRMED, TENTATIVE, CANCELLED
+	Organizer    *Attendee
+	Attendees    []Attendee
+	Created      time.Time
+	LastModified time.Time
+}
+
+// Calendar 日历
+type Calendar struct {
+	Name   string
+	Method string
+	Events []Event
+}
+
+// NewCalendar 创建日历
+func NewCalendar(name, method string) *Calendar {
+	return &Calendar{
+		Name:   name,
+		Method: method,
+	}
+}
+
+// AddEvent 添加事件
+func (c *Calendar) AddEvent(event Event) {
+	c.Events = append(c.Events, event)
+}
+
+// Bytes 序列化为 RFC 5545 格式
+func (c *Calendar) Bytes() []byte {
+	w := &writer{}
+	now := time.Now()
+
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + prodID)
+	w.line("CALSCALE:GREGORIAN")
+	if c.Method != "" {
+		w.line("METHOD:" + c.Method)
+	}
+	if c.Name != "" {
+		w.line("X-WR-CALNAME:" + escapeText(c.Name))
+	}
+
+	for _, e := range c.Events {
+		w.line("BEGIN:VEVENT")
+		w.line("UID:" + e.UID)
+		w.line("DTSTAMP:" + formatTime(now))
+		w.line("DTSTART:" + formatTime(e.Start))
+		w.line("DTEND:" + formatTime(e.End))
+		w.line("SEQUENCE:" + strconv.Itoa(e.Sequence))
+		if !e.Created.IsZero() {
+			w.line("CREATED:" + formatTime(e.Created))
+		}
+		if !e.LastModified.IsZero() {
+			w.line("LAST-MODIFIED:" + formatTime(e.LastModified))
+		}
+		w.line("SUMMARY:" + escapeText(e.Summary))
+		if e.Description != "" {
+			w.line("DESCRIPTION:" + escapeText(e.Description))
+		}
+		if e.Location != "" {
+			w.line("LOCATION:" + escapeText(e.Location))
+		}
+		if e.URL != "" {
+			w.line("URL:" + e.URL)
+		}
+		if e.Status != "" {
+			w.line("STATUS:" + e.Status)
+		}
+		if e.Organizer != nil {
+			w.line("ORGANIZER" + cnParam(e.Organizer.Name) + ":mailto:" + e.Organizer.Email)
+		}
+		for _, a := range e.Attendees {
+			w.line("ATTENDEE" + cnParam(a.Name) + ";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:" + a.Email)
+		}
+		w.line("BEGIN:VALARM")
+		w.line("ACTION:DISPLAY")
+		w.line("DESCRIPTION:" + escapeText(e.Summary))
+		w.line("TRIGGER:-PT30M")
+		w.line("END:VALARM")
+		w.line("END:VEVENT")
+	}
+
+	w.line("END:VCALENDAR")
+	return w.buf.Bytes()
+}
+
+// writer 负责折行与CRLF换行
+type writer struct {
+	buf bytes.Buffer
+}
+
+// line 写入一行内容，超过75字节时按 RFC 5545 折行（不拆分UTF-8字符）
+func (w *writer) line(s string) {
+	octets := 0
+	for _, r := range s {
+		size := len(string(r))
+		if octets+size > maxLineOctets {
+			w.buf.WriteString("\r\n ")
+			octets = 1
+		}
+		w.buf.WriteRune(r)
+		octets += size
+	}
+	w.buf.WriteString("\r\n")
+}
+
+// escapeText 转义 TEXT 类型的值
+func escapeText(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\r", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(s)
+}
+
+// cnParam 生成 CN 参数，名称中包含特殊字符时加引号；参数值不允许包含控制字符，直接去掉
+func cnParam(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" {
+		return ""
+	}
+	name = strings.ReplaceAll(name, `"`, "'")
+	return `;CN="` + name + `"`
+}
+
+// formatTime 格式化为UTC时间
+func formatTime(t time.Time) string {
+	return t.UTC().Format(utcFormat)
+}
@@ -0,0 +1,195 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"普通文本", "EPI实验室面试", "EPI实验室面试"},
+		{"反斜杠", `a\b`, `a\\b`},
+		{"分号与逗号", "a;b,c", `a\;b\,c`},
+		{"LF换行", "第一行\n第二行", `第一行\n第二行`},
+		{"CRLF换行", "第一行\r\n第二行", `第一行\n第二行`},
+		{"单独的CR换行", "第一行\r第二行", `第一行\n第二行`},
+		{"CR与CRLF混用", "a\r\rb\r\n", `a\n\nb\n`},
+		{"空字符串", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeText(tt.in); got != tt.want {
+				t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCNParam(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"空名称", "", ""},
+		{"普通名称", "张三", `;CN="张三"`},
+		{"双引号替换为单引号", `张"三"`, `;CN="张'三'"`},
+		{"去掉换行防止注入属性", "张三\r\nATTENDEE:mailto:x@example.com", `;CN="张三ATTENDEE:mailto:x@example.com"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cnParam(tt.in); got != tt.want {
+				t.Errorf("cnParam(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	cst := time.FixedZone("CST", 8*3600)
+	tests := []struct {
+		name string
+		in   time.Time
+		want string
+	}{
+		{"UTC", time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC), "20261018T093000Z"},
+		{"东八区转换为UTC", time.Date(2026, 10, 18, 9, 30, 0, 0, cst), "20261018T013000Z"},
+		{"跨日", time.Date(2026, 1, 1, 5, 0, 0, 0, cst), "20251231T210000Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatTime(tt.in); got != tt.want {
+				t.Errorf("formatTime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriterLineFolding(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		lines int
+	}{
+		{"短行不折", "SUMMARY:面试", 1},
+		{"恰好75字节不折", "X:" + strings.Repeat("a", 73), 1},
+		{"76字节折为两行", "X:" + strings.Repeat("a", 74), 2},
+		{"长中文按字符折行", "DESCRIPTION:" + strings.Repeat("实验室", 40), 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &writer{}
+			w.line(tt.in)
+			out := w.buf.String()
+
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("输出未以CRLF结尾: %q", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			if len(lines) != tt.lines {
+				t.Errorf("折行后 %d 行, want %d", len(lines), tt.lines)
+			}
+			for i, line := range lines {
+				if len(line) > maxLineOctets {
+					t.Errorf("第%d行 %d 字节，超过 %d", i+1, len(line), maxLineOctets)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("第%d行拆分了UTF-8字符: %q", i+1, line)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("续行未以空格开头: %q", line)
+				}
+			}
+
+			// 按 RFC 5545 展开后应与原内容一致
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); unfolded != tt.in {
+				t.Errorf("展开后 = %q, want %q", unfolded, tt.in)
+			}
+		})
+	}
+}
+
+func TestCalendarBytes(t *testing.T) {
+	start := time.Date(2026, 10, 20, 2, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		method   string
+		event    *Event
+		contains []string
+		absent   []string
+	}{
+		{
+			name:     "空日历",
+			method:   MethodPublish,
+			contains: []string{"BEGIN:VCALENDAR\r\n", "VERSION:2.0\r\n", "METHOD:PUBLISH\r\n", "END:VCALENDAR\r\n"},
+			absent:   []string{"BEGIN:VEVENT"},
+		},
+		{
+			name:   "面试邀请",
+			method: MethodRequest,
+			event: &Event{
+				UID:       "interview-1@lab",
+				Sequence:  2,
+				Start:     start,
+				End:       start.Add(30 * time.Minute),
+				Summary:   "EPI实验室面试",
+				Location:  "实验楼 301, 二层",
+				Status:    "CONFIRMED",
+				Organizer: &Attendee{Name: "EPI实验室", Email: "lab@example.com"},
+				Attendees: []Attendee{{Name: "张三", Email: "zhangsan@example.com"}},
+			},
+			contains: []string{
+				"METHOD:REQUEST\r\n",
+				"UID:interview-1@lab\r\n",
+				"DTSTART:20261020T020000Z\r\n",
+				"DTEND:20261020T023000Z\r\n",
+				"SEQUENCE:2\r\n",
+				`LOCATION:实验楼 301\, 二层` + "\r\n",
+				"STATUS:CONFIRMED\r\n",
+				`ORGANIZER;CN="EPI实验室":mailto:lab@example.com` + "\r\n",
+				"TRIGGER:-PT30M\r\n",
+				"END:VEVENT\r\n",
+			},
+			absent: []string{"URL:"},
+		},
+		{
+			name:     "取消",
+			method:   MethodCancel,
+			event:    &Event{UID: "interview-1@lab", Start: start, End: start, Summary: "面试取消", Status: "CANCELLED"},
+			contains: []string{"METHOD:CANCEL\r\n", "STATUS:CANCELLED\r\n"},
+			absent:   []string{"ORGANIZER", "ATTENDEE"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cal := NewCalendar("", tt.method)
+			if tt.event != nil {
+				cal.AddEvent(*tt.event)
+			}
+			out := string(cal.Bytes())
+
+			if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n") {
+				t.Errorf("日历未以 BEGIN:VCALENDAR 开头")
+			}
+			if strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+				t.Errorf("存在未使用CRLF的换行")
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(out, s) {
+					t.Errorf("输出缺少 %q", s)
+				}
+			}
+			for _, s := range tt.absent {
+				if strings.Contains(out, s) {
+					t.Errorf("输出不应包含 %q", s)
+				}
+			}
+		})
+	}
+}
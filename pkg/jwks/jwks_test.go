@@ -0,0 +1,223 @@
+package jwks
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+)
+
+func TestSetFind(t *testing.T) {
+	sig1 := JWK{Kid: "k1", Use: "sig"}
+	sig2 := JWK{Kid: "k2"}
+	enc := JWK{Kid: "e1", Use: "enc"}
+
+	tests := []struct {
+		name    string
+		set     Set
+		kid     string
+		wantKid string
+		wantOK  bool
+	}{
+		{"按kid命中", Set{Keys: []JWK{sig1, sig2}}, "k2", "k2", true},
+		{"kid不存在", Set{Keys: []JWK{sig1, sig2}}, "k3", "", false},
+		{"kid为空且只有一个签名公钥", Set{Keys: []JWK{sig1, enc}}, "", "k1", true},
+		{"kid为空且有多个签名公钥", Set{Keys: []JWK{sig1, sig2}}, "", "", false},
+		{"跳过加密公钥", Set{Keys: []JWK{enc}}, "e1", "", false},
+		{"空集合", Set{}, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.set.Find(tt.kid)
+			if ok != tt.wantOK || got.Kid != tt.wantKid {
+				t.Errorf("Find(%q) = (%q, %v), want (%q, %v)", tt.kid, got.Kid, ok, tt.wantKid, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateKeyAndPEMRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		alg     string
+		bits    int
+		wantErr bool
+	}{
+		{"Ed25519", AlgEdDSA, 0, false},
+		{"RSA 2048", AlgRS256, 2048, false},
+		{"RSA长度不足", AlgRS256, 1024, true},
+		{"不支持的算法", "HS256", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := GenerateKey(tt.alg, tt.bits)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("期望返回错误")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateKey() error = %v", err)
+			}
+
+			alg, err := Algorithm(key.Public())
+			if err != nil || alg != tt.alg {
+				t.Fatalf("Algorithm() = (%q, %v), want %q", alg, err, tt.alg)
+			}
+
+			privatePEM, err := EncodePrivateKeyPEM(key)
+			if err != nil {
+				t.Fatalf("EncodePrivateKeyPEM() error = %v", err)
+			}
+			parsed, err := ParsePrivateKeyPEM(privatePEM)
+			if err != nil {
+				t.Fatalf("ParsePrivateKeyPEM() error = %v", err)
+			}
+			if !publicKeysEqual(parsed.Public(), key.Public()) {
+				t.Error("私钥PEM往返后公钥不一致")
+			}
+
+			publicPEM, err := EncodePublicKeyPEM(key.Public())
+			if err != nil {
+				t.Fatalf("EncodePublicKeyPEM() error = %v", err)
+			}
+			pub, err := ParsePublicKeyPEM(publicPEM)
+			if err != nil {
+				t.Fatalf("ParsePublicKeyPEM() error = %v", err)
+			}
+			if !publicKeysEqual(pub, key.Public()) {
+				t.Error("公钥PEM往返后不一致")
+			}
+		})
+	}
+}
+
+func TestParsePrivateKeyPEM(t *testing.T) {
+	weakRSA, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"PKCS#1 RSA", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), false},
+		{"非PEM数据", []byte("not a pem"), true},
+		{"不支持的PEM类型", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1}}), true},
+		{"RSA长度不足", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(weakRSA)}), true},
+		{"不支持的ECDSA私钥", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ecDER}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePrivateKeyPEM(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePrivateKeyPEM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJWKRoundTrip(t *testing.T) {
+	rsaKey, err := GenerateKey(AlgRS256, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey, err := GenerateKey(AlgEdDSA, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		pub     crypto.PublicKey
+		wantKty string
+		wantAlg string
+	}{
+		{"RSA", rsaKey.Public(), "RSA", AlgRS256},
+		{"Ed25519", edKey.Public(), "OKP", AlgEdDSA},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwk, err := NewJWK("kid-1", tt.pub)
+			if err != nil {
+				t.Fatalf("NewJWK() error = %v", err)
+			}
+			if jwk.Kty != tt.wantKty || jwk.Alg != tt.wantAlg || jwk.Use != "sig" || jwk.Kid != "kid-1" {
+				t.Errorf("NewJWK() = %+v", jwk)
+			}
+
+			pub, err := jwk.PublicKey()
+			if err != nil {
+				t.Fatalf("PublicKey() error = %v", err)
+			}
+			if !publicKeysEqual(pub, tt.pub) {
+				t.Error("JWK往返后公钥不一致")
+			}
+		})
+	}
+}
+
+func TestJWKPublicKey(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	tests := []struct {
+		name    string
+		jwk     JWK
+		wantErr bool
+	}{
+		{"P-256", JWK{Kty: "EC", Crv: "P-256", X: b64(ecKey.X.Bytes()), Y: b64(ecKey.Y.Bytes())}, false},
+		{"椭圆曲线点不在曲线上", JWK{Kty: "EC", Crv: "P-256", X: b64([]byte{1}), Y: b64([]byte{2})}, true},
+		{"不支持的EC曲线", JWK{Kty: "EC", Crv: "secp256k1", X: b64([]byte{1}), Y: b64([]byte{2})}, true},
+		{"Ed25519长度错误", JWK{Kty: "OKP", Crv: "Ed25519", X: b64([]byte{1, 2, 3})}, true},
+		{"不支持的OKP曲线", JWK{Kty: "OKP", Crv: "X25519", X: b64(make([]byte, 32))}, true},
+		{"RSA指数为空", JWK{Kty: "RSA", N: b64([]byte{1}), E: ""}, true},
+		{"RSA模数编码错误", JWK{Kty: "RSA", N: "***", E: "AQAB"}, true},
+		{"不支持的密钥类型", JWK{Kty: "oct"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.jwk.PublicKey()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PublicKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// publicKeysEqual 比较两个公钥是否相同
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	switch key := a.(type) {
+	case *rsa.PublicKey:
+		return key.Equal(b)
+	case ed25519.PublicKey:
+		return key.Equal(b)
+	case *ecdsa.PublicKey:
+		return key.Equal(b)
+	}
+	return false
+}
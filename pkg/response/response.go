@@ -80,6 +80,20 @@ func Conflict(c *gin.Context, message string) {
 	Error(c, http.StatusConflict, message)
 }
 
+// ConflictWithData 409错误，附带当前数据供客户端合并
+func ConflictWithData(c *gin.Context, message string, data interface{}) {
+	c.JSON(http.StatusConflict, Response{
+		Code:    http.StatusConflict,
+		Message: message,
+		Data:    data,
+	})
+}
+
+// TooManyRequests 429错误
+func TooManyRequests(c *gin.Context, message string) {
+	Error(c, http.StatusTooManyRequests, message)
+}
+
 // InternalServerError 500错误
 func InternalServerError(c *gin.Context, message string) {
 	Error(c, http.StatusInternalServerError, message)
@@ -185,10 +185,10 @@ func ValidateEmail(email string) bool {
 	return validate.Var(email, "required,email") == nil
 }
 
-// ValidatePassword 验证密码强度
+// ValidatePassword 验证密码基本格式：6到72个字节，允许任意字符
+// 完整的密码策略（字符类别、常见密码、历史密码）由 services.PasswordPolicyService 校验
 func ValidatePassword(password string) bool {
-	// 密码至少6位，包含字母和数字
-	return validate.Var(password, "required,min=6,alphanum") == nil
+	return len(password) >= 6 && len(password) <= 72
 }
 
 // ValidatePhone 验证手机号格式
@@ -97,7 +97,7 @@ REDIS_HOST=redis
 REDIS_PORT=6379
 
 # JWT 配置
-JWT_SECRET=your_jwt_secret_key_change_in_production
+JWT_SECRET=$(openssl rand -hex 32)
 JWT_EXPIRE_HOURS=24
 
 # 服务器配置